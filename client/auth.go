@@ -0,0 +1,159 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+)
+
+// Authenticator decorates each outgoing request of the client
+// with credentials, such as an Authorization header.
+//
+// If an Authenticator also has a method Invalidate(),
+// the client calls it when the server responds with
+// status 401 (Unauthorized), so that fresh credentials are obtained
+// for subsequent requests. The failed request is not retried.
+//
+// Implementations must be safe for concurrent use.
+type Authenticator interface {
+	// Authenticate adds credentials to req before it is sent.
+	//
+	// If it returns a non-nil error, the request is not sent,
+	// and the error is returned to the caller of the client method.
+	Authenticate(req *http.Request) error
+}
+
+// BasicAuth returns an Authenticator that sets the HTTP basic
+// authentication with the specified username and password.
+func BasicAuth(username, password string) Authenticator {
+	return basicAuth{username: username, password: password}
+}
+
+// basicAuth is the Authenticator returned by BasicAuth.
+type basicAuth struct {
+	username, password string
+}
+
+func (a basicAuth) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(a.username, a.password)
+	return nil
+}
+
+// StaticHeader returns an Authenticator that sets the specified header
+// to the specified value, such as an API key header of a gateway.
+func StaticHeader(name, value string) Authenticator {
+	return staticHeader{name: name, value: value}
+}
+
+// BearerToken returns an Authenticator that sets the Authorization header
+// to "Bearer " followed by the specified token.
+func BearerToken(token string) Authenticator {
+	return staticHeader{name: "Authorization", value: "Bearer " + token}
+}
+
+// staticHeader is the Authenticator returned by StaticHeader
+// and BearerToken.
+type staticHeader struct {
+	name, value string
+}
+
+func (a staticHeader) Authenticate(req *http.Request) error {
+	req.Header.Set(a.name, a.value)
+	return nil
+}
+
+// TokenFunc is a function that fetches a new access token
+// and returns it with its expiry time.
+//
+// A zero expiry time means that the token never expires
+// (until invalidated).
+type TokenFunc func() (token string, expiry time.Time, err error)
+
+// RefreshingToken is an Authenticator that sets the Authorization header
+// to a bearer token obtained by a TokenFunc,
+// and fetches a new token when the current one is about to expire
+// or has been invalidated.
+//
+// It is safe for concurrent use.
+// Concurrent requests share one fetch.
+type RefreshingToken struct {
+	fetch  TokenFunc
+	leeway time.Duration
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// NewRefreshingToken creates a new RefreshingToken that obtains
+// tokens by fetch.
+//
+// leeway is the time before the expiry at which a token is refreshed,
+// to tolerate clock skew and network delay.
+// A non-positive leeway means no leeway.
+//
+// It panics if fetch is nil.
+func NewRefreshingToken(fetch TokenFunc, leeway time.Duration) *RefreshingToken {
+	if fetch == nil {
+		panic(gogoerrors.AutoMsg("fetch is nil"))
+	}
+	return &RefreshingToken{fetch: fetch, leeway: max(leeway, 0)}
+}
+
+// Authenticate sets the Authorization header of req
+// to the current bearer token, fetching a new token if necessary.
+func (rt *RefreshingToken) Authenticate(req *http.Request) error {
+	token, err := rt.Token()
+	if err != nil {
+		return gogoerrors.AutoWrap(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Token returns the current token, fetching a new token
+// if there is no token or the current one is about to expire.
+func (rt *RefreshingToken) Token() (string, error) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if rt.token != "" && (rt.expiry.IsZero() ||
+		time.Now().Add(rt.leeway).Before(rt.expiry)) {
+		return rt.token, nil
+	}
+	token, expiry, err := rt.fetch()
+	if err != nil {
+		return "", gogoerrors.AutoWrap(err)
+	} else if token == "" {
+		return "", gogoerrors.AutoNew("fetched token is empty")
+	}
+	rt.token, rt.expiry = token, expiry
+	return token, nil
+}
+
+// Invalidate discards the current token,
+// so that a new token is fetched for the next request.
+func (rt *RefreshingToken) Invalidate() {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.token, rt.expiry = "", time.Time{}
+}
@@ -0,0 +1,179 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/donyori/gocorenlp/client"
+	"github.com/donyori/gocorenlp/client/corenlptest"
+)
+
+func TestClient_Authenticator(t *testing.T) {
+	testCases := []struct {
+		name   string
+		auth   client.Authenticator
+		header string
+		want   string
+	}{
+		{
+			"BasicAuth",
+			client.BasicAuth(Username, Password),
+			"Authorization",
+			"Basic " + base64.StdEncoding.EncodeToString(
+				[]byte(Username+":"+Password)),
+		},
+		{
+			"StaticHeader",
+			client.StaticHeader("X-Api-Key", "secret-key"),
+			"X-Api-Key",
+			"secret-key",
+		},
+		{
+			"BearerToken",
+			client.BearerToken("secret-token"),
+			"Authorization",
+			"Bearer secret-token",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := NewFakeServerForTest(t, nil)
+			srv.SetHook(RequireHeaderHook(tc.header, tc.want))
+			opt := srv.ClientOptions()
+			opt.Authenticator = tc.auth
+			CheckFakeServerAnnotation(t, opt)
+		})
+	}
+}
+
+func TestClient_Authenticator_Precedence(t *testing.T) {
+	srv := NewFakeServerForTest(t, nil)
+	srv.SetHook(RequireHeaderHook("Authorization", "Bearer secret-token"))
+	opt := srv.ClientOptions()
+	opt.Username, opt.Password = Username, Password
+	opt.Authenticator = client.BearerToken("secret-token")
+	CheckFakeServerAnnotation(t, opt)
+}
+
+func TestClient_Authenticator_Error(t *testing.T) {
+	srv := NewFakeServerForTest(t, nil)
+	opt := srv.ClientOptions()
+	errAuth := errors.New("no credentials")
+	opt.Authenticator = client.NewRefreshingToken(
+		func() (string, time.Time, error) {
+			return "", time.Time{}, errAuth
+		},
+		0,
+	)
+	_, err := client.New(opt)
+	if !errors.Is(err, errAuth) {
+		t.Errorf("got error %v; want %v", err, errAuth)
+	}
+	if reqs := srv.Requests(); len(reqs) != 0 {
+		t.Errorf("got %d requests sent; want 0", len(reqs))
+	}
+}
+
+func TestRefreshingToken(t *testing.T) {
+	var numFetch atomic.Int32
+	rt := client.NewRefreshingToken(
+		func() (string, time.Time, error) {
+			n := numFetch.Add(1)
+			return fmt.Sprintf("token-%d", n), time.Now().Add(time.Hour), nil
+		},
+		time.Minute,
+	)
+	srv := NewFakeServerForTest(t, nil)
+	var valid atomic.Value
+	valid.Store("Bearer token-1")
+	srv.SetHook(func(req *corenlptest.Request) *corenlptest.Fault {
+		if req.Header.Get("Authorization") != valid.Load().(string) {
+			return &corenlptest.Fault{StatusCode: http.StatusUnauthorized}
+		}
+		return nil
+	})
+	opt := srv.ClientOptions()
+	opt.Authenticator = rt
+	c, err := client.New(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err = c.Ready(); err != nil {
+			t.Fatal("ready -", err)
+		}
+	}
+	if n := numFetch.Load(); n != 1 {
+		t.Errorf("got %d fetches before revoking; want 1", n)
+	}
+
+	// Revoke token-1. The next request fails with 401
+	// and invalidates the token, and the one after it uses token-2.
+	valid.Store("Bearer token-2")
+	if err = c.Ready(); err == nil {
+		t.Error("got nil error with a revoked token")
+	}
+	if err = c.Ready(); err != nil {
+		t.Error("ready after refreshing -", err)
+	}
+	if n := numFetch.Load(); n != 2 {
+		t.Errorf("got %d fetches after revoking; want 2", n)
+	}
+}
+
+func TestRefreshingToken_Expiry(t *testing.T) {
+	var numFetch int
+	rt := client.NewRefreshingToken(
+		func() (string, time.Time, error) {
+			numFetch++
+			// Expire within the leeway, so every call refreshes the token.
+			return fmt.Sprintf("token-%d", numFetch),
+				time.Now().Add(time.Second), nil
+		},
+		time.Minute,
+	)
+	for i := 1; i <= 3; i++ {
+		token, err := rt.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := fmt.Sprintf("token-%d", i); token != want {
+			t.Errorf("got token %q; want %q", token, want)
+		}
+	}
+}
+
+// RequireHeaderHook returns a corenlptest.Hook that replies 401
+// to requests whose header with the specified name is not the specified value.
+func RequireHeaderHook(name, value string) corenlptest.Hook {
+	return func(req *corenlptest.Request) *corenlptest.Fault {
+		if req.Header.Get(name) != value {
+			return &corenlptest.Fault{StatusCode: http.StatusUnauthorized}
+		}
+		return nil
+	}
+}
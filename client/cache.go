@@ -0,0 +1,258 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"sync"
+	"time"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+)
+
+// Cache stores the raw serialized responses of annotation requests,
+// keyed by the hash of the text, annotators, properties,
+// and server version of the request.
+//
+// See MemoryCache and DiskCache for the built-in implementations.
+//
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the response stored with the specified key.
+	//
+	// It returns false if no valid response is stored with the key.
+	// The caller must not modify the returned data.
+	Get(key string) (data []byte, ok bool, err error)
+
+	// Set stores the specified response with the specified key.
+	//
+	// The cache must not retain data after Set returns;
+	// it should store a copy of data instead.
+	Set(key string, data []byte) error
+}
+
+// CacheOptions are the limits of MemoryCache and DiskCache.
+type CacheOptions struct {
+	// MaxEntries is the maximum number of responses in the cache.
+	// When exceeded, the least recently used responses are evicted.
+	//
+	// A non-positive value means no limit.
+	//
+	// Default: 0
+	MaxEntries int `json:"maxEntries,omitempty"`
+
+	// MaxBytes is the maximum total size of responses in the cache.
+	// When exceeded, the least recently used responses are evicted.
+	// A response larger than MaxBytes is not stored.
+	//
+	// A non-positive value means no limit.
+	//
+	// Default: 0
+	MaxBytes int64 `json:"maxBytes,omitempty"`
+
+	// TTL is the time to live of responses in the cache,
+	// counting from the time they are stored.
+	//
+	// A non-positive value means that responses never expire.
+	//
+	// Default: 0
+	TTL time.Duration `json:"ttl,omitempty"`
+
+	// onlyKeyedLiterals forces others to construct CacheOptions
+	// only with the keyed literals, so future additions to it
+	// will not violate compatibility.
+	onlyKeyedLiterals struct{}
+}
+
+var _ = CacheOptions{}.onlyKeyedLiterals // to suppress "field `onlyKeyedLiterals` is unused (unused)"
+
+// MemoryCache is an in-memory Cache with the least recently used (LRU)
+// eviction policy.
+//
+// It is safe for concurrent use.
+type MemoryCache struct {
+	opt   CacheOptions
+	mu    sync.Mutex
+	ll    *list.List // ll holds *memoryCacheEntry, most recently used first.
+	items map[string]*list.Element
+	size  int64
+}
+
+// memoryCacheEntry is an entry of MemoryCache.
+type memoryCacheEntry struct {
+	key    string
+	data   []byte
+	expiry time.Time // expiry is zero if the entry never expires.
+}
+
+// NewMemoryCache creates a new MemoryCache with the specified limits.
+//
+// If opt is nil, the cache has no limits.
+func NewMemoryCache(opt *CacheOptions) *MemoryCache {
+	mc := &MemoryCache{
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+	if opt != nil {
+		mc.opt = *opt
+	}
+	return mc
+}
+
+// Get returns the response stored with the specified key.
+//
+// It returns false if the response does not exist or has expired.
+// Its error is always nil.
+func (mc *MemoryCache) Get(key string) (data []byte, ok bool, err error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	elem := mc.items[key]
+	if elem == nil {
+		return
+	}
+	entry := elem.Value.(*memoryCacheEntry)
+	if !entry.expiry.IsZero() && !time.Now().Before(entry.expiry) {
+		mc.remove(elem)
+		return
+	}
+	mc.ll.MoveToFront(elem)
+	return entry.data, true, nil
+}
+
+// Set stores a copy of the specified response with the specified key,
+// and evicts the least recently used responses if the limits are exceeded.
+//
+// Its error is always nil.
+func (mc *MemoryCache) Set(key string, data []byte) error {
+	size := int64(len(data))
+	if mc.opt.MaxBytes > 0 && size > mc.opt.MaxBytes {
+		return nil
+	}
+	entry := &memoryCacheEntry{
+		key:  key,
+		data: append([]byte(nil), data...),
+	}
+	if mc.opt.TTL > 0 {
+		entry.expiry = time.Now().Add(mc.opt.TTL)
+	}
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if elem := mc.items[key]; elem != nil {
+		mc.remove(elem)
+	}
+	mc.items[key] = mc.ll.PushFront(entry)
+	mc.size += size
+	for mc.ll.Len() > 1 &&
+		(mc.opt.MaxEntries > 0 && mc.ll.Len() > mc.opt.MaxEntries ||
+			mc.opt.MaxBytes > 0 && mc.size > mc.opt.MaxBytes) {
+		mc.remove(mc.ll.Back())
+	}
+	return nil
+}
+
+// Len returns the number of responses in the cache,
+// including the expired ones not yet evicted.
+func (mc *MemoryCache) Len() int {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	return mc.ll.Len()
+}
+
+// remove removes the specified element from the cache.
+//
+// The caller must hold mc.mu.
+func (mc *MemoryCache) remove(elem *list.Element) {
+	entry := mc.ll.Remove(elem).(*memoryCacheEntry)
+	delete(mc.items, entry.key)
+	mc.size -= int64(len(entry.data))
+}
+
+// cacheKey returns the cache key of an annotation request
+// with the specified text, properties, and server version.
+//
+// The properties are the JSON-encoded properties sent to the server,
+// including the annotators.
+func cacheKey(text []byte, properties []byte, serverVersion string) string {
+	h := sha256.New()
+	writeCacheKeyField(h, []byte(serverVersion))
+	writeCacheKeyField(h, properties)
+	writeCacheKeyField(h, text)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeCacheKeyField writes the length of the specified field
+// followed by the field itself to h,
+// so that different fields cannot be confused.
+func writeCacheKeyField(h hash.Hash, field []byte) {
+	var lenBuf [8]byte
+	binary.BigEndian.PutUint64(lenBuf[:], uint64(len(field)))
+	h.Write(lenBuf[:])
+	h.Write(field)
+}
+
+// flightGroup deduplicates concurrent calls with the same key,
+// so that only one of them does the work
+// and the others wait for and share its result.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// flightCall is an in-flight or completed call of flightGroup.
+type flightCall struct {
+	wg   sync.WaitGroup
+	data []byte
+	err  error
+}
+
+// do calls f and returns its results,
+// unless a call with the same key is in flight,
+// in which case it waits for that call and returns its results.
+//
+// shared reports whether the results are of another call.
+func (g *flightGroup) do(key string, f func() ([]byte, error)) (
+	data []byte, shared bool, err error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if call := g.calls[key]; call != nil {
+		g.mu.Unlock()
+		call.wg.Wait()
+		return call.data, true, call.err
+	}
+	// Report an error to the waiting callers if f panics.
+	call := &flightCall{err: gogoerrors.AutoNew("the shared call panicked")}
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		call.wg.Done()
+	}()
+	call.data, call.err = f()
+	return call.data, false, call.err
+}
@@ -0,0 +1,214 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/donyori/gocorenlp/client"
+	"github.com/donyori/gocorenlp/client/corenlptest"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+func TestMemoryCache(t *testing.T) {
+	mc := client.NewMemoryCache(&client.CacheOptions{
+		MaxEntries: 2,
+		MaxBytes:   8,
+	})
+	CacheSet(t, mc, "a", "aaa")
+	CacheSet(t, mc, "b", "bbb")
+	CacheGet(t, mc, "a", "aaa") // make "b" the least recently used
+	CacheSet(t, mc, "c", "ccc") // evict "b" for MaxEntries
+	CacheGet(t, mc, "b", "")
+	CacheGet(t, mc, "a", "aaa")
+	CacheGet(t, mc, "c", "ccc")
+	CacheSet(t, mc, "d", "ddddd") // evict "a" for MaxBytes
+	CacheGet(t, mc, "a", "")
+	CacheGet(t, mc, "d", "ddddd")
+	CacheSet(t, mc, "e", "eeeeeeeee") // larger than MaxBytes, not stored
+	CacheGet(t, mc, "e", "")
+	if n := mc.Len(); n != 2 {
+		t.Errorf("got length %d; want 2", n)
+	}
+}
+
+func TestMemoryCache_TTL(t *testing.T) {
+	mc := client.NewMemoryCache(&client.CacheOptions{TTL: time.Millisecond})
+	CacheSet(t, mc, "a", "aaa")
+	time.Sleep(time.Millisecond * 5)
+	CacheGet(t, mc, "a", "")
+	if n := mc.Len(); n != 0 {
+		t.Errorf("got length %d; want 0", n)
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	dc, err := client.NewDiskCache(dir, &client.CacheOptions{MaxEntries: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	CacheSet(t, dc, "a", "aaa")
+	CacheSet(t, dc, "b", "bbb")
+	CacheGet(t, dc, "a", "aaa")
+	CacheSet(t, dc, "c", "ccc") // evict "b"
+	CacheGet(t, dc, "b", "")
+	if _, err = os.Stat(filepath.Join(dir, "b.bin")); !os.IsNotExist(err) {
+		t.Error("the file of the evicted response still exists; error:", err)
+	}
+	if dc.Set("../x", []byte("x")) == nil {
+		t.Error("got nil error with an invalid key")
+	}
+
+	// Reopen the cache to check persistence.
+	dc, err = client.NewDiskCache(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := dc.Len(); n != 2 {
+		t.Errorf("got length %d after reopening; want 2", n)
+	}
+	CacheGet(t, dc, "a", "aaa")
+	CacheGet(t, dc, "c", "ccc")
+}
+
+func TestDiskCache_TTL(t *testing.T) {
+	dir := t.TempDir()
+	dc, err := client.NewDiskCache(dir, &client.CacheOptions{TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	CacheSet(t, dc, "a", "aaa")
+	CacheSet(t, dc, "b", "bbb")
+	old := time.Now().Add(-time.Hour * 2)
+	if err = os.Chtimes(filepath.Join(dir, "a.bin"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	dc, err = client.NewDiskCache(dir, &client.CacheOptions{TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	CacheGet(t, dc, "a", "")
+	CacheGet(t, dc, "b", "bbb")
+}
+
+func TestClient_Cache(t *testing.T) {
+	srv := NewFakeServerForTest(t, nil)
+	srv.SetHook(func(req *corenlptest.Request) *corenlptest.Fault {
+		if req.Endpoint == "" {
+			// Make concurrent requests overlap.
+			return &corenlptest.Fault{Delay: time.Millisecond * 50}
+		}
+		return nil
+	})
+	opt := srv.ClientOptions()
+	opt.Cache = client.NewMemoryCache(nil)
+	opt.ServerVersion = "4.5.6"
+	c, err := client.New(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const NumGoroutine = 4
+	var wg sync.WaitGroup
+	raws := make([][]byte, NumGoroutine)
+	for i := range raws {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var b bytes.Buffer
+			_, err := c.AnnotateStringRaw(Text, "tokenize,ssplit,pos", &b)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			raws[i] = b.Bytes()
+		}(i)
+	}
+	wg.Wait()
+	for i := 1; i < NumGoroutine; i++ {
+		if !bytes.Equal(raws[i], raws[0]) {
+			t.Errorf("response %d is different from response 0", i)
+		}
+	}
+	if n := NumAnnotationRequest(srv); n != 1 {
+		t.Errorf("got %d annotation requests after concurrent requests; want 1", n)
+	}
+
+	// A cache hit.
+	doc := new(pb.Document)
+	if err = c.AnnotateString(Text, "tokenize,ssplit,pos", doc); err != nil {
+		t.Fatal(err)
+	}
+	CheckAnnotation(t, doc)
+	if n := NumAnnotationRequest(srv); n != 1 {
+		t.Errorf("got %d annotation requests after a cache hit; want 1", n)
+	}
+
+	// Different annotators miss the cache.
+	if err = c.AnnotateString(Text, "tokenize,ssplit", doc); err == nil {
+		t.Error("got nil error for unregistered annotators")
+	}
+	if n := NumAnnotationRequest(srv); n != 2 {
+		t.Errorf("got %d annotation requests after a cache miss; want 2", n)
+	}
+}
+
+// CacheSet stores the specified data with the specified key in c.
+func CacheSet(t *testing.T, c client.Cache, key, data string) {
+	t.Helper()
+	if err := c.Set(key, []byte(data)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// CacheGet checks the data stored with the specified key in c.
+//
+// An empty want means that the data should not be found.
+func CacheGet(t *testing.T, c client.Cache, key, want string) {
+	t.Helper()
+	data, ok, err := c.Get(key)
+	switch {
+	case err != nil:
+		t.Errorf("get %q - %v", key, err)
+	case want == "" && ok:
+		t.Errorf("got %q for %q; want not found", data, key)
+	case want != "" && !ok:
+		t.Errorf("got not found for %q; want %q", key, want)
+	case string(data) != want:
+		t.Errorf("got %q for %q; want %q", data, key, want)
+	}
+}
+
+// NumAnnotationRequest returns the number of annotation requests
+// received by the specified fake server.
+func NumAnnotationRequest(srv *corenlptest.Server) int {
+	var n int
+	for _, req := range srv.Requests() {
+		if req.Endpoint == "" {
+			n++
+		}
+	}
+	return n
+}
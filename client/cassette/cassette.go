@@ -0,0 +1,270 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cassette
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+)
+
+// Mode is the mode of a Recorder.
+type Mode int8
+
+const (
+	// Replay serves the responses from the cassette only.
+	// A request not in the cassette results in an error.
+	Replay Mode = iota
+
+	// Record sends every request to the server
+	// and saves the response to the cassette,
+	// replacing any previous recording of the same request.
+	Record
+
+	// ReplayOrRecord serves the responses from the cassette if recorded,
+	// and otherwise sends the request to the server
+	// and saves the response to the cassette.
+	ReplayOrRecord
+)
+
+// String returns the name of the mode.
+func (m Mode) String() string {
+	switch m {
+	case Replay:
+		return "Replay"
+	case Record:
+		return "Record"
+	case ReplayOrRecord:
+		return "ReplayOrRecord"
+	default:
+		return "Mode(" + strconv.Itoa(int(m)) + ")"
+	}
+}
+
+// ErrNotRecorded is an error indicating that the request
+// is not recorded in the cassette in the mode Replay.
+//
+// The client should use errors.Is to test whether an error is ErrNotRecorded.
+var ErrNotRecorded = gogoerrors.New("request is not recorded in the cassette")
+
+// Interaction is the recorded information of a request and its response,
+// stored in the JSON file of the interaction.
+type Interaction struct {
+	Method     string            `json:"method"`               // Method is the HTTP method of the request.
+	Path       string            `json:"path"`                 // Path is the URL path of the request.
+	Properties map[string]string `json:"properties,omitempty"` // Properties are the properties of the annotation request.
+	Text       string            `json:"text,omitempty"`       // Text is the request body, that is, the text to be annotated.
+	StatusCode int               `json:"statusCode"`           // StatusCode is the status code of the response.
+	Header     http.Header       `json:"header,omitempty"`     // Header is the header of the response.
+}
+
+// Recorder is an http.RoundTripper that records responses
+// to a cassette directory and replays them.
+//
+// It is safe for concurrent use.
+type Recorder struct {
+	dir  string
+	mode Mode
+	next http.RoundTripper
+	mu   sync.Mutex // serializes writing the cassette
+}
+
+var _ http.RoundTripper = (*Recorder)(nil)
+
+// New creates a new Recorder working on the cassette directory dir
+// in the specified mode.
+//
+// next is the transport used to send requests to the server
+// in the modes Record and ReplayOrRecord.
+// If next is nil, http.DefaultTransport is used.
+//
+// In the modes Record and ReplayOrRecord,
+// New creates the directory if it does not exist.
+func New(dir string, mode Mode, next http.RoundTripper) (*Recorder, error) {
+	switch mode {
+	case Replay:
+	case Record, ReplayOrRecord:
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, gogoerrors.AutoWrap(err)
+		}
+	default:
+		return nil, gogoerrors.AutoNew("unknown mode " + mode.String())
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{dir: dir, mode: mode, next: next}, nil
+}
+
+// Dir returns the cassette directory.
+func (r *Recorder) Dir() string {
+	return r.dir
+}
+
+// Mode returns the mode of r.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// RoundTrip implements http.RoundTripper.
+//
+// It replays the recorded response of req,
+// or sends req by the next transport and records the response,
+// depending on the mode of r.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var text []byte
+	if req.Body != nil {
+		var err error
+		text, err = io.ReadAll(req.Body)
+		_ = req.Body.Close() // ignore error
+		if err != nil {
+			return nil, gogoerrors.AutoWrap(err)
+		}
+	}
+	it := &Interaction{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Text:   string(text),
+	}
+	if it.Path == "" {
+		it.Path = "/"
+	}
+	if p := req.URL.Query().Get("properties"); len(p) > 0 {
+		err := json.Unmarshal([]byte(p), &it.Properties)
+		if err != nil {
+			return nil, gogoerrors.AutoWrap(err)
+		}
+	}
+	name, err := it.name()
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	if r.mode != Record {
+		resp, err := r.replay(req, name)
+		if err == nil {
+			return resp, nil
+		} else if r.mode == Replay || !gogoerrors.Is(err, ErrNotRecorded) {
+			return nil, gogoerrors.AutoWrap(err)
+		}
+	}
+	return r.record(req, it, name)
+}
+
+// replay loads the recorded response of the interaction with
+// the specified name.
+//
+// It reports ErrNotRecorded if the interaction is not recorded.
+func (r *Recorder) replay(req *http.Request, name string) (
+	*http.Response, error) {
+	meta, err := os.ReadFile(filepath.Join(r.dir, name+".json"))
+	if gogoerrors.Is(err, fs.ErrNotExist) {
+		return nil, gogoerrors.AutoWrap(fmt.Errorf("%w: %s %s",
+			ErrNotRecorded, req.Method, req.URL.Path))
+	} else if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	it := new(Interaction)
+	if err = json.Unmarshal(meta, it); err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	body, err := os.ReadFile(filepath.Join(r.dir, name+".bin"))
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	header := it.Header
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(it.StatusCode) + " " + http.StatusText(it.StatusCode),
+		StatusCode:    it.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// record sends the request by the next transport and
+// saves the response as the interaction with the specified name.
+func (r *Recorder) record(req *http.Request, it *Interaction, name string) (
+	*http.Response, error) {
+	out := req.Clone(req.Context())
+	if req.Body != nil {
+		out.Body = io.NopCloser(strings.NewReader(it.Text))
+		out.ContentLength = int64(len(it.Text))
+	}
+	resp, err := r.next.RoundTrip(out)
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close() // ignore error
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	it.StatusCode, it.Header = resp.StatusCode, resp.Header.Clone()
+	meta, err := json.MarshalIndent(it, "", "  ")
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err = os.WriteFile(filepath.Join(r.dir, name+".bin"), body, 0o644)
+	if err == nil {
+		// Write the JSON file last, as its existence marks the recording.
+		err = os.WriteFile(filepath.Join(r.dir, name+".json"), meta, 0o644)
+	}
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	return resp, nil
+}
+
+// name returns the file name (without extension) of the interaction,
+// computed from its request method, path, properties, and text.
+func (it *Interaction) name() (string, error) {
+	// json.Marshal sorts the map keys, so the result is canonical.
+	props, err := json.Marshal(it.Properties)
+	if err != nil {
+		return "", gogoerrors.AutoWrap(err)
+	}
+	h := sha256.New()
+	for _, s := range []string{it.Method, it.Path, string(props), it.Text} {
+		_, _ = fmt.Fprintf(h, "%d:%s;", len(s), s) // never returns an error
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16]), nil
+}
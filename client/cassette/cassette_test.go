@@ -0,0 +1,162 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cassette_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/client"
+	"github.com/donyori/gocorenlp/client/cassette"
+	"github.com/donyori/gocorenlp/client/corenlptest"
+	"github.com/donyori/gocorenlp/internal/pbtest"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+func TestRecorder(t *testing.T) {
+	dir := t.TempDir()
+	srv, err := corenlptest.NewServer(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	err = srv.AddResponseBase64(
+		pbtest.RosesAreRed, "", pbtest.RosesAreRedRespV456)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opt := srv.ClientOptions()
+
+	// Record.
+	rec, err := cassette.New(dir, cassette.Record, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opt.Transport = rec
+	c, err := client.New(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded := new(pb.Document)
+	err = c.AnnotateString(pbtest.RosesAreRed, "tokenize,ssplit", recorded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 { // live and annotation
+		t.Errorf("got %d recorded interactions; want 2", len(files))
+	}
+
+	// Replay after the server is closed.
+	srv.Close()
+	rec, err = cassette.New(dir, cassette.Replay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opt.Transport = rec
+	c, err = client.New(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := new(pb.Document)
+	err = c.AnnotateString(pbtest.RosesAreRed, "tokenize,ssplit", replayed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(replayed, recorded) {
+		t.Error("replayed document is different from the recorded one")
+	}
+	if err = pbtest.CheckRosesAreRedDocument(replayed); err != nil {
+		t.Error(err)
+	}
+
+	err = c.AnnotateString(pbtest.RosesAreRed, "tokenize", new(pb.Document))
+	if !errors.Is(err, cassette.ErrNotRecorded) {
+		t.Errorf("got %v; want ErrNotRecorded", err)
+	}
+	if err = c.Ready(); !errors.Is(err, cassette.ErrNotRecorded) {
+		t.Errorf("got %v; want ErrNotRecorded", err)
+	}
+}
+
+func TestRecorder_ReplayOrRecord(t *testing.T) {
+	dir := t.TempDir()
+	srv, err := corenlptest.NewServer(&corenlptest.Options{
+		Username: "user1",
+		Password: "u1%passWORD",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	rec, err := cassette.New(dir, cassette.ReplayOrRecord, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opt := srv.ClientOptions()
+	opt.Transport = rec
+	c, err := client.New(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err = c.Ready(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var numReady int
+	for _, req := range srv.Requests() {
+		if req.Endpoint == "ready" {
+			numReady++
+		}
+	}
+	if numReady != 1 {
+		t.Errorf("server got %d ready requests; want 1", numReady)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		b, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, secret := range []string{opt.Password, srv.ShutdownKey()} {
+			if bytes.Contains(b, []byte(secret)) {
+				t.Errorf("file %s contains the secret %q", entry.Name(), secret)
+			}
+		}
+	}
+}
+
+func TestNew_UnknownMode(t *testing.T) {
+	_, err := cassette.New(t.TempDir(), cassette.Mode(-1), nil)
+	if err == nil {
+		t.Error("got nil error")
+	}
+}
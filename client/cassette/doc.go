@@ -0,0 +1,40 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package cassette provides an HTTP transport that records
+// the responses of a Stanford CoreNLP server to a directory (a cassette)
+// and replays them later,
+// so that tests written against the package client can run
+// deterministically without Java or a CoreNLP server.
+//
+// To use it, set a Recorder as the Transport of the client options:
+//
+//	rec, err := cassette.New("testdata/cassette", cassette.ReplayOrRecord, nil)
+//	...
+//	c, err := client.New(&client.Options{Transport: rec})
+//	...
+//
+// Each interaction is stored as two files named by a hash of
+// the request method, path, properties, and text:
+// a JSON file (.json) holding the request properties and text
+// and the response status and header,
+// and the raw response body (.bin),
+// which is the serialized annotation for annotation requests.
+// The credentials and the shutdown key are not part of the hash
+// and are never stored.
+package cassette
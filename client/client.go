@@ -23,10 +23,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
+	"maps"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	gogoerrors "github.com/donyori/gogo/errors"
@@ -173,7 +176,10 @@ type Client interface {
 // Thus, make sure the server is online and set the appropriate host address
 // in opt before calling this function.
 func New(opt *Options) (c Client, err error) {
-	t := newClientImpl(opt)
+	t, err := newClientImpl(opt)
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
 	if err := t.Live(); err != nil {
 		return nil, gogoerrors.AutoWrap(err)
 	}
@@ -183,37 +189,61 @@ func New(opt *Options) (c Client, err error) {
 // clientImpl is an implementation of the interface Client.
 type clientImpl struct {
 	c           http.Client
-	host        string
-	statusHost  string
-	userinfo    *url.Userinfo
+	baseURL     *url.URL
+	statusURL   *url.URL
+	auth        Authenticator
 	annotators  string
+	properties  map[string]string
 	contentType string
 	serverID    string
+
+	cache         Cache
+	serverVersion string
+	flight        flightGroup
+
+	hooks  []Hook
+	logger *slog.Logger
+
+	limiter  *tokenBucket
+	inFlight chan struct{}
+	breaker  *circuitBreaker
 }
 
 // newClientImpl creates a new clientImpl and
 // sets its fields according to the specified options opt.
-func newClientImpl(opt *Options) *clientImpl {
+//
+// It reports an error if the scheme is unsupported or
+// the TLS or proxy options are invalid.
+func newClientImpl(opt *Options) (*clientImpl, error) {
 	if opt == nil {
 		opt = new(Options)
 	}
 	c := &clientImpl{serverID: strings.TrimSpace(opt.ServerID)}
-	c.host, c.statusHost = opt.GetHosts()
-	username := strings.TrimSpace(opt.Username)
-	if len(username) > 0 {
-		password := strings.TrimSpace(opt.Password)
-		if len(password) > 0 {
-			c.userinfo = url.UserPassword(username, password)
-		} else {
-			c.userinfo = url.User(username)
+	var err error
+	c.baseURL, c.statusURL, err = opt.GetBaseURLs()
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	c.auth = opt.Authenticator
+	if c.auth == nil {
+		username := strings.TrimSpace(opt.Username)
+		if len(username) > 0 {
+			c.auth = BasicAuth(username, strings.TrimSpace(opt.Password))
 		}
 	}
 	if opt.ClientTimeout > 0 {
 		c.c.Timeout = opt.ClientTimeout
 	}
+	c.c.Transport, err = newTransport(opt)
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
 	if len(opt.Annotators) > 0 {
 		c.annotators = strings.Join(strings.Fields(opt.Annotators), "") // drop white space
 	}
+	if len(opt.Properties) > 0 {
+		c.properties = maps.Clone(opt.Properties)
+	}
 	charset := strings.TrimSpace(opt.Charset)
 	if len(charset) == 0 {
 		charset = "utf-8"
@@ -223,17 +253,34 @@ func newClientImpl(opt *Options) *clientImpl {
 	// the post data should be percent-encoded.
 	// Thus, set the Content-Type header to "application/x-www-form-urlencoded".
 	c.contentType = "application/x-www-form-urlencoded; charset=" + charset
-	return c
+	c.cache = opt.Cache
+	c.serverVersion = strings.TrimSpace(opt.ServerVersion)
+	c.hooks = slices.Clone(opt.Hooks)
+	c.logger = opt.Logger
+	if opt.RateLimit > 0 {
+		c.limiter = newTokenBucket(opt.RateLimit, opt.RateBurst)
+	}
+	if opt.MaxInFlight > 0 {
+		c.inFlight = make(chan struct{}, opt.MaxInFlight)
+	}
+	if opt.CircuitBreakerThreshold > 0 {
+		c.breaker = &circuitBreaker{
+			threshold: opt.CircuitBreakerThreshold,
+			cooldown:  opt.CircuitBreakerCooldown,
+		}
+		if c.breaker.cooldown <= 0 {
+			c.breaker.cooldown = DefaultCircuitBreakerCooldown
+		}
+	}
+	return c, nil
 }
 
-func (c *clientImpl) Live() error {
-	liveUrl := &url.URL{
-		Scheme: "http",
-		User:   c.userinfo,
-		Host:   c.statusHost,
-		Path:   "live",
-	}
-	resp, err := c.c.Get(liveUrl.String())
+func (c *clientImpl) Live() (err error) {
+	ev := c.startRequest(EndpointLive, "")
+	defer func() {
+		c.endRequest(ev, err)
+	}()
+	resp, err := c.do(ev, http.MethodGet, c.endpoint(true, "live", nil), nil)
 	if err != nil {
 		return gogoerrors.AutoWrap(err)
 	}
@@ -244,14 +291,12 @@ func (c *clientImpl) Live() error {
 	return gogoerrors.AutoWrap(err)
 }
 
-func (c *clientImpl) Ready() error {
-	readyUrl := &url.URL{
-		Scheme: "http",
-		User:   c.userinfo,
-		Host:   c.statusHost,
-		Path:   "ready",
-	}
-	resp, err := c.c.Get(readyUrl.String())
+func (c *clientImpl) Ready() (err error) {
+	ev := c.startRequest(EndpointReady, "")
+	defer func() {
+		c.endRequest(ev, err)
+	}()
+	resp, err := c.do(ev, http.MethodGet, c.endpoint(true, "ready", nil), nil)
 	if err != nil {
 		return gogoerrors.AutoWrap(err)
 	}
@@ -268,11 +313,11 @@ func (c *clientImpl) Annotate(
 	outDoc proto.Message,
 ) error {
 	var b bytes.Buffer
-	if _, err := c.AnnotateRaw(input, annotators, &b); err != nil {
-		return gogoerrors.AutoWrap(err)
-	}
-	// Parse ProtoBuf message.
-	return gogoerrors.AutoWrap(model.DecodeResponseBody(b.Bytes(), outDoc))
+	_, err := c.annotateRaw(input, annotators, &b, func() error {
+		// Parse ProtoBuf message.
+		return model.DecodeResponseBody(b.Bytes(), outDoc)
+	})
+	return gogoerrors.AutoWrap(err)
 }
 
 func (c *clientImpl) AnnotateString(
@@ -287,6 +332,22 @@ func (c *clientImpl) AnnotateRaw(
 	input io.Reader,
 	annotators string,
 	output io.Writer,
+) (written int64, err error) {
+	written, err = c.annotateRaw(input, annotators, output, nil)
+	return written, gogoerrors.AutoWrap(err)
+}
+
+// annotateRaw is the implementation of AnnotateRaw.
+//
+// decode is called after the response body is written to output
+// without error, so that its error is reported to the hooks
+// as the error of the request.
+// decode can be nil.
+func (c *clientImpl) annotateRaw(
+	input io.Reader,
+	annotators string,
+	output io.Writer,
+	decode func() error,
 ) (written int64, err error) {
 	// Check arguments first.
 	if input == nil {
@@ -296,12 +357,14 @@ func (c *clientImpl) AnnotateRaw(
 		panic(gogoerrors.AutoMsg("output writer is nil"))
 	}
 
-	// Make request URL.
+	// Make request properties.
 	ann := strings.Join(strings.Fields(annotators), "") // drop white space
 	if len(ann) == 0 {
 		ann = c.annotators
 	}
-	prop := make(map[string]string, 3)
+	prop := make(map[string]string, len(c.properties)+3)
+	maps.Copy(prop, c.properties)
+	delete(prop, "annotators")
 	prop["outputFormat"] = "serialized"
 	prop["serializer"] = "edu.stanford.nlp.pipeline.ProtobufAnnotationSerializer"
 	if len(ann) > 0 {
@@ -312,26 +375,40 @@ func (c *clientImpl) AnnotateRaw(
 		// This should never happen.
 		return 0, gogoerrors.AutoWrap(err)
 	}
-	qv := url.Values{"properties": []string{string(propBytes)}}
-	annUrl := &url.URL{
-		Scheme:   "http",
-		User:     c.userinfo,
-		Host:     c.host,
-		RawQuery: qv.Encode(),
+	ev := c.startRequest(EndpointAnnotate, ann)
+	if ev != nil {
+		defer func() {
+			ev.ResponseBytes = written
+			c.endRequest(ev, err)
+		}()
+	}
+	if decode != nil {
+		defer func() {
+			if err == nil {
+				err = gogoerrors.AutoWrap(decode())
+			}
+		}()
+	}
+	if c.cache != nil {
+		written, err = c.annotateCached(ev, input, propBytes, output)
+		return written, gogoerrors.AutoWrap(err)
+	}
+	if ev != nil {
+		cr := &countingReader{r: input}
+		defer func() {
+			ev.RequestBytes = cr.n
+		}()
+		input = cr
 	}
 
 	// Send request and forward response body to output.
-	resp, err := c.c.Post(annUrl.String(), c.contentType, input)
+	resp, err := c.postAnnotation(ev, input, propBytes)
 	if err != nil {
 		return 0, gogoerrors.AutoWrap(err)
 	}
 	defer func(c io.Closer) {
 		_ = c.Close() // ignore error
 	}(resp.Body)
-	err = checkResponse(resp, "")
-	if err != nil {
-		return 0, gogoerrors.AutoWrap(err)
-	}
 	written, err = io.Copy(output, resp.Body)
 	return written, gogoerrors.AutoWrap(err)
 }
@@ -344,16 +421,13 @@ func (c *clientImpl) AnnotateStringRaw(
 	return written, gogoerrors.AutoWrap(err)
 }
 
-func (c *clientImpl) Shutdown(key string) error {
+func (c *clientImpl) Shutdown(key string) (err error) {
+	ev := c.startRequest(EndpointShutdown, "")
+	defer func() {
+		c.endRequest(ev, err)
+	}()
 	qv := url.Values{"key": []string{key}}
-	shutdownUrl := &url.URL{
-		Scheme:   "http",
-		User:     c.userinfo,
-		Host:     c.host,
-		Path:     "shutdown",
-		RawQuery: qv.Encode(),
-	}
-	resp, err := c.c.Get(shutdownUrl.String())
+	resp, err := c.do(ev, http.MethodGet, c.endpoint(false, "shutdown", qv), nil)
 	if err != nil {
 		return gogoerrors.AutoWrap(err)
 	}
@@ -380,39 +454,293 @@ func (c *clientImpl) ShutdownLocal() error {
 
 func (c *clientImpl) private() {}
 
+// postAnnotation sends an annotation request with the specified input
+// and JSON-encoded properties, and checks the response.
+//
+// ev is the event of the request, which can be nil.
+//
+// If the returned error is nil,
+// the caller is responsible for closing the response body.
+func (c *clientImpl) postAnnotation(
+	ev *RequestEvent,
+	input io.Reader,
+	propBytes []byte,
+) (*http.Response, error) {
+	qv := url.Values{"properties": []string{string(propBytes)}}
+	resp, err := c.do(ev, http.MethodPost, c.endpoint(false, "", qv), input)
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	err = checkResponse(resp, "")
+	if err != nil {
+		_ = resp.Body.Close() // ignore error
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	return resp, nil
+}
+
+// annotateCached looks up the response to the annotation request
+// with the specified input and JSON-encoded properties in the cache,
+// sends the request if the cache misses,
+// and writes the response to output.
+//
+// Concurrent identical requests share one lookup and one request.
+//
+// ev is the event of the request, which can be nil.
+func (c *clientImpl) annotateCached(
+	ev *RequestEvent,
+	input io.Reader,
+	propBytes []byte,
+	output io.Writer,
+) (written int64, err error) {
+	text, err := io.ReadAll(input)
+	if err != nil {
+		return 0, gogoerrors.AutoWrap(err)
+	}
+	key := cacheKey(text, propBytes, c.serverVersion)
+	var hit bool
+	data, shared, err := c.flight.do(key, func() ([]byte, error) {
+		if data, ok, err := c.cache.Get(key); err == nil && ok {
+			hit = true
+			return data, nil
+		}
+		resp, err := c.postAnnotation(ev, bytes.NewReader(text), propBytes)
+		if err != nil {
+			return nil, gogoerrors.AutoWrap(err)
+		}
+		defer func(c io.Closer) {
+			_ = c.Close() // ignore error
+		}(resp.Body)
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, gogoerrors.AutoWrap(err)
+		}
+		_ = c.cache.Set(key, data) // ignore error
+		return data, nil
+	})
+	if ev != nil {
+		ev.RequestBytes, ev.CacheHit = int64(len(text)), hit || shared
+	}
+	if err != nil {
+		return 0, gogoerrors.AutoWrap(err)
+	}
+	n, err := output.Write(data)
+	return int64(n), gogoerrors.AutoWrap(err)
+}
+
+// do sends an HTTP request with the specified method, URL, and body,
+// subject to the rate limiter, the max-in-flight limit,
+// and the circuit breaker of the client,
+// and returns the response.
+//
+// ev is the event of the request, which can be nil.
+// If ev is not nil, do records the number of attempts
+// and the status code of the response in it.
+//
+// body can be nil.
+// If body is not nil, the Content-Type header is set to
+// the content type of the client.
+func (c *clientImpl) do(
+	ev *RequestEvent,
+	method, rawURL string,
+	body io.Reader,
+) (*http.Response, error) {
+	if c.breaker != nil {
+		probe := c.probeReady
+		if ev != nil {
+			probe = func() error {
+				err := c.probeReady()
+				if !isPrepareError(err) {
+					ev.Attempts++
+				}
+				return err
+			}
+		}
+		if err := c.breaker.allow(probe); err != nil {
+			return nil, gogoerrors.AutoWrap(err)
+		}
+	}
+	release := c.acquire()
+	resp, err := c.send(method, rawURL, body)
+	if ev != nil && !isPrepareError(err) {
+		ev.Attempts++
+	}
+	if c.breaker != nil {
+		switch {
+		case isPrepareError(err):
+			// The request is not sent, so it tells nothing about the server.
+		case err != nil:
+			c.breaker.record(err)
+		case isServerFailure(resp.StatusCode):
+			c.breaker.record(&errors.UnacceptableResponseError{
+				StatusCode: resp.StatusCode,
+				Status:     resp.Status,
+			})
+		default:
+			c.breaker.record(nil)
+		}
+	}
+	if err != nil {
+		if release != nil {
+			release()
+		}
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	if release != nil {
+		resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+	}
+	if ev != nil {
+		ev.StatusCode = resp.StatusCode
+	}
+	return resp, nil
+}
+
+// isServerFailure reports whether the response status code
+// indicates a failure of the server, counted by the circuit breaker:
+// 5XX (server errors) and 429 (Too Many Requests).
+//
+// Other status codes, including 4XX caused by the client
+// (such as a wrong credential or invalid properties),
+// show that the server is working.
+func isServerFailure(statusCode int) bool {
+	return statusCode >= 500 || statusCode == http.StatusTooManyRequests
+}
+
+// send sends an HTTP request with the specified method, URL, and body,
+// decorated by the authenticator of the client, and returns the response.
+//
+// The errors in building or authenticating the request,
+// with which the request is not sent, are reported as *prepareError.
+//
+// body can be nil.
+// If body is not nil, the Content-Type header is set to
+// the content type of the client.
+func (c *clientImpl) send(method, rawURL string, body io.Reader) (
+	*http.Response, error) {
+	req, err := http.NewRequest(method, rawURL, body)
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(&prepareError{err: err})
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", c.contentType)
+	}
+	c.logHTTPRequest(method, rawURL)
+	if c.auth != nil {
+		if err = c.auth.Authenticate(req); err != nil {
+			return nil, gogoerrors.AutoWrap(&prepareError{err: fmt.Errorf(
+				"failed to authenticate the request: %w", err)})
+		}
+	}
+	resp, err := c.c.Do(req)
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		if inv, ok := c.auth.(interface{ Invalidate() }); ok {
+			inv.Invalidate()
+		}
+	}
+	return resp, nil
+}
+
+// prepareError is an error in preparing a request on the client side,
+// such as building the request or authenticating it,
+// with which the request is not sent to the server.
+//
+// The circuit breaker does not count it as a failure of the server.
+type prepareError struct {
+	err error
+}
+
+func (e *prepareError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *prepareError) Unwrap() error {
+	return e.err
+}
+
+// isPrepareError reports whether err is caused by a *prepareError.
+func isPrepareError(err error) bool {
+	var e *prepareError
+	return errors.As(err, &e)
+}
+
+// endpoint returns the URL of the specified endpoint
+// with the specified query values.
+//
+// If status is true, the URL is on the status server;
+// otherwise, it is on the main server.
+//
+// name is the endpoint name without slashes,
+// such as "live" and "shutdown".
+// An empty name means the annotation endpoint.
+//
+// qv can be nil.
+func (c *clientImpl) endpoint(status bool, name string, qv url.Values) string {
+	u := *c.baseURL
+	if status {
+		u = *c.statusURL
+	}
+	u.Path += "/" + name
+	if qv != nil {
+		u.RawQuery = qv.Encode()
+	}
+	return u.String()
+}
+
+// maxErrorBodySize is the maximum number of bytes read
+// from the body of a response with an unexpected status
+// when the body is not expected.
+const maxErrorBodySize = 1 << 20
+
 // checkResponse checks the status and body of the specified HTTP response.
 //
 // wantBody is the expected body of the response.
 // If wantBody is not empty, checkResponse reads the response body
 // and compares it with wantBody, but does not close the response body reader.
-// Otherwise, checkResponse does nothing to the response body reader,
-// neither reading nor closing it.
+// Otherwise, checkResponse reads at most maxErrorBodySize bytes
+// of the response body only if the status code is not 2XX,
+// and never closes the response body reader.
 //
 // checkResponse reports an error if the status code is not 2XX,
 // or the expected body is not empty and the response body is different from
 // the expected (leading and trailing whitespace characters are ignored).
-// If the returned error is non-nil, it is of type
+// If the status code is not 2XX, the returned error is the result of
+// github.com/donyori/gocorenlp/errors.ParseServerError,
+// which identifies known failures of the server by the response body.
+// Otherwise, if the returned error is non-nil, it is of type
 // *github.com/donyori/gocorenlp/errors.UnacceptableResponseError.
 func checkResponse(resp *http.Response, wantBody string) error {
-	var err error
 	respErr := new(errors.UnacceptableResponseError)
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+	badStatus := resp.StatusCode < 200 || resp.StatusCode >= 300
+	if badStatus {
 		respErr.StatusCode, respErr.Status = resp.StatusCode, resp.Status
-		err = respErr
-	}
-	if wantBody == "" {
-		return gogoerrors.AutoWrap(err)
 	}
 	var body []byte
-	body, respErr.ReadError = io.ReadAll(resp.Body)
+	if wantBody != "" {
+		body, respErr.ReadError = io.ReadAll(resp.Body)
+	} else if badStatus {
+		body, respErr.ReadError = io.ReadAll(
+			io.LimitReader(resp.Body, maxErrorBodySize))
+	} else {
+		return nil
+	}
 	if len(body) > 0 {
 		respErr.Body = string(body)
 	}
-	if respErr.ReadError != nil {
-		err = respErr
-	} else if string(bytes.TrimSpace(body)) != strings.TrimSpace(wantBody) {
+	var mismatch bool
+	if wantBody != "" && respErr.ReadError == nil &&
+		string(bytes.TrimSpace(body)) != strings.TrimSpace(wantBody) {
 		respErr.WantBody = wantBody
-		err = respErr
+		mismatch = true
 	}
-	return gogoerrors.AutoWrap(err)
+	switch {
+	case badStatus:
+		return gogoerrors.AutoWrap(errors.ParseServerError(respErr))
+	case respErr.ReadError != nil, mismatch:
+		return gogoerrors.AutoWrap(respErr)
+	}
+	return nil
 }
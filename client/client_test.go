@@ -382,7 +382,11 @@ func NewClientForTest(tb testing.TB, index int) client.Client {
 	}
 	opts.Annotators = "tokenize,ssplit,pos"
 	opts.ClientTimeout = time.Millisecond * 600
-	return client.NewClientWithoutCheckingLive(opts)
+	c, err := client.NewClientWithoutCheckingLive(opts)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return c
 }
 
 // AnnotateFunc encapsulates common code for testing the methods Annotate,
@@ -0,0 +1,31 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package corenlptest provides a fake Stanford CoreNLP server
+// for testing code that uses the package client,
+// without running Java.
+//
+// The fake server is based on net/http/httptest.
+// It implements the liveness endpoint (/live), the readiness endpoint (/ready),
+// the shutdown endpoint (/shutdown) with key checking,
+// and the annotation endpoint (/), which replies with
+// canned serialized responses registered by text and annotators.
+// Latency, failures, and status codes can be injected with a hook.
+//
+// See the function NewServer for usage.
+package corenlptest
@@ -0,0 +1,60 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package corenlptest_test
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/client"
+	"github.com/donyori/gocorenlp/client/corenlptest"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+func ExampleNewServer() {
+	srv, err := corenlptest.NewServer(nil)
+	if err != nil {
+		panic(err) // handle error
+	}
+	defer srv.Close()
+
+	// Register the document to return for the text and annotators.
+	const text, annotators = "Hello world.", "tokenize,ssplit"
+	err = srv.AddDocument(text, annotators, &pb.Document{
+		Text: proto.String(text),
+	})
+	if err != nil {
+		panic(err) // handle error
+	}
+
+	// Connect to the fake server as if it were a CoreNLP server.
+	c, err := client.New(srv.ClientOptions())
+	if err != nil {
+		panic(err) // handle error
+	}
+	doc := new(pb.Document)
+	err = c.AnnotateString(text, annotators, doc)
+	if err != nil {
+		panic(err) // handle error
+	}
+	fmt.Println(doc.GetText())
+	// Output:
+	// Hello world.
+}
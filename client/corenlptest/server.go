@@ -0,0 +1,548 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package corenlptest
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/client"
+)
+
+// Options are the configuration for creating a new fake server.
+type Options struct {
+	// Username and Password are the credentials
+	// required by the server with basic auth.
+	//
+	// If Username is empty, the server does not require basic auth.
+	//
+	// Default: "" (empty)
+	Username, Password string
+
+	// ServerID is the server ID (the value of the option -server_id
+	// of the Stanford CoreNLP server).
+	//
+	// It determines the name of the shutdown key file.
+	//
+	// Default: "" (empty)
+	ServerID string
+
+	// ShutdownKey is the key required by the shutdown endpoint.
+	//
+	// If empty, a random key is generated.
+	//
+	// Default: "" (empty)
+	ShutdownKey string
+
+	// WriteKeyFile indicates whether to write the shutdown key to
+	// the file corenlp.shutdown (or corenlp.shutdown.<ServerID>
+	// if ServerID is not empty) in the temporary directory,
+	// as the Stanford CoreNLP server does,
+	// so that the method ShutdownLocal of the client works.
+	//
+	// The file is removed when the fake server is closed.
+	// Note that it overwrites the key file of any real server
+	// with the same server ID on the local machine.
+	//
+	// Default: false
+	WriteKeyFile bool
+
+	// SeparateStatusServer indicates whether to serve
+	// the liveness and readiness endpoints on a separate port,
+	// as the Stanford CoreNLP server started with the option -status_port.
+	//
+	// Default: false
+	SeparateStatusServer bool
+
+	// TLS indicates whether to serve HTTPS instead of HTTP,
+	// with a self-signed certificate (see Server.Certificate).
+	//
+	// Default: false
+	TLS bool
+
+	// DefaultAnnotators are the annotators assumed for
+	// annotation requests that specify no annotators,
+	// used to look up the canned responses.
+	//
+	// Default: "" (empty)
+	DefaultAnnotators string
+
+	// onlyKeyedLiterals forces others to construct Options
+	// only with the keyed literals, so future additions to it
+	// will not violate compatibility.
+	onlyKeyedLiterals struct{}
+}
+
+var _ = Options{}.onlyKeyedLiterals // to suppress "field `onlyKeyedLiterals` is unused (unused)"
+
+// Request is a request received by the fake server.
+type Request struct {
+	// Endpoint is the path of the request without the leading slash,
+	// such as "live", "ready", "shutdown", and "" (annotation).
+	Endpoint string
+
+	// Text is the text to be annotated.
+	// It is empty for the requests to other endpoints.
+	Text string
+
+	// Annotators are the annotators of the annotation request,
+	// with white space dropped.
+	// If the request specifies no annotators,
+	// it is Options.DefaultAnnotators.
+	Annotators string
+
+	// Properties are the properties of the annotation request.
+	Properties map[string]string
+
+	// Header is the header of the HTTP request.
+	Header http.Header
+}
+
+// Fault is a fault injected into the response to a request.
+//
+// The zero value injects nothing.
+type Fault struct {
+	// Delay is the time to wait before responding.
+	// The wait ends early if the client cancels the request.
+	Delay time.Duration
+
+	// StatusCode is the status code to reply with instead of
+	// the normal response. Zero means the normal response.
+	StatusCode int
+
+	// Body is the response body used along with StatusCode.
+	Body string
+
+	// CloseConnection indicates whether to close the connection
+	// without responding, after Delay.
+	// It takes precedence over StatusCode.
+	CloseConnection bool
+}
+
+// Hook is a function called on each request received by the fake server
+// before handling it.
+//
+// It returns the fault to inject into the response,
+// or nil for the normal response.
+//
+// It must be safe for concurrent use.
+type Hook func(req *Request) *Fault
+
+// responseKey is the key of the canned responses.
+type responseKey struct {
+	text, annotators string
+}
+
+// Server is a fake Stanford CoreNLP server.
+type Server struct {
+	main, status *httptest.Server
+	opt          Options
+	key          string
+	keyFile      string
+
+	mu        sync.Mutex
+	responses map[responseKey][]byte
+	requests  []Request
+	hook      Hook
+	shutdown  bool
+
+	closeOnce sync.Once
+}
+
+// NewServer starts and returns a new fake server
+// with the specified options.
+//
+// If opt is nil, it uses default options.
+//
+// The caller should call Close when finished, to shut it down.
+//
+// For example:
+//
+//	srv, err := corenlptest.NewServer(nil)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer srv.Close()
+//	srv.AddResponse(text, "tokenize,ssplit,pos", body)
+//	c, err := client.New(srv.ClientOptions())
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	...
+func NewServer(opt *Options) (*Server, error) {
+	s := &Server{responses: make(map[responseKey][]byte)}
+	if opt != nil {
+		s.opt = *opt
+	}
+	s.key = s.opt.ShutdownKey
+	if s.key == "" {
+		var b [16]byte
+		if _, err := rand.Read(b[:]); err != nil {
+			return nil, gogoerrors.AutoWrap(err)
+		}
+		s.key = hex.EncodeToString(b[:])
+	}
+	if s.opt.WriteKeyFile {
+		s.keyFile = filepath.Join(os.TempDir(), "corenlp.shutdown")
+		if id := strings.TrimSpace(s.opt.ServerID); len(id) > 0 {
+			s.keyFile += "." + id
+		}
+		err := os.WriteFile(s.keyFile, []byte(s.key), 0600)
+		if err != nil {
+			return nil, gogoerrors.AutoWrap(err)
+		}
+	}
+	newServer := httptest.NewServer
+	if s.opt.TLS {
+		newServer = httptest.NewTLSServer
+	}
+	s.main = newServer(http.HandlerFunc(s.serveMain))
+	if s.opt.SeparateStatusServer {
+		s.status = newServer(http.HandlerFunc(s.serveStatus))
+	}
+	return s, nil
+}
+
+// Close shuts down the fake server and removes the shutdown key file
+// if it was written.
+//
+// It blocks until all outstanding requests on the server have completed.
+func (s *Server) Close() {
+	s.closeOnce.Do(func() {
+		s.main.Close()
+		if s.status != nil {
+			s.status.Close()
+		}
+		if s.keyFile != "" {
+			_ = os.Remove(s.keyFile) // ignore error
+		}
+	})
+}
+
+// URL returns the base URL of the main server,
+// of the form http://ipaddr:port (or https://ipaddr:port if Options.TLS
+// is true) with no trailing slash.
+func (s *Server) URL() string {
+	return s.main.URL
+}
+
+// StatusURL returns the base URL of the status server.
+//
+// It is the same as URL if Options.SeparateStatusServer is false.
+func (s *Server) StatusURL() string {
+	if s.status != nil {
+		return s.status.URL
+	}
+	return s.main.URL
+}
+
+// ShutdownKey returns the key required by the shutdown endpoint.
+func (s *Server) ShutdownKey() string {
+	return s.key
+}
+
+// Certificate returns the certificate used by the fake server,
+// or nil if the fake server does not serve HTTPS.
+func (s *Server) Certificate() *x509.Certificate {
+	return s.main.Certificate()
+}
+
+// ClientOptions returns the client options for connecting to
+// the fake server, including the host, ports, credentials,
+// and server ID.
+//
+// If the fake server serves HTTPS, the options also include
+// the scheme "https" and a TLS configuration trusting
+// the certificate of the fake server.
+func (s *Server) ClientOptions() *client.Options {
+	hostname, port := splitHostPort(s.main.URL)
+	opt := &client.Options{
+		Hostname: hostname,
+		Port:     port,
+		Username: s.opt.Username,
+		Password: s.opt.Password,
+		ServerID: s.opt.ServerID,
+	}
+	if cert := s.Certificate(); cert != nil {
+		pool := x509.NewCertPool()
+		pool.AddCert(cert)
+		opt.Scheme = "https"
+		opt.TLSConfig = &tls.Config{RootCAs: pool}
+	}
+	if s.status != nil {
+		_, opt.StatusPort = splitHostPort(s.status.URL)
+	}
+	return opt
+}
+
+// AddResponse registers the response body replied to
+// the annotation requests with the specified text and annotators.
+//
+// body is the serialized response, as returned by the Stanford CoreNLP
+// server, that is, a length-delimited (varint length prefix)
+// ProtoBuf document.
+// To register a document, use the method AddDocument instead.
+//
+// White space in annotators is dropped.
+// If annotators are empty, the response is used for the requests with
+// the specified text and annotators that have no response registered.
+//
+// A later registration with the same text and annotators replaces
+// the earlier one.
+func (s *Server) AddResponse(text, annotators string, body []byte) {
+	k := responseKey{text: text, annotators: dropSpace(annotators)}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[k] = body
+}
+
+// AddResponseBase64 is like AddResponse,
+// but the response body is encoded in standard base64
+// (as defined in RFC 4648), as in the test data of this module.
+//
+// White space in base64Body is ignored.
+func (s *Server) AddResponseBase64(text, annotators, base64Body string) error {
+	b, err := base64.StdEncoding.DecodeString(
+		strings.Join(strings.Fields(base64Body), ""))
+	if err != nil {
+		return gogoerrors.AutoWrap(err)
+	}
+	s.AddResponse(text, annotators, b)
+	return nil
+}
+
+// AddDocument is like AddResponse,
+// but it serializes the specified document (usually a Stanford CoreNLP
+// document, such as
+// github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb.Document)
+// as the response body.
+func (s *Server) AddDocument(text, annotators string, doc proto.Message) error {
+	b, err := proto.Marshal(doc)
+	if err != nil {
+		return gogoerrors.AutoWrap(err)
+	}
+	s.AddResponse(text, annotators, protowire.AppendBytes(nil, b))
+	return nil
+}
+
+// SetHook sets the hook called on each subsequent request.
+//
+// A nil hook removes the current hook.
+func (s *Server) SetHook(hook Hook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hook = hook
+}
+
+// Requests returns the requests received by the fake server so far,
+// in the order of receipt.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// IsShutdown reports whether the fake server has received
+// a shutdown request with the correct key.
+//
+// After that, the fake server closes itself,
+// so subsequent requests fail to connect.
+func (s *Server) IsShutdown() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.shutdown
+}
+
+// serveMain handles the requests to the main server.
+func (s *Server) serveMain(w http.ResponseWriter, r *http.Request) {
+	switch strings.Trim(r.URL.Path, "/") {
+	case "":
+		s.handleAnnotate(w, r)
+	case "shutdown":
+		s.handleShutdown(w, r)
+	case "live", "ready":
+		if s.status == nil {
+			s.serveStatus(w, r)
+			return
+		}
+		fallthrough
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveStatus handles the requests to the status endpoints.
+func (s *Server) serveStatus(w http.ResponseWriter, r *http.Request) {
+	endpoint := strings.Trim(r.URL.Path, "/")
+	if endpoint != "live" && endpoint != "ready" {
+		http.NotFound(w, r)
+		return
+	}
+	if !s.begin(w, r, &Request{Endpoint: endpoint, Header: r.Header}) {
+		return
+	}
+	_, _ = io.WriteString(w, endpoint) // ignore error
+}
+
+// handleAnnotate handles the annotation requests.
+func (s *Server) handleAnnotate(w http.ResponseWriter, r *http.Request) {
+	req := &Request{Header: r.Header}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Text = string(body)
+	if p := r.URL.Query().Get("properties"); len(p) > 0 {
+		err = json.Unmarshal([]byte(p), &req.Properties)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	req.Annotators = dropSpace(req.Properties["annotators"])
+	if req.Annotators == "" {
+		req.Annotators = dropSpace(s.opt.DefaultAnnotators)
+	}
+	if !s.begin(w, r, req) {
+		return
+	}
+	s.mu.Lock()
+	resp, ok := s.responses[responseKey{req.Text, req.Annotators}]
+	if !ok {
+		resp, ok = s.responses[responseKey{text: req.Text}]
+	}
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, fmt.Sprintf(
+			"corenlptest: no response registered for text %q "+
+				"and annotators %q", req.Text, req.Annotators),
+			http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	_, _ = w.Write(resp) // ignore error
+}
+
+// handleShutdown handles the shutdown requests.
+func (s *Server) handleShutdown(w http.ResponseWriter, r *http.Request) {
+	if !s.begin(w, r, &Request{Endpoint: "shutdown", Header: r.Header}) {
+		return
+	}
+	if r.URL.Query().Get("key") != s.key {
+		http.Error(w, "Invalid shutdown key", http.StatusUnauthorized)
+		return
+	}
+	s.mu.Lock()
+	s.shutdown = true
+	s.mu.Unlock()
+	_, _ = io.WriteString(w, "Shutdown successful!") // ignore error
+	// Close asynchronously as Close waits for this request to complete.
+	go s.Close()
+}
+
+// begin records the request, checks basic auth, and injects the fault
+// returned by the hook.
+//
+// It reports whether the request should be handled normally.
+func (s *Server) begin(w http.ResponseWriter, r *http.Request, req *Request) bool {
+	s.mu.Lock()
+	s.requests = append(s.requests, *req)
+	hook := s.hook
+	s.mu.Unlock()
+	if s.opt.Username != "" {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != s.opt.Username || password != s.opt.Password {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return false
+		}
+	}
+	if hook == nil {
+		return true
+	}
+	fault := hook(req)
+	if fault == nil {
+		return true
+	}
+	if fault.Delay > 0 {
+		timer := time.NewTimer(fault.Delay)
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			timer.Stop()
+			return false
+		}
+	}
+	if fault.CloseConnection {
+		if hj, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hj.Hijack(); err == nil {
+				_ = conn.Close() // ignore error
+				return false
+			}
+		}
+		panic(http.ErrAbortHandler)
+	} else if fault.StatusCode != 0 {
+		w.WriteHeader(fault.StatusCode)
+		_, _ = io.WriteString(w, fault.Body) // ignore error
+		return false
+	}
+	return true
+}
+
+// splitHostPort splits the host and port from the specified base URL.
+func splitHostPort(rawURL string) (hostname string, port uint16) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		panic(gogoerrors.AutoWrap(err)) // should never happen
+	}
+	hostname, portStr, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		panic(gogoerrors.AutoWrap(err)) // should never happen
+	}
+	p, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		panic(gogoerrors.AutoWrap(err)) // should never happen
+	}
+	return hostname, uint16(p)
+}
+
+// dropSpace returns s with all white space removed.
+func dropSpace(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
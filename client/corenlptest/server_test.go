@@ -0,0 +1,246 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package corenlptest_test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/donyori/gocorenlp/client"
+	"github.com/donyori/gocorenlp/client/corenlptest"
+	"github.com/donyori/gocorenlp/errors"
+	"github.com/donyori/gocorenlp/internal/pbtest"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+func TestServer_Annotate(t *testing.T) {
+	srv := NewServerForTest(t, &corenlptest.Options{
+		DefaultAnnotators: "tokenize, ssplit, pos",
+	})
+	err := srv.AddResponseBase64(
+		pbtest.RosesAreRed, "", pbtest.RosesAreRedRespV456)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewClient(t, srv.ClientOptions())
+	for _, ann := range []string{"", "tokenize,ssplit"} {
+		doc := new(pb.Document)
+		err = c.AnnotateString(pbtest.RosesAreRed, ann, doc)
+		if err != nil {
+			t.Errorf("annotators %q - %v", ann, err)
+			continue
+		}
+		if err = pbtest.CheckRosesAreRedDocument(doc); err != nil {
+			t.Errorf("annotators %q - %v", ann, err)
+		}
+	}
+	var reqs []corenlptest.Request
+	for _, req := range srv.Requests() {
+		if req.Endpoint == "" {
+			reqs = append(reqs, req)
+		}
+	}
+	if len(reqs) != 2 {
+		t.Fatalf("got %d requests; want 2", len(reqs))
+	}
+	for i, want := range []string{"tokenize,ssplit,pos", "tokenize,ssplit"} {
+		if reqs[i].Annotators != want {
+			t.Errorf("request#%d: got annotators %q; want %q",
+				i, reqs[i].Annotators, want)
+		}
+		if reqs[i].Text != pbtest.RosesAreRed {
+			t.Errorf("request#%d: got text %q; want %q",
+				i, reqs[i].Text, pbtest.RosesAreRed)
+		}
+		if f := reqs[i].Properties["outputFormat"]; f != "serialized" {
+			t.Errorf("request#%d: got outputFormat %q; want serialized", i, f)
+		}
+	}
+
+	err = c.AnnotateString("unknown", "", new(pb.Document))
+	if !errors.IsUnacceptableResponseError(err) {
+		t.Errorf("got %v; want an UnacceptableResponseError", err)
+	}
+}
+
+func TestServer_AddDocument(t *testing.T) {
+	srv := NewServerForTest(t, nil)
+	const text = "Hello world!"
+	want := &pb.Document{Text: new(string)}
+	*want.Text = text
+	if err := srv.AddDocument(text, "tokenize", want); err != nil {
+		t.Fatal(err)
+	}
+	c := NewClient(t, srv.ClientOptions())
+	doc := new(pb.Document)
+	if err := c.AnnotateString(text, "tokenize", doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.GetText() != text {
+		t.Errorf("got text %q; want %q", doc.GetText(), text)
+	}
+}
+
+func TestServer_Hook(t *testing.T) {
+	srv := NewServerForTest(t, nil)
+	opt := srv.ClientOptions()
+	opt.ClientTimeout = time.Millisecond * 100
+	c := NewClient(t, opt)
+
+	srv.SetHook(func(req *corenlptest.Request) *corenlptest.Fault {
+		if req.Endpoint == "ready" {
+			return &corenlptest.Fault{
+				StatusCode: http.StatusServiceUnavailable,
+				Body:       "not ready",
+			}
+		}
+		return nil
+	})
+	if err := c.Live(); err != nil {
+		t.Error("live -", err)
+	}
+	if err := c.Ready(); !errors.IsUnacceptableResponseError(err) {
+		t.Errorf("ready - got %v; want an UnacceptableResponseError", err)
+	}
+
+	srv.SetHook(func(*corenlptest.Request) *corenlptest.Fault {
+		return &corenlptest.Fault{Delay: time.Second}
+	})
+	if err := c.Live(); !errors.IsTimeoutError(err) {
+		t.Errorf("delay - got %v; want a timeout error", err)
+	}
+
+	srv.SetHook(func(*corenlptest.Request) *corenlptest.Fault {
+		return &corenlptest.Fault{CloseConnection: true}
+	})
+	if err := c.Live(); !errors.IsConnectionError(err) {
+		t.Errorf("close connection - got %v; want a connection error", err)
+	}
+
+	srv.SetHook(nil)
+	if err := c.Live(); err != nil {
+		t.Error("no hook -", err)
+	}
+}
+
+func TestServer_BasicAuth(t *testing.T) {
+	srv := NewServerForTest(t, &corenlptest.Options{
+		Username: "user1",
+		Password: "u1%passWORD",
+	})
+	opt := srv.ClientOptions()
+	if err := NewClient(t, opt).Live(); err != nil {
+		t.Error("correct password -", err)
+	}
+	opt.Password = "wrong"
+	_, err := client.New(opt)
+	if !errors.IsUnacceptableResponseError(err) {
+		t.Errorf("wrong password - got %v; want an UnacceptableResponseError",
+			err)
+	}
+}
+
+func TestServer_SeparateStatusServer(t *testing.T) {
+	srv := NewServerForTest(t, &corenlptest.Options{
+		SeparateStatusServer: true,
+	})
+	if srv.StatusURL() == srv.URL() {
+		t.Error("the status server is not separate")
+	}
+	opt := srv.ClientOptions()
+	if opt.StatusPort == 0 || opt.StatusPort == opt.Port {
+		t.Errorf("got status port %d and main port %d",
+			opt.StatusPort, opt.Port)
+	}
+	c := NewClient(t, opt)
+	if err := c.Live(); err != nil {
+		t.Error("live -", err)
+	}
+	if err := c.Ready(); err != nil {
+		t.Error("ready -", err)
+	}
+}
+
+func TestServer_Shutdown(t *testing.T) {
+	srv := NewServerForTest(t, &corenlptest.Options{ShutdownKey: "secret"})
+	c := NewClient(t, srv.ClientOptions())
+	if err := c.Shutdown("wrong"); !errors.IsUnacceptableResponseError(err) {
+		t.Errorf("wrong key - got %v; want an UnacceptableResponseError", err)
+	}
+	if srv.IsShutdown() {
+		t.Fatal("server is shut down with a wrong key")
+	}
+	if err := c.Shutdown("secret"); err != nil {
+		t.Fatal(err)
+	}
+	if !srv.IsShutdown() {
+		t.Error("server is not shut down")
+	}
+	deadline := time.Now().Add(time.Second)
+	for c.Live() == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("server is still live after shutdown")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+func TestServer_ShutdownLocal(t *testing.T) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		t.Fatal(err)
+	}
+	srv := NewServerForTest(t, &corenlptest.Options{
+		ServerID:     "corenlptest" + hex.EncodeToString(b[:]),
+		WriteKeyFile: true,
+	})
+	c := NewClient(t, srv.ClientOptions())
+	if err := c.ShutdownLocal(); err != nil {
+		t.Fatal(err)
+	}
+	if !srv.IsShutdown() {
+		t.Error("server is not shut down")
+	}
+}
+
+// NewServerForTest creates a new fake server with the specified options
+// and registers its closing to tb.Cleanup.
+func NewServerForTest(tb testing.TB, opt *corenlptest.Options) *corenlptest.Server {
+	tb.Helper()
+	srv, err := corenlptest.NewServer(opt)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(srv.Close)
+	return srv
+}
+
+// NewClient creates a new client.Client with the specified options
+// by client.New and reports any error to tb.
+func NewClient(tb testing.TB, opt *client.Options) client.Client {
+	tb.Helper()
+	c, err := client.New(opt)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return c
+}
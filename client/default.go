@@ -26,7 +26,17 @@ import (
 )
 
 // defaultClient is a client with default settings.
-var defaultClient = newClientImpl(nil)
+var defaultClient = newDefaultClient()
+
+// newDefaultClient creates a client with default settings.
+func newDefaultClient() *clientImpl {
+	c, err := newClientImpl(nil)
+	if err != nil {
+		// This should never happen.
+		panic(gogoerrors.AutoWrap(err))
+	}
+	return c
+}
 
 // Live is a wrapper around Client.Live with a default client.
 // The default client connects to 127.0.0.1:9000,
@@ -0,0 +1,261 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+)
+
+// diskCacheExt is the filename extension of the responses
+// stored by DiskCache.
+const diskCacheExt = ".bin"
+
+// DiskCache is a Cache that stores each response in a file
+// in a directory, named by its key.
+//
+// It evicts the least recently used responses when exceeding the limits.
+// The usage is tracked in memory;
+// the responses found in the directory when the cache is created
+// are ordered by the modification time of their files.
+// The expiry of a response is counted from
+// the modification time of its file, so it persists across runs.
+//
+// It is safe for concurrent use within a process.
+// The directory should not be shared by
+// multiple DiskCache instances at the same time.
+type DiskCache struct {
+	dir   string
+	opt   CacheOptions
+	mu    sync.Mutex
+	items map[string]*diskCacheEntry
+	size  int64
+}
+
+// diskCacheEntry is the index entry of a response stored by DiskCache.
+type diskCacheEntry struct {
+	size     int64
+	stored   time.Time // stored is the time when the response was stored.
+	lastUsed time.Time // lastUsed is the time when the response was last used.
+}
+
+// NewDiskCache creates a new DiskCache that stores responses
+// in the specified directory with the specified limits.
+//
+// It creates the directory if it does not exist,
+// and indexes the responses already in it,
+// so responses persist across runs.
+//
+// If opt is nil, the cache has no limits.
+func NewDiskCache(dir string, opt *CacheOptions) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	dc := &DiskCache{dir: dir, items: make(map[string]*diskCacheEntry)}
+	if opt != nil {
+		dc.opt = *opt
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || !strings.HasSuffix(name, diskCacheExt) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			if gogoerrors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, gogoerrors.AutoWrap(err)
+		}
+		mt := info.ModTime()
+		dc.items[strings.TrimSuffix(name, diskCacheExt)] = &diskCacheEntry{
+			size:     info.Size(),
+			stored:   mt,
+			lastUsed: mt,
+		}
+		dc.size += info.Size()
+	}
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	return dc, gogoerrors.AutoWrap(dc.evict())
+}
+
+// Dir returns the directory of the cache.
+func (dc *DiskCache) Dir() string {
+	return dc.dir
+}
+
+// Get returns the response stored with the specified key.
+//
+// It returns false if the response does not exist or has expired.
+func (dc *DiskCache) Get(key string) (data []byte, ok bool, err error) {
+	if !isValidDiskCacheKey(key) {
+		return
+	}
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	entry := dc.items[key]
+	if entry == nil {
+		return
+	}
+	if dc.opt.TTL > 0 && !time.Now().Before(entry.stored.Add(dc.opt.TTL)) {
+		return nil, false, gogoerrors.AutoWrap(dc.remove(key))
+	}
+	data, err = os.ReadFile(dc.filename(key))
+	if err != nil {
+		if gogoerrors.Is(err, fs.ErrNotExist) {
+			// Removed by others.
+			dc.size -= entry.size
+			delete(dc.items, key)
+			err = nil
+		}
+		return nil, false, gogoerrors.AutoWrap(err)
+	}
+	entry.lastUsed = time.Now()
+	return data, true, nil
+}
+
+// Set stores the specified response with the specified key,
+// and evicts the least recently used responses if the limits are exceeded.
+//
+// It reports an error if key is not a valid filename component.
+func (dc *DiskCache) Set(key string, data []byte) error {
+	if !isValidDiskCacheKey(key) {
+		return gogoerrors.AutoNew(fmt.Sprintf("invalid cache key %q", key))
+	}
+	size := int64(len(data))
+	if dc.opt.MaxBytes > 0 && size > dc.opt.MaxBytes {
+		return nil
+	}
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	// Write to a temporary file and then rename it,
+	// so that readers never see a partial response.
+	f, err := os.CreateTemp(dc.dir, key+".*.tmp")
+	if err != nil {
+		return gogoerrors.AutoWrap(err)
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, dc.filename(key))
+	}
+	if err != nil {
+		_ = os.Remove(tmp) // ignore error
+		return gogoerrors.AutoWrap(err)
+	}
+	if old := dc.items[key]; old != nil {
+		dc.size -= old.size
+	}
+	now := time.Now()
+	dc.items[key] = &diskCacheEntry{size: size, stored: now, lastUsed: now}
+	dc.size += size
+	return gogoerrors.AutoWrap(dc.evict())
+}
+
+// Len returns the number of responses in the cache,
+// including the expired ones not yet evicted.
+func (dc *DiskCache) Len() int {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	return len(dc.items)
+}
+
+// evict removes the expired responses,
+// and then the least recently used responses until the limits are met.
+//
+// The caller must hold dc.mu.
+func (dc *DiskCache) evict() error {
+	var expiredBefore time.Time
+	if dc.opt.TTL > 0 {
+		expiredBefore = time.Now().Add(-dc.opt.TTL)
+	}
+	keys := make([]string, 0, len(dc.items))
+	for key, entry := range dc.items {
+		if !expiredBefore.IsZero() && !entry.stored.After(expiredBefore) {
+			if err := dc.remove(key); err != nil {
+				return gogoerrors.AutoWrap(err)
+			}
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if (dc.opt.MaxEntries <= 0 || len(keys) <= dc.opt.MaxEntries) &&
+		(dc.opt.MaxBytes <= 0 || dc.size <= dc.opt.MaxBytes) {
+		return nil
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		return dc.items[a].lastUsed.Compare(dc.items[b].lastUsed)
+	})
+	for _, key := range keys {
+		if (dc.opt.MaxEntries <= 0 || len(dc.items) <= dc.opt.MaxEntries) &&
+			(dc.opt.MaxBytes <= 0 || dc.size <= dc.opt.MaxBytes) {
+			break
+		}
+		if err := dc.remove(key); err != nil {
+			return gogoerrors.AutoWrap(err)
+		}
+	}
+	return nil
+}
+
+// remove deletes the response with the specified key.
+//
+// The caller must hold dc.mu.
+func (dc *DiskCache) remove(key string) error {
+	entry := dc.items[key]
+	if entry == nil {
+		return nil
+	}
+	dc.size -= entry.size
+	delete(dc.items, key)
+	err := os.Remove(dc.filename(key))
+	if err != nil && !gogoerrors.Is(err, fs.ErrNotExist) {
+		return gogoerrors.AutoWrap(err)
+	}
+	return nil
+}
+
+// filename returns the name of the file storing
+// the response with the specified key.
+func (dc *DiskCache) filename(key string) string {
+	return filepath.Join(dc.dir, key+diskCacheExt)
+}
+
+// isValidDiskCacheKey reports whether the specified key
+// can be used as a filename component.
+func isValidDiskCacheKey(key string) bool {
+	return len(key) > 0 && !strings.ContainsAny(key, `/\.:`) &&
+		!strings.ContainsFunc(key, func(r rune) bool { return r < ' ' })
+}
@@ -22,6 +22,10 @@ package client
 
 // NewClientWithoutCheckingLive directly calls
 // the unexported function newClientImpl.
-func NewClientWithoutCheckingLive(opts *Options) Client {
-	return newClientImpl(opts)
+func NewClientWithoutCheckingLive(opts *Options) (Client, error) {
+	c, err := newClientImpl(opts)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
 }
@@ -0,0 +1,210 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client_test
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/client"
+	"github.com/donyori/gocorenlp/client/corenlptest"
+	"github.com/donyori/gocorenlp/errors"
+	"github.com/donyori/gocorenlp/model"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+// The tests in this file run against a fake server
+// (package github.com/donyori/gocorenlp/client/corenlptest),
+// so they do not require a Stanford CoreNLP server.
+
+func TestClient_FakeServer(t *testing.T) {
+	srv := NewFakeServerForTest(t, &corenlptest.Options{
+		SeparateStatusServer: true,
+	})
+	opt := srv.ClientOptions()
+	opt.Annotators = "tokenize,ssplit,pos"
+	c, err := client.NewClientWithoutCheckingLive(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Live(); err != nil {
+		t.Error("live -", err)
+	}
+	if err := c.Ready(); err != nil {
+		t.Error("ready -", err)
+	}
+	t.Run("Annotate", func(t *testing.T) {
+		AnnotateFunc(t, func(annotators string) *pb.Document {
+			doc := new(pb.Document)
+			err := c.Annotate(strings.NewReader(Text), annotators, doc)
+			if err != nil {
+				t.Error(err)
+				return nil
+			}
+			return doc
+		})
+	})
+	t.Run("AnnotateStringRaw", func(t *testing.T) {
+		AnnotateFunc(t, func(annotators string) *pb.Document {
+			var b bytes.Buffer
+			written, err := c.AnnotateStringRaw(Text, annotators, &b)
+			if err != nil {
+				t.Error(err)
+				return nil
+			}
+			if n := int64(b.Len()); written != n {
+				t.Errorf("got written %d; want %d", written, n)
+				return nil
+			}
+			doc := new(pb.Document)
+			if err = model.DecodeResponseBody(b.Bytes(), doc); err != nil {
+				t.Error(err)
+				return nil
+			}
+			return doc
+		})
+	})
+	if err := c.Shutdown(srv.ShutdownKey()); err != nil {
+		t.Error("shutdown -", err)
+	} else if !srv.IsShutdown() {
+		t.Error("fake server is not shut down")
+	}
+}
+
+func TestClient_FakeServer_BasicAuth(t *testing.T) {
+	srv := NewFakeServerForTest(t, &corenlptest.Options{
+		Username: Username,
+		Password: Password,
+	})
+	opt := srv.ClientOptions()
+	opt.Annotators = "tokenize,ssplit,pos"
+	c, err := client.NewClientWithoutCheckingLive(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := new(pb.Document)
+	if err := c.AnnotateString(Text, "", doc); err != nil {
+		t.Fatal(err)
+	}
+	CheckAnnotation(t, doc)
+}
+
+// NewFakeServerForTest starts a fake server with the specified options,
+// registers the annotation of Text with annotators "tokenize,ssplit,pos",
+// and registers its closing to tb.Cleanup.
+func NewFakeServerForTest(
+	tb testing.TB,
+	opt *corenlptest.Options,
+) *corenlptest.Server {
+	tb.Helper()
+	srv, err := corenlptest.NewServer(opt)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(srv.Close)
+	words := strings.Fields(strings.TrimSuffix(Text, "."))
+	words = append(words, ".")
+	pos := []string{"DT", "JJ", "JJ", "NN", "VBD", "IN", "DT", "JJ", "NN", "."}
+	sentence := &pb.Sentence{
+		TokenOffsetBegin: proto.Uint32(0),
+		TokenOffsetEnd:   proto.Uint32(uint32(len(words))),
+	}
+	for i, w := range words {
+		before, after := " ", " "
+		if i == 0 {
+			before = ""
+		}
+		if i >= len(words)-2 {
+			after = ""
+		}
+		if i == len(words)-1 {
+			before = ""
+		}
+		sentence.Token = append(sentence.Token, &pb.Token{
+			Word:   proto.String(w),
+			Pos:    proto.String(pos[i]),
+			Before: proto.String(before),
+			After:  proto.String(after),
+		})
+	}
+	doc := &pb.Document{
+		Text:     proto.String(Text),
+		Sentence: []*pb.Sentence{sentence},
+	}
+	if err = srv.AddDocument(Text, "tokenize,ssplit,pos", doc); err != nil {
+		tb.Fatal(err)
+	}
+	return srv
+}
+
+func TestClient_FakeServer_ServerError(t *testing.T) {
+	srv := NewFakeServerForTest(t, nil)
+	srv.SetHook(func(req *corenlptest.Request) *corenlptest.Fault {
+		if req.Endpoint != "" {
+			return nil
+		}
+		return &corenlptest.Fault{
+			StatusCode: http.StatusInternalServerError,
+			Body: "java.lang.IllegalArgumentException: No annotator named foo\n" +
+				"\tat edu.stanford.nlp.pipeline.AnnotatorPool.get(AnnotatorPool.java:1)\n",
+		}
+	})
+	c, err := client.New(srv.ClientOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.AnnotateString(Text, "tokenize,foo", new(pb.Document))
+	if !errors.IsUnknownAnnotatorError(err) {
+		t.Errorf("got %v; want an unknown annotator error", err)
+	}
+	if !errors.IsUnacceptableResponseError(err) {
+		t.Error("not an unacceptable response error")
+	}
+}
+
+func TestClient_FakeServer_Properties(t *testing.T) {
+	srv := NewFakeServerForTest(t, nil)
+	opt := srv.ClientOptions()
+	opt.Properties = map[string]string{
+		"tokenize.language": "en",
+		"annotators":        "ignored",
+	}
+	c, err := client.New(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = c.AnnotateString(Text, "tokenize,ssplit,pos", new(pb.Document)); err != nil {
+		t.Fatal(err)
+	}
+	reqs := srv.Requests()
+	req := reqs[len(reqs)-1]
+	if v := req.Properties["tokenize.language"]; v != "en" {
+		t.Errorf("got tokenize.language %q; want en", v)
+	}
+	if req.Annotators != "tokenize,ssplit,pos" {
+		t.Errorf("got annotators %q; want tokenize,ssplit,pos", req.Annotators)
+	}
+	if v := req.Properties["outputFormat"]; v != "serialized" {
+		t.Errorf("got outputFormat %q; want serialized", v)
+	}
+}
@@ -0,0 +1,219 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"io"
+	"time"
+
+	"github.com/donyori/gocorenlp/errors"
+)
+
+// Endpoints reported in RequestEvent.
+const (
+	EndpointLive     = "live"
+	EndpointReady    = "ready"
+	EndpointAnnotate = "annotate"
+	EndpointShutdown = "shutdown"
+)
+
+// RequestEvent describes a request made by the client.
+//
+// The same *RequestEvent is passed to Hook.RequestStart and Hook.RequestEnd
+// of one request, so hooks can use it as the key to
+// associate their own data (such as a tracing span) with the request.
+type RequestEvent struct {
+	// Endpoint is the endpoint of the request,
+	// one of EndpointLive, EndpointReady, EndpointAnnotate,
+	// and EndpointShutdown.
+	Endpoint string
+
+	// Annotators are the annotators sent with the annotation request,
+	// after applying the client's default annotators.
+	// It is empty for other endpoints.
+	Annotators string
+
+	// Start is the time when the request started.
+	Start time.Time
+
+	// The following fields are set before calling Hook.RequestEnd.
+
+	// Duration is the time taken by the request,
+	// including reading the response body.
+	Duration time.Duration
+
+	// Attempts is the number of HTTP requests sent to the server
+	// for the request, including the readiness probe sent by
+	// the circuit breaker when it is half-open.
+	// The client does not resend a failed request,
+	// so Attempts is greater than 1 only if the circuit breaker
+	// probed the server before sending the request.
+	//
+	// It is zero if the request is served from the cache
+	// or rejected by the circuit breaker without a probe.
+	Attempts int
+
+	// StatusCode is the status code of the HTTP response.
+	// It is zero if no response is received,
+	// or the request is served from the cache.
+	StatusCode int
+
+	// RequestBytes is the number of bytes of the text sent
+	// with the annotation request.
+	// It is zero for other endpoints.
+	RequestBytes int64
+
+	// ResponseBytes is the number of bytes written to
+	// the output of the annotation request
+	// (the result written of AnnotateRaw).
+	// It is zero for other endpoints.
+	ResponseBytes int64
+
+	// CacheHit reports whether the response of the annotation request
+	// is served by Options.Cache (including sharing the response with
+	// a concurrent identical request) without sending a request of its own.
+	CacheHit bool
+
+	// Err is the error of the request, or nil if the request succeeded.
+	// For Annotate and AnnotateString, it includes the error
+	// in decoding the response body to the ProtoBuf message.
+	Err error
+
+	// ErrorClass is the class of Err obtained by ClassifyError.
+	ErrorClass string
+}
+
+// Hook is called by the client at the start and end of every request,
+// for collecting metrics and tracing.
+//
+// The hooks are called synchronously in the goroutine making the request,
+// so they should return quickly.
+// Implementations must be safe for concurrent use.
+type Hook interface {
+	// RequestStart is called when a request starts,
+	// with the fields Endpoint, Annotators, and Start of ev set.
+	RequestStart(ev *RequestEvent)
+
+	// RequestEnd is called when a request ends,
+	// with all the fields of ev set.
+	RequestEnd(ev *RequestEvent)
+}
+
+// Error classes returned by ClassifyError.
+const (
+	ErrorClassNone                 = ""
+	ErrorClassCircuitOpen          = "circuit_open"
+	ErrorClassServerTimeout        = "server_timeout"
+	ErrorClassTimeout              = "timeout"
+	ErrorClassOutOfMemory          = "out_of_memory"
+	ErrorClassUnknownAnnotator     = "unknown_annotator"
+	ErrorClassMissingDependency    = "missing_dependency"
+	ErrorClassBadProperties        = "bad_properties"
+	ErrorClassConnection           = "connection"
+	ErrorClassUnacceptableResponse = "unacceptable_response"
+	ErrorClassProtoBuf             = "protobuf"
+	ErrorClassOther                = "other"
+)
+
+// ClassifyError classifies the specified error
+// by the predicates of package github.com/donyori/gocorenlp/errors.
+//
+// It returns ErrorClassNone if err is nil.
+// It returns the first matched class in the order of
+// ErrorClassCircuitOpen, ErrorClassServerTimeout, ErrorClassTimeout, ErrorClassOutOfMemory,
+// ErrorClassUnknownAnnotator, ErrorClassMissingDependency,
+// ErrorClassBadProperties, ErrorClassConnection,
+// ErrorClassUnacceptableResponse, and ErrorClassProtoBuf.
+// If none of them matches, it returns ErrorClassOther.
+func ClassifyError(err error) string {
+	switch {
+	case err == nil:
+		return ErrorClassNone
+	case errors.IsCircuitOpenError(err):
+		return ErrorClassCircuitOpen
+	case errors.IsServerTimeoutError(err):
+		return ErrorClassServerTimeout
+	case errors.IsTimeoutError(err):
+		return ErrorClassTimeout
+	case errors.IsOutOfMemoryError(err):
+		return ErrorClassOutOfMemory
+	case errors.IsUnknownAnnotatorError(err):
+		return ErrorClassUnknownAnnotator
+	case errors.IsMissingAnnotatorDependencyError(err):
+		return ErrorClassMissingDependency
+	case errors.IsBadPropertiesError(err):
+		return ErrorClassBadProperties
+	case errors.IsConnectionError(err):
+		return ErrorClassConnection
+	case errors.IsUnacceptableResponseError(err):
+		return ErrorClassUnacceptableResponse
+	case errors.IsProtoBufError(err):
+		return ErrorClassProtoBuf
+	}
+	return ErrorClassOther
+}
+
+// startRequest creates a RequestEvent for a request to
+// the specified endpoint with the specified annotators,
+// logs the start of the request, and calls the hooks of the client.
+//
+// It returns nil if the client has neither hooks nor a logger.
+func (c *clientImpl) startRequest(endpoint, annotators string) *RequestEvent {
+	if len(c.hooks) == 0 && c.logger == nil {
+		return nil
+	}
+	ev := &RequestEvent{
+		Endpoint:   endpoint,
+		Annotators: annotators,
+		Start:      time.Now(),
+	}
+	c.logStart(ev)
+	for _, h := range c.hooks {
+		h.RequestStart(ev)
+	}
+	return ev
+}
+
+// endRequest completes the specified RequestEvent with the specified error,
+// logs the end of the request, and calls the hooks of the client.
+//
+// It does nothing if ev is nil.
+func (c *clientImpl) endRequest(ev *RequestEvent, err error) {
+	if ev == nil {
+		return
+	}
+	ev.Duration = time.Since(ev.Start)
+	ev.Err, ev.ErrorClass = err, ClassifyError(err)
+	c.logEnd(ev)
+	for _, h := range c.hooks {
+		h.RequestEnd(ev)
+	}
+}
+
+// countingReader is an io.Reader that counts the bytes read.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (n int, err error) {
+	n, err = cr.r.Read(p)
+	cr.n += int64(n)
+	return
+}
@@ -0,0 +1,218 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client_test
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/donyori/gocorenlp/client"
+	"github.com/donyori/gocorenlp/client/corenlptest"
+	"github.com/donyori/gocorenlp/errors"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+func TestClient_Hooks(t *testing.T) {
+	srv := NewFakeServerForTest(t, nil)
+	hook := new(RecordingHook)
+	opt := srv.ClientOptions()
+	opt.Annotators = "tokenize,ssplit,pos"
+	opt.Hooks = []client.Hook{hook}
+	c, err := client.New(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	written, err := c.AnnotateStringRaw(Text, "", &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.SetHook(func(req *corenlptest.Request) *corenlptest.Fault {
+		return &corenlptest.Fault{StatusCode: http.StatusServiceUnavailable}
+	})
+	if c.Ready() == nil {
+		t.Error("got nil error from an unavailable server")
+	}
+
+	evs := hook.Events()
+	if len(evs) != 3 {
+		t.Fatalf("got %d events; want 3", len(evs))
+	}
+	if ev := evs[0]; ev.Endpoint != client.EndpointLive ||
+		ev.StatusCode != http.StatusOK || ev.Err != nil {
+		t.Errorf("live - got %+v", *ev)
+	}
+	ev := evs[1]
+	switch {
+	case ev.Endpoint != client.EndpointAnnotate:
+		t.Errorf("annotate - got endpoint %q", ev.Endpoint)
+	case ev.Annotators != "tokenize,ssplit,pos":
+		t.Errorf("annotate - got annotators %q", ev.Annotators)
+	case ev.StatusCode != http.StatusOK:
+		t.Errorf("annotate - got status code %d", ev.StatusCode)
+	case ev.Attempts != 1:
+		t.Errorf("annotate - got attempts %d; want 1", ev.Attempts)
+	case ev.RequestBytes != int64(len(Text)):
+		t.Errorf("annotate - got request bytes %d; want %d",
+			ev.RequestBytes, len(Text))
+	case ev.ResponseBytes != written:
+		t.Errorf("annotate - got response bytes %d; want %d",
+			ev.ResponseBytes, written)
+	case ev.Duration <= 0:
+		t.Errorf("annotate - got duration %v", ev.Duration)
+	}
+	ev = evs[2]
+	if ev.Endpoint != client.EndpointReady ||
+		ev.StatusCode != http.StatusServiceUnavailable ||
+		ev.ErrorClass != client.ErrorClassUnacceptableResponse {
+		t.Errorf("ready - got %+v", *ev)
+	}
+	if n := hook.NumStart(); n != 3 {
+		t.Errorf("got %d starts; want 3", n)
+	}
+}
+
+func TestClient_Hooks_ProtoBufError(t *testing.T) {
+	srv := NewFakeServerForTest(t, nil)
+	hook := new(RecordingHook)
+	opt := srv.ClientOptions()
+	opt.Hooks = []client.Hook{hook}
+	c, err := client.New(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.SetHook(func(req *corenlptest.Request) *corenlptest.Fault {
+		// A truncated length-delimited message.
+		return &corenlptest.Fault{StatusCode: http.StatusOK, Body: "\x05ab"}
+	})
+	err = c.AnnotateString(Text, "", new(pb.Document))
+	if !errors.IsProtoBufError(err) {
+		t.Fatalf("got %v; want a ProtoBuf error", err)
+	}
+
+	evs := hook.Events()
+	if len(evs) != 2 {
+		t.Fatalf("got %d events; want 2", len(evs))
+	}
+	if ev := evs[1]; ev.Endpoint != client.EndpointAnnotate ||
+		ev.ErrorClass != client.ErrorClassProtoBuf ||
+		!errors.IsProtoBufError(ev.Err) {
+		t.Errorf("got %+v", *ev)
+	}
+}
+
+func TestClient_Hooks_Attempts(t *testing.T) {
+	const Cooldown = time.Millisecond * 50
+	srv := NewFakeServerForTest(t, nil)
+	hook := new(RecordingHook)
+	opt := srv.ClientOptions()
+	opt.CircuitBreakerThreshold = 1
+	opt.CircuitBreakerCooldown = Cooldown
+	opt.Hooks = []client.Hook{hook}
+	c, err := client.New(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.SetHook(func(req *corenlptest.Request) *corenlptest.Fault {
+		return &corenlptest.Fault{StatusCode: http.StatusServiceUnavailable}
+	})
+	if c.Live() == nil {
+		t.Fatal("got nil error from an unavailable server")
+	}
+	if !errors.IsCircuitOpenError(c.Live()) {
+		t.Fatal("the circuit breaker is not open")
+	}
+	srv.SetHook(nil)
+	time.Sleep(Cooldown + time.Millisecond*10)
+	if err = c.Live(); err != nil {
+		t.Fatalf("got %v after a successful probe", err)
+	}
+
+	evs := hook.Events()
+	if len(evs) != 4 {
+		t.Fatalf("got %d events; want 4", len(evs))
+	}
+	// The first event is of the liveness check by client.New.
+	for i, want := range []int{1, 1, 0, 2} {
+		if evs[i].Attempts != want {
+			t.Errorf("event %d - got attempts %d; want %d",
+				i, evs[i].Attempts, want)
+		}
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	respErr := &errors.UnacceptableResponseError{StatusCode: 500}
+	testCases := []struct {
+		err  error
+		want string
+	}{
+		{nil, client.ErrorClassNone},
+		{&errors.ServerTimeoutError{Response: respErr}, client.ErrorClassServerTimeout},
+		{&errors.OutOfMemoryError{Response: respErr}, client.ErrorClassOutOfMemory},
+		{&errors.UnknownAnnotatorError{Annotator: "foo"}, client.ErrorClassUnknownAnnotator},
+		{&errors.MissingAnnotatorDependencyError{}, client.ErrorClassMissingDependency},
+		{&errors.BadPropertiesError{Response: respErr}, client.ErrorClassBadProperties},
+		{respErr, client.ErrorClassUnacceptableResponse},
+		{errors.NewProtoBufError("op", nil, errors.New("x")), client.ErrorClassProtoBuf},
+		{errors.New("x"), client.ErrorClassOther},
+	}
+
+	for _, tc := range testCases {
+		if got := client.ClassifyError(tc.err); got != tc.want {
+			t.Errorf("error %v - got %q; want %q", tc.err, got, tc.want)
+		}
+	}
+}
+
+// RecordingHook is a client.Hook that records the finished requests.
+type RecordingHook struct {
+	mu       sync.Mutex
+	numStart int
+	events   []*client.RequestEvent
+}
+
+func (h *RecordingHook) RequestStart(*client.RequestEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.numStart++
+}
+
+func (h *RecordingHook) RequestEnd(ev *client.RequestEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, ev)
+}
+
+// NumStart returns the number of started requests.
+func (h *RecordingHook) NumStart() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.numStart
+}
+
+// Events returns the events of the finished requests.
+func (h *RecordingHook) Events() []*client.RequestEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]*client.RequestEvent(nil), h.events...)
+}
@@ -0,0 +1,226 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+
+	"github.com/donyori/gocorenlp/errors"
+)
+
+// DefaultCircuitBreakerCooldown is the default time for which
+// the circuit breaker stays open before probing the server.
+const DefaultCircuitBreakerCooldown = 30 * time.Second
+
+// tokenBucket is a token-bucket rate limiter.
+//
+// It is safe for concurrent use.
+type tokenBucket struct {
+	rate   float64 // rate is the number of tokens added per second.
+	burst  float64 // burst is the capacity of the bucket.
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket creates a new full tokenBucket with the specified
+// rate (per second) and burst.
+//
+// A burst less than 1 is treated as 1.
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	b := float64(max(burst, 1))
+	return &tokenBucket{rate: rate, burst: b, tokens: b, last: time.Now()}
+}
+
+// wait takes a token from the bucket,
+// blocking until the token is available.
+//
+// The callers are served in the order of calling.
+func (tb *tokenBucket) wait() {
+	tb.mu.Lock()
+	now := time.Now()
+	tb.tokens = min(tb.tokens+now.Sub(tb.last).Seconds()*tb.rate, tb.burst)
+	tb.last = now
+	// Reserve a token, possibly in advance.
+	tb.tokens--
+	var d time.Duration
+	if tb.tokens < 0 {
+		d = time.Duration(-tb.tokens / tb.rate * float64(time.Second))
+	}
+	tb.mu.Unlock()
+	if d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// Circuit breaker states.
+const (
+	circuitClosed int8 = iota
+	circuitOpen
+	circuitProbing
+)
+
+// circuitBreaker fails requests fast after consecutive failures,
+// and probes the server for readiness after a cooldown
+// to decide whether to resume the requests.
+//
+// It is safe for concurrent use.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	mu        sync.Mutex
+	state     int8
+	failures  int
+	lastErr   error
+	openUntil time.Time
+}
+
+// allow reports an *errors.CircuitOpenError if the request
+// should be rejected.
+//
+// If the circuit breaker is open and the cooldown has passed,
+// the caller probes the server by probe,
+// while requests of other callers are rejected.
+// If the probe succeeds, the circuit breaker is closed
+// and the request is allowed.
+// Otherwise, the circuit breaker is open again for another cooldown,
+// unless the probe is not sent due to an error on the client side
+// (see prepareError), which is returned as is.
+func (cb *circuitBreaker) allow(probe func() error) error {
+	cb.mu.Lock()
+	switch cb.state {
+	case circuitClosed:
+		cb.mu.Unlock()
+		return nil
+	case circuitOpen:
+		if !time.Now().Before(cb.openUntil) {
+			break
+		}
+		fallthrough
+	default:
+		err := cb.openError()
+		cb.mu.Unlock()
+		return gogoerrors.AutoWrap(err)
+	}
+	cb.state, cb.openUntil = circuitProbing, time.Time{}
+	cb.mu.Unlock()
+
+	probeErr := probe()
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if isPrepareError(probeErr) {
+		// The probe is not sent. Stay open without a cooldown
+		// so that the next request probes the server again.
+		cb.state = circuitOpen
+		return gogoerrors.AutoWrap(probeErr)
+	}
+	if probeErr == nil {
+		cb.state, cb.failures, cb.lastErr = circuitClosed, 0, nil
+		return nil
+	}
+	cb.state, cb.lastErr = circuitOpen, probeErr
+	cb.openUntil = time.Now().Add(cb.cooldown)
+	return gogoerrors.AutoWrap(cb.openError())
+}
+
+// record records the result of a request.
+//
+// A nil err resets the consecutive failures.
+// A non-nil err is counted as a failure,
+// and the circuit breaker opens when the failures reach the threshold.
+func (cb *circuitBreaker) record(err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.state != circuitClosed {
+		// A request sent before the circuit breaker opened.
+		return
+	}
+	if err == nil {
+		cb.failures, cb.lastErr = 0, nil
+		return
+	}
+	cb.failures++
+	cb.lastErr = err
+	if cb.failures >= cb.threshold {
+		cb.state = circuitOpen
+		cb.openUntil = time.Now().Add(cb.cooldown)
+	}
+}
+
+// openError returns the error reporting that the circuit breaker is open.
+//
+// The caller must hold cb.mu.
+func (cb *circuitBreaker) openError() *errors.CircuitOpenError {
+	return &errors.CircuitOpenError{
+		Failures:  cb.failures,
+		LastError: cb.lastErr,
+		Until:     cb.openUntil,
+	}
+}
+
+// releaseOnClose is an io.ReadCloser that calls release
+// when it is closed for the first time.
+type releaseOnClose struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (rc *releaseOnClose) Close() error {
+	rc.once.Do(rc.release)
+	return rc.ReadCloser.Close()
+}
+
+// acquire waits for the rate limiter and the max-in-flight limit
+// of the client before sending a request.
+//
+// It returns the function to release the in-flight slot,
+// which is nil if the client has no max-in-flight limit.
+func (c *clientImpl) acquire() (release func()) {
+	if c.limiter != nil {
+		c.limiter.wait()
+	}
+	if c.inFlight != nil {
+		c.inFlight <- struct{}{}
+		return func() {
+			<-c.inFlight
+		}
+	}
+	return nil
+}
+
+// probeReady sends a readiness request to the server,
+// bypassing the rate limiter, the max-in-flight limit,
+// and the circuit breaker.
+func (c *clientImpl) probeReady() error {
+	resp, err := c.send(http.MethodGet, c.endpoint(true, "ready", nil), nil)
+	if err != nil {
+		return gogoerrors.AutoWrap(err)
+	}
+	defer func(c io.Closer) {
+		_ = c.Close() // ignore error
+	}(resp.Body)
+	return gogoerrors.AutoWrap(checkResponse(resp, "ready"))
+}
@@ -0,0 +1,253 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client_test
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/donyori/gocorenlp/client"
+	"github.com/donyori/gocorenlp/client/corenlptest"
+	"github.com/donyori/gocorenlp/errors"
+)
+
+func TestClient_RateLimit(t *testing.T) {
+	srv := NewFakeServerForTest(t, nil)
+	opt := srv.ClientOptions()
+	opt.RateLimit = 20
+	opt.RateBurst = 2
+	c, err := client.New(opt) // take the first token
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err = c.Ready(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The second token is in the bucket, and the other 4 requests
+	// wait for 50ms each.
+	if d := time.Since(start); d < time.Millisecond*180 {
+		t.Errorf("got %v for 5 requests; want at least 200ms", d)
+	}
+}
+
+func TestClient_MaxInFlight(t *testing.T) {
+	srv := NewFakeServerForTest(t, nil)
+	srv.SetHook(func(req *corenlptest.Request) *corenlptest.Fault {
+		if req.Endpoint == "ready" {
+			return &corenlptest.Fault{Delay: time.Millisecond * 50}
+		}
+		return nil
+	})
+	opt := srv.ClientOptions()
+	opt.MaxInFlight = 1
+	c, err := client.New(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.Ready(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if d := time.Since(start); d < time.Millisecond*150 {
+		t.Errorf("got %v for 3 requests; want at least 150ms", d)
+	}
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	const Cooldown = time.Millisecond * 50
+	srv := NewFakeServerForTest(t, nil)
+	opt := srv.ClientOptions()
+	opt.CircuitBreakerThreshold = 2
+	opt.CircuitBreakerCooldown = Cooldown
+	c, err := client.New(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.SetHook(func(req *corenlptest.Request) *corenlptest.Fault {
+		return &corenlptest.Fault{StatusCode: http.StatusServiceUnavailable}
+	})
+	for i := 0; i < 2; i++ {
+		if err = c.Live(); !errors.IsUnacceptableResponseError(err) {
+			t.Fatalf("request %d - got %v; want an unacceptable response error",
+				i, err)
+		}
+	}
+	n := len(srv.Requests())
+	err = c.Live()
+	var e *errors.CircuitOpenError
+	if !errors.As(err, &e) {
+		t.Fatalf("got %v; want a circuit open error", err)
+	}
+	if e.Failures != 2 || !errors.IsUnacceptableResponseError(e.LastError) {
+		t.Errorf("got failures %d, last error %v", e.Failures, e.LastError)
+	}
+	if m := len(srv.Requests()); m != n {
+		t.Errorf("got %d requests sent while open; want 0", m-n)
+	}
+
+	// Half-open: the probe fails, and the circuit breaker opens again.
+	time.Sleep(Cooldown + time.Millisecond*10)
+	if err = c.Live(); !errors.IsCircuitOpenError(err) {
+		t.Errorf("got %v after a failed probe; want a circuit open error", err)
+	}
+	reqs := srv.Requests()[n:]
+	if len(reqs) != 1 || reqs[0].Endpoint != "ready" {
+		t.Errorf("got requests %+v; want one readiness probe", reqs)
+	}
+
+	// Half-open: the probe succeeds, and the request proceeds.
+	srv.SetHook(nil)
+	time.Sleep(Cooldown + time.Millisecond*10)
+	n = len(srv.Requests())
+	if err = c.Live(); err != nil {
+		t.Errorf("got %v after a successful probe", err)
+	}
+	reqs = srv.Requests()[n:]
+	if len(reqs) != 2 || reqs[0].Endpoint != "ready" ||
+		reqs[1].Endpoint != "live" {
+		t.Errorf("got requests %+v; want a readiness probe and a live request",
+			reqs)
+	}
+}
+
+func TestClient_CircuitBreaker_ClientErrors(t *testing.T) {
+	srv := NewFakeServerForTest(t, nil)
+	opt := srv.ClientOptions()
+	opt.CircuitBreakerThreshold = 2
+	opt.CircuitBreakerCooldown = time.Minute
+	c, err := client.New(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, code := range []int{
+		http.StatusBadRequest,
+		http.StatusUnauthorized,
+		http.StatusForbidden,
+		http.StatusNotFound,
+	} {
+		srv.SetHook(func(req *corenlptest.Request) *corenlptest.Fault {
+			return &corenlptest.Fault{StatusCode: code}
+		})
+		for i := 0; i < 3; i++ {
+			if err = c.Live(); !errors.IsUnacceptableResponseError(err) {
+				t.Fatalf("%d, request %d - got %v; want an unacceptable response error",
+					code, i, err)
+			}
+		}
+	}
+
+	// A client error resets the consecutive server failures.
+	for _, code := range []int{
+		http.StatusTooManyRequests,
+		http.StatusBadRequest,
+		http.StatusInternalServerError,
+	} {
+		srv.SetHook(func(req *corenlptest.Request) *corenlptest.Fault {
+			return &corenlptest.Fault{StatusCode: code}
+		})
+		if err = c.Live(); !errors.IsUnacceptableResponseError(err) {
+			t.Fatalf("%d - got %v; want an unacceptable response error",
+				code, err)
+		}
+	}
+
+	// Two consecutive server failures open the circuit breaker.
+	srv.SetHook(func(req *corenlptest.Request) *corenlptest.Fault {
+		return &corenlptest.Fault{StatusCode: http.StatusTooManyRequests}
+	})
+	if err = c.Live(); !errors.IsUnacceptableResponseError(err) {
+		t.Fatalf("got %v; want an unacceptable response error", err)
+	}
+	if err = c.Live(); !errors.IsCircuitOpenError(err) {
+		t.Errorf("got %v; want a circuit open error", err)
+	}
+}
+
+func TestClient_CircuitBreaker_PrepareErrors(t *testing.T) {
+	const Cooldown = time.Millisecond * 50
+	srv := NewFakeServerForTest(t, nil)
+	auth := new(toggleAuth)
+	opt := srv.ClientOptions()
+	opt.Authenticator = auth
+	opt.CircuitBreakerThreshold = 1
+	opt.CircuitBreakerCooldown = Cooldown
+	c, err := client.New(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := len(srv.Requests())
+	auth.fail.Store(true)
+	for i := 0; i < 3; i++ {
+		if err = c.Live(); !errors.Is(err, errToggleAuth) {
+			t.Fatalf("request %d - got %v; want %v", i, err, errToggleAuth)
+		}
+	}
+	if m := len(srv.Requests()); m != n {
+		t.Errorf("got %d requests sent; want 0", m-n)
+	}
+
+	// Open the circuit breaker, and fail the authentication of the probe.
+	auth.fail.Store(false)
+	srv.SetHook(func(req *corenlptest.Request) *corenlptest.Fault {
+		return &corenlptest.Fault{StatusCode: http.StatusServiceUnavailable}
+	})
+	if err = c.Live(); !errors.IsUnacceptableResponseError(err) {
+		t.Fatalf("got %v; want an unacceptable response error", err)
+	}
+	srv.SetHook(nil)
+	time.Sleep(Cooldown + time.Millisecond*10)
+	auth.fail.Store(true)
+	if err = c.Live(); !errors.Is(err, errToggleAuth) {
+		t.Fatalf("probe - got %v; want %v", err, errToggleAuth)
+	}
+	// The next request probes the server again without waiting.
+	auth.fail.Store(false)
+	if err = c.Live(); err != nil {
+		t.Errorf("got %v after a successful probe", err)
+	}
+}
+
+// errToggleAuth is the error reported by toggleAuth.
+var errToggleAuth = errors.New("token endpoint is down")
+
+// toggleAuth is a client.Authenticator that fails if fail is true.
+type toggleAuth struct {
+	fail atomic.Bool
+}
+
+func (a *toggleAuth) Authenticate(*http.Request) error {
+	if a.fail.Load() {
+		return errToggleAuth
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	stderrors "errors"
+	"log/slog"
+	"net/url"
+
+	"github.com/donyori/gocorenlp/errors"
+	"github.com/donyori/gocorenlp/internal/strutil"
+)
+
+// maxLogBodySize is the number of bytes of a response body
+// preserved in a log record.
+const maxLogBodySize = 200
+
+// redacted replaces sensitive values in log records.
+const redacted = "REDACTED"
+
+// logStart logs the start of the request described by ev
+// at level Debug.
+func (c *clientImpl) logStart(ev *RequestEvent) {
+	if !c.logEnabled(slog.LevelDebug) {
+		return
+	}
+	attrs := []slog.Attr{slog.String("endpoint", ev.Endpoint)}
+	if len(ev.Annotators) > 0 {
+		attrs = append(attrs, slog.String("annotators", ev.Annotators))
+	}
+	c.logger.LogAttrs(context.Background(), slog.LevelDebug,
+		"gocorenlp: request started", attrs...)
+}
+
+// logEnd logs the end of the request described by ev.
+//
+// A failed request is logged at level Error,
+// with the response body (if any) truncated.
+// A successful shutdown request is logged at level Info.
+// Other successful requests are logged at level Debug.
+func (c *clientImpl) logEnd(ev *RequestEvent) {
+	level := slog.LevelDebug
+	switch {
+	case ev.Err != nil:
+		level = slog.LevelError
+	case ev.Endpoint == EndpointShutdown:
+		level = slog.LevelInfo
+	}
+	if !c.logEnabled(level) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("endpoint", ev.Endpoint),
+		slog.Duration("duration", ev.Duration),
+	}
+	if ev.Attempts > 1 {
+		attrs = append(attrs, slog.Int("attempts", ev.Attempts))
+	}
+	if ev.StatusCode != 0 {
+		attrs = append(attrs, slog.Int("status", ev.StatusCode))
+	}
+	if ev.Endpoint == EndpointAnnotate {
+		attrs = append(attrs,
+			slog.String("annotators", ev.Annotators),
+			slog.Int64("requestBytes", ev.RequestBytes),
+			slog.Int64("responseBytes", ev.ResponseBytes),
+			slog.Bool("cacheHit", ev.CacheHit),
+		)
+	}
+	msg := "gocorenlp: request finished"
+	if ev.Err != nil {
+		msg = "gocorenlp: request failed"
+		attrs = append(attrs,
+			slog.String("error", ev.Err.Error()),
+			slog.String("errorClass", ev.ErrorClass),
+		)
+		var e *errors.UnacceptableResponseError
+		if stderrors.As(ev.Err, &e) && len(e.Body) > 0 {
+			attrs = append(attrs,
+				slog.String("body", strutil.Shorten(e.Body, maxLogBodySize)))
+		}
+	}
+	c.logger.LogAttrs(context.Background(), level, msg, attrs...)
+}
+
+// logHTTPRequest logs an HTTP request with the specified method and URL
+// at level Debug.
+//
+// The properties of an annotation request are also logged
+// in a separate attribute for readability.
+// The shutdown key is redacted.
+// Headers, including the credentials set by the authenticator,
+// are never logged.
+func (c *clientImpl) logHTTPRequest(method, rawURL string) {
+	if !c.logEnabled(slog.LevelDebug) {
+		return
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		// This should never happen.
+		return
+	}
+	attrs := []slog.Attr{slog.String("method", method)}
+	qv := u.Query()
+	if prop := qv.Get("properties"); len(prop) > 0 {
+		attrs = append(attrs, slog.String("properties", prop))
+	}
+	if qv.Has("key") {
+		qv.Set("key", redacted)
+		u.RawQuery = qv.Encode()
+	}
+	attrs = append(attrs, slog.String("url", u.String()))
+	c.logger.LogAttrs(context.Background(), slog.LevelDebug,
+		"gocorenlp: sending HTTP request", attrs...)
+}
+
+// logEnabled reports whether the client logs records at the specified level.
+func (c *clientImpl) logEnabled(level slog.Level) bool {
+	return c.logger != nil &&
+		c.logger.Enabled(context.Background(), level)
+}
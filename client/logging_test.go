@@ -0,0 +1,89 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client_test
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/donyori/gocorenlp/client"
+	"github.com/donyori/gocorenlp/client/corenlptest"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+func TestClient_Logger(t *testing.T) {
+	srv := NewFakeServerForTest(t, &corenlptest.Options{
+		Username: Username,
+		Password: Password,
+	})
+	var logs bytes.Buffer
+	opt := srv.ClientOptions()
+	opt.Logger = slog.New(slog.NewTextHandler(&logs,
+		&slog.HandlerOptions{Level: slog.LevelDebug}))
+	c, err := client.New(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.AnnotateString(Text, "tokenize,ssplit,pos", new(pb.Document))
+	if err != nil {
+		t.Fatal(err)
+	}
+	longBody := strings.Repeat("java.lang.NullPointerException ", 100)
+	srv.SetHook(func(req *corenlptest.Request) *corenlptest.Fault {
+		if req.Endpoint != "" {
+			return nil
+		}
+		return &corenlptest.Fault{
+			StatusCode: http.StatusInternalServerError,
+			Body:       longBody,
+		}
+	})
+	if c.AnnotateString(Text, "tokenize,ssplit,pos", new(pb.Document)) == nil {
+		t.Error("got nil error from a failing server")
+	}
+	if err = c.Shutdown(srv.ShutdownKey()); err != nil {
+		t.Fatal(err)
+	}
+
+	got := logs.String()
+	for _, want := range []string{
+		"level=DEBUG msg=\"gocorenlp: request started\" endpoint=live",
+		"level=DEBUG msg=\"gocorenlp: sending HTTP request\" method=POST properties=",
+		"annotators=tokenize,ssplit,pos",
+		"level=ERROR msg=\"gocorenlp: request failed\" endpoint=annotate",
+		"status=500",
+		"level=INFO msg=\"gocorenlp: request finished\" endpoint=shutdown",
+		"key=REDACTED",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("log %q not found", want)
+		}
+	}
+	for _, secret := range []string{Password, srv.ShutdownKey(), longBody} {
+		if strings.Contains(got, secret) {
+			t.Errorf("log contains %q", secret)
+		}
+	}
+	if t.Failed() {
+		t.Log("logs:\n" + got)
+	}
+}
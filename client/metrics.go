@@ -0,0 +1,251 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+)
+
+// DefaultLatencyBuckets are the default upper bounds (in seconds)
+// of the buckets of the request latency histogram of MetricsCollector.
+var DefaultLatencyBuckets = []float64{
+	.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60,
+}
+
+// MetricsCollector is a Hook that collects in-memory metrics of requests,
+// including counters and latency histograms,
+// and exposes them in the Prometheus text exposition format.
+//
+// It exposes the following metrics:
+//   - gocorenlp_client_requests_total (counter):
+//     the number of finished requests,
+//     labeled by endpoint, status code, and error class
+//     (see ClassifyError).
+//   - gocorenlp_client_requests_in_flight (gauge):
+//     the number of requests in progress, labeled by endpoint.
+//   - gocorenlp_client_request_bytes_total (counter):
+//     the number of bytes of text sent with annotation requests.
+//   - gocorenlp_client_response_bytes_total (counter):
+//     the number of bytes of responses to annotation requests.
+//   - gocorenlp_client_cache_hits_total (counter):
+//     the number of annotation requests served by the cache.
+//   - gocorenlp_client_retries_total (counter):
+//     the number of HTTP requests sent in addition to the first one
+//     of each request (see RequestEvent.Attempts).
+//   - gocorenlp_client_request_duration_seconds (histogram):
+//     the latency of finished requests, labeled by endpoint.
+//
+// It is safe for concurrent use.
+type MetricsCollector struct {
+	buckets []float64
+
+	mu            sync.Mutex
+	requests      map[requestsKey]uint64
+	inFlight      map[string]int64
+	requestBytes  uint64
+	responseBytes uint64
+	cacheHits     uint64
+	retries       uint64
+	latency       map[string]*histogram
+}
+
+// requestsKey is the labels of gocorenlp_client_requests_total.
+type requestsKey struct {
+	endpoint, code, errorClass string
+}
+
+// histogram is a cumulative histogram of latency in seconds.
+type histogram struct {
+	counts []uint64 // counts[i] is the number of observations <= buckets[i].
+	count  uint64
+	sum    float64
+}
+
+// NewMetricsCollector creates a new MetricsCollector
+// with the specified upper bounds (in seconds) of
+// the buckets of the request latency histogram.
+//
+// If buckets is empty, DefaultLatencyBuckets is used.
+// The bucket +Inf is always added implicitly.
+func NewMetricsCollector(buckets ...float64) *MetricsCollector {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+	return &MetricsCollector{
+		buckets:  slices.Compact(buckets),
+		requests: make(map[requestsKey]uint64),
+		inFlight: make(map[string]int64),
+		latency:  make(map[string]*histogram),
+	}
+}
+
+// RequestStart increases the number of requests in progress.
+func (mc *MetricsCollector) RequestStart(ev *RequestEvent) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.inFlight[ev.Endpoint]++
+}
+
+// RequestEnd records the finished request.
+func (mc *MetricsCollector) RequestEnd(ev *RequestEvent) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.inFlight[ev.Endpoint]--
+	mc.requests[requestsKey{
+		endpoint:   ev.Endpoint,
+		code:       strconv.Itoa(ev.StatusCode),
+		errorClass: ev.ErrorClass,
+	}]++
+	mc.requestBytes += uint64(max(ev.RequestBytes, 0))
+	mc.responseBytes += uint64(max(ev.ResponseBytes, 0))
+	if ev.CacheHit {
+		mc.cacheHits++
+	}
+	if ev.Attempts > 1 {
+		mc.retries += uint64(ev.Attempts - 1)
+	}
+	h := mc.latency[ev.Endpoint]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(mc.buckets))}
+		mc.latency[ev.Endpoint] = h
+	}
+	sec := ev.Duration.Seconds()
+	for i := len(mc.buckets) - 1; i >= 0 && sec <= mc.buckets[i]; i-- {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += sec
+}
+
+// WritePrometheus writes the metrics to w
+// in the Prometheus text exposition format (version 0.0.4).
+func (mc *MetricsCollector) WritePrometheus(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	mc.mu.Lock()
+	mc.writeTo(bw)
+	mc.mu.Unlock()
+	return gogoerrors.AutoWrap(bw.Flush())
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format,
+// so that MetricsCollector can be registered as the handler of
+// the metrics endpoint scraped by Prometheus.
+func (mc *MetricsCollector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = mc.WritePrometheus(w) // ignore error
+}
+
+// writeTo writes the metrics to w.
+//
+// The caller must hold mc.mu.
+func (mc *MetricsCollector) writeTo(w *bufio.Writer) {
+	const prefix = "gocorenlp_client_"
+
+	writeHeader(w, prefix+"requests_total", "counter",
+		"Number of finished requests.")
+	keys := make([]requestsKey, 0, len(mc.requests))
+	for k := range mc.requests {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b requestsKey) int {
+		if c := strings.Compare(a.endpoint, b.endpoint); c != 0 {
+			return c
+		} else if c = strings.Compare(a.code, b.code); c != 0 {
+			return c
+		}
+		return strings.Compare(a.errorClass, b.errorClass)
+	})
+	for _, k := range keys {
+		_, _ = fmt.Fprintf(w, "%srequests_total{endpoint=%s,code=%s,error=%s} %d\n",
+			prefix, quoteLabel(k.endpoint), quoteLabel(k.code),
+			quoteLabel(k.errorClass), mc.requests[k])
+	}
+
+	writeHeader(w, prefix+"requests_in_flight", "gauge",
+		"Number of requests in progress.")
+	for _, ep := range sortedKeys(mc.inFlight) {
+		_, _ = fmt.Fprintf(w, "%srequests_in_flight{endpoint=%s} %d\n",
+			prefix, quoteLabel(ep), mc.inFlight[ep])
+	}
+
+	writeHeader(w, prefix+"request_bytes_total", "counter",
+		"Number of bytes of text sent with annotation requests.")
+	_, _ = fmt.Fprintf(w, "%srequest_bytes_total %d\n", prefix, mc.requestBytes)
+	writeHeader(w, prefix+"response_bytes_total", "counter",
+		"Number of bytes of responses to annotation requests.")
+	_, _ = fmt.Fprintf(w, "%sresponse_bytes_total %d\n", prefix, mc.responseBytes)
+	writeHeader(w, prefix+"cache_hits_total", "counter",
+		"Number of annotation requests served by the cache.")
+	_, _ = fmt.Fprintf(w, "%scache_hits_total %d\n", prefix, mc.cacheHits)
+	writeHeader(w, prefix+"retries_total", "counter",
+		"Number of HTTP requests sent in addition to the first one of each request.")
+	_, _ = fmt.Fprintf(w, "%sretries_total %d\n", prefix, mc.retries)
+
+	writeHeader(w, prefix+"request_duration_seconds", "histogram",
+		"Latency of finished requests in seconds.")
+	for _, ep := range sortedKeys(mc.latency) {
+		h, label := mc.latency[ep], quoteLabel(ep)
+		for i, b := range mc.buckets {
+			_, _ = fmt.Fprintf(w,
+				"%srequest_duration_seconds_bucket{endpoint=%s,le=%q} %d\n",
+				prefix, label, strconv.FormatFloat(b, 'g', -1, 64),
+				h.counts[i])
+		}
+		_, _ = fmt.Fprintf(w,
+			"%srequest_duration_seconds_bucket{endpoint=%s,le=\"+Inf\"} %d\n",
+			prefix, label, h.count)
+		_, _ = fmt.Fprintf(w, "%srequest_duration_seconds_sum{endpoint=%s} %s\n",
+			prefix, label, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		_, _ = fmt.Fprintf(w, "%srequest_duration_seconds_count{endpoint=%s} %d\n",
+			prefix, label, h.count)
+	}
+}
+
+// writeHeader writes the HELP and TYPE lines of the specified metric to w.
+func writeHeader(w *bufio.Writer, name, typ, help string) {
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// quoteLabel returns the specified label value quoted and escaped
+// as required by the Prometheus text exposition format.
+func quoteLabel(v string) string {
+	v = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+	return `"` + v + `"`
+}
+
+// sortedKeys returns the keys of the specified map in ascending order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
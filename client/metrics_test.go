@@ -0,0 +1,98 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/donyori/gocorenlp/client"
+)
+
+func TestMetricsCollector(t *testing.T) {
+	mc := client.NewMetricsCollector(0.1, 1)
+	events := []*client.RequestEvent{
+		{
+			Endpoint:   client.EndpointLive,
+			Duration:   time.Millisecond * 50,
+			StatusCode: 200,
+		},
+		{
+			Endpoint:      client.EndpointAnnotate,
+			Duration:      time.Millisecond * 500,
+			StatusCode:    200,
+			RequestBytes:  10,
+			ResponseBytes: 100,
+		},
+		{
+			Endpoint:      client.EndpointAnnotate,
+			Duration:      time.Millisecond * 1500,
+			RequestBytes:  10,
+			ResponseBytes: 100,
+			CacheHit:      true,
+		},
+		{
+			Endpoint:   client.EndpointAnnotate,
+			Duration:   time.Millisecond * 50,
+			Attempts:   2,
+			StatusCode: 500,
+			ErrorClass: client.ErrorClassServerTimeout,
+		},
+	}
+	for _, ev := range events {
+		mc.RequestStart(ev)
+		mc.RequestEnd(ev)
+	}
+	mc.RequestStart(&client.RequestEvent{Endpoint: client.EndpointReady})
+
+	rec := httptest.NewRecorder()
+	mc.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("got Content-Type %q", ct)
+	}
+	got := rec.Body.String()
+	for _, want := range []string{
+		`gocorenlp_client_requests_total{endpoint="annotate",code="0",error=""} 1`,
+		`gocorenlp_client_requests_total{endpoint="annotate",code="200",error=""} 1`,
+		`gocorenlp_client_requests_total{endpoint="annotate",code="500",error="server_timeout"} 1`,
+		`gocorenlp_client_requests_total{endpoint="live",code="200",error=""} 1`,
+		`gocorenlp_client_requests_in_flight{endpoint="annotate"} 0`,
+		`gocorenlp_client_requests_in_flight{endpoint="ready"} 1`,
+		`gocorenlp_client_request_bytes_total 20`,
+		`gocorenlp_client_response_bytes_total 200`,
+		`gocorenlp_client_cache_hits_total 1`,
+		`gocorenlp_client_retries_total 1`,
+		`# TYPE gocorenlp_client_request_duration_seconds histogram`,
+		`gocorenlp_client_request_duration_seconds_bucket{endpoint="annotate",le="0.1"} 1`,
+		`gocorenlp_client_request_duration_seconds_bucket{endpoint="annotate",le="1"} 2`,
+		`gocorenlp_client_request_duration_seconds_bucket{endpoint="annotate",le="+Inf"} 3`,
+		`gocorenlp_client_request_duration_seconds_sum{endpoint="annotate"} 2.05`,
+		`gocorenlp_client_request_duration_seconds_count{endpoint="annotate"} 3`,
+		`gocorenlp_client_request_duration_seconds_bucket{endpoint="live",le="0.1"} 1`,
+	} {
+		if !strings.Contains(got, want+"\n") {
+			t.Errorf("metric %q not found", want)
+		}
+	}
+	if t.Failed() {
+		t.Log("metrics:\n" + got)
+	}
+}
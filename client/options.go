@@ -19,14 +19,29 @@
 package client
 
 import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"net/netip"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
+
+	gogoerrors "github.com/donyori/gogo/errors"
 )
 
 // Options are the configuration for creating a new client.
 type Options struct {
+	// Scheme is the URL scheme used to connect to the target server,
+	// either "http" or "https".
+	//
+	// Use "https" for the servers behind TLS-terminating gateways.
+	//
+	// Default: http
+	Scheme string `json:"scheme,omitempty"`
+
 	// Hostname is the host (without port number) of the target server.
 	//
 	// Default: 127.0.0.1
@@ -34,9 +49,19 @@ type Options struct {
 
 	// Port is the port of the target server.
 	//
-	// Default: 9000
+	// Default: 443 if Scheme is "https"; 9000 otherwise
 	Port uint16 `json:"port,omitempty"`
 
+	// PathPrefix is the URL path prefix of the target server,
+	// such as "/corenlp" for a server behind a gateway that
+	// forwards "https://example.com/corenlp/..." to the server.
+	//
+	// It applies to all endpoints, including the liveness and
+	// readiness endpoints.
+	//
+	// Default: "" (empty, no prefix)
+	PathPrefix string `json:"pathPrefix,omitempty"`
+
 	// StatusPort is the port of the target server to run
 	// the liveness and readiness server on.
 	// If zero, treat it the same as the main server.
@@ -60,6 +85,8 @@ type Options struct {
 	// Username is the username sent with the request.
 	// Set this along with Password if the target server requires basic auth.
 	//
+	// It is ignored if Authenticator is set.
+	//
 	// Default: "" (empty)
 	Username string `json:"username,omitempty"`
 
@@ -71,6 +98,18 @@ type Options struct {
 	// Default: "" (empty)
 	Password string `json:"password,omitempty"`
 
+	// Authenticator decorates each request with credentials,
+	// such as bearer tokens and custom headers required by an API gateway.
+	//
+	// See BasicAuth, StaticHeader, BearerToken, and RefreshingToken
+	// for the built-in implementations.
+	//
+	// If nil and Username is not empty,
+	// BasicAuth(Username, Password) is used.
+	//
+	// Default: nil
+	Authenticator Authenticator `json:"-"`
+
 	// Charset is the character encoding of the request
 	// set in the Content-Type header.
 	//
@@ -85,9 +124,23 @@ type Options struct {
 	// For example:
 	//  tokenize,ssplit,pos,depparse
 	//
+	// PipelineBuilder can build it with the prerequisites
+	// of the annotators resolved.
+	//
 	// Default: "" (empty, no annotator is specified by default)
 	Annotators string `json:"annotators,omitempty"`
 
+	// Properties are additional properties sent with
+	// every annotation request, such as
+	//  "tokenize.language": "en"
+	//  "ner.applyFineGrained": "false"
+	//
+	// The properties "annotators", "outputFormat", and "serializer"
+	// are set by the client and cannot be overridden here.
+	//
+	// Default: nil
+	Properties map[string]string `json:"properties,omitempty"`
+
 	// ServerID is the value of the option -server_id used
 	// when starting the target server.
 	//
@@ -96,6 +149,175 @@ type Options struct {
 	// Default: "" (empty)
 	ServerID string `json:"serverID,omitempty"`
 
+	// Transport is the HTTP transport used by the client
+	// to send requests and receive responses.
+	//
+	// It can be used to customize the connections,
+	// or to intercept the requests, such as recording and replaying
+	// responses with the package
+	// github.com/donyori/gocorenlp/client/cassette.
+	//
+	// If nil, http.DefaultTransport is used.
+	//
+	// If any of the fields TLSConfig, CAFile, CertFile, KeyFile,
+	// ProxyURL, and Proxy is set, Transport must be nil or
+	// of type *http.Transport. In that case, the client uses a clone
+	// of Transport (or http.DefaultTransport if nil)
+	// with these settings applied.
+	//
+	// Default: nil
+	Transport http.RoundTripper `json:"-"`
+
+	// TLSConfig is the TLS configuration for the HTTPS connections.
+	//
+	// The fields CAFile, CertFile, and KeyFile are applied to
+	// a clone of TLSConfig.
+	//
+	// Default: nil
+	TLSConfig *tls.Config `json:"-"`
+
+	// CAFile is the path to a PEM-encoded CA certificate bundle
+	// for verifying the server certificate,
+	// in addition to the system certificate pool
+	// (or TLSConfig.RootCAs if set).
+	//
+	// Default: "" (empty)
+	CAFile string `json:"caFile,omitempty"`
+
+	// CertFile and KeyFile are the paths to the PEM-encoded
+	// client certificate and its private key,
+	// for the servers requiring client certificates.
+	//
+	// They must be set together.
+	//
+	// Default: "" (empty)
+	CertFile string `json:"certFile,omitempty"`
+
+	// KeyFile is the path to the PEM-encoded private key
+	// of the client certificate. See CertFile.
+	//
+	// Default: "" (empty)
+	KeyFile string `json:"keyFile,omitempty"`
+
+	// ProxyURL is the URL of the proxy server used for all requests,
+	// such as "http://proxy.example.com:3128".
+	//
+	// If both ProxyURL and Proxy are empty, the proxy is determined by
+	// the environment variables HTTP_PROXY, HTTPS_PROXY, and NO_PROXY
+	// (as http.DefaultTransport does).
+	//
+	// Default: "" (empty)
+	ProxyURL string `json:"proxyURL,omitempty"`
+
+	// Proxy is a function that returns the proxy for the given request,
+	// as the field Proxy of http.Transport.
+	// It takes precedence over ProxyURL.
+	//
+	// Default: nil
+	Proxy func(req *http.Request) (*url.URL, error) `json:"-"`
+
+	// Cache caches the raw serialized responses of annotation requests,
+	// so that identical requests are annotated only once.
+	// Concurrent identical requests are also deduplicated
+	// into one request to the server.
+	//
+	// See MemoryCache and DiskCache for the built-in implementations.
+	//
+	// Errors reported by Cache are ignored,
+	// and the request is sent to the server as if the cache missed.
+	//
+	// Default: nil (no cache)
+	Cache Cache `json:"-"`
+
+	// ServerVersion is the version of the target server, such as "4.5.6".
+	//
+	// It is only used as a part of the cache key,
+	// so that a shared cache does not mix up the responses
+	// from different versions of the server.
+	//
+	// Default: "" (empty)
+	ServerVersion string `json:"serverVersion,omitempty"`
+
+	// Hooks are called at the start and end of every request
+	// made by the client, in order.
+	//
+	// See MetricsCollector for a built-in implementation.
+	//
+	// Default: nil
+	Hooks []Hook `json:"-"`
+
+	// Logger logs the lifecycle of requests made by the client,
+	// including the URL, annotators, and properties sent,
+	// and the status, duration, and sizes of the response.
+	//
+	// Requests are logged at level Debug,
+	// failures at level Error (with the response body truncated),
+	// and successful shutdown requests at level Info.
+	// The password and the shutdown key are redacted,
+	// and the credentials set by Authenticator are never logged.
+	//
+	// Default: nil (no logging)
+	Logger *slog.Logger `json:"-"`
+
+	// RateLimit is the maximum average number of requests per second
+	// sent by the client, enforced by a token-bucket rate limiter.
+	// Requests exceeding the limit wait for their turn.
+	//
+	// A non-positive value means no limit.
+	//
+	// Default: 0
+	RateLimit float64 `json:"rateLimit,omitempty"`
+
+	// RateBurst is the maximum number of requests sent at once
+	// without waiting for the rate limiter (the capacity of the bucket).
+	//
+	// Only valid when RateLimit is positive.
+	// A value less than 1 is treated as 1.
+	//
+	// Default: 0
+	RateBurst int `json:"rateBurst,omitempty"`
+
+	// MaxInFlight is the maximum number of requests in progress
+	// (until the response body is read and closed) at the same time.
+	// Requests exceeding the limit wait for a slot.
+	//
+	// A non-positive value means no limit.
+	//
+	// Default: 0
+	MaxInFlight int `json:"maxInFlight,omitempty"`
+
+	// CircuitBreakerThreshold is the number of consecutive failures
+	// (connection errors and responses with status 5XX or
+	// 429 Too Many Requests) to open the circuit breaker.
+	// Other responses, including 4XX caused by the client
+	// (such as a wrong credential or invalid properties),
+	// reset the consecutive failures.
+	// Errors on the client side with which no request is sent
+	// (such as a failure of Authenticator) are not counted.
+	//
+	// While the circuit breaker is open, requests fail fast with
+	// *github.com/donyori/gocorenlp/errors.CircuitOpenError
+	// without being sent.
+	// After CircuitBreakerCooldown, the next request probes
+	// the server with the readiness endpoint (half-open):
+	// if the server is ready, the circuit breaker closes
+	// and the request proceeds;
+	// otherwise, the circuit breaker stays open for another cooldown.
+	//
+	// A non-positive value disables the circuit breaker.
+	//
+	// Default: 0
+	CircuitBreakerThreshold int `json:"circuitBreakerThreshold,omitempty"`
+
+	// CircuitBreakerCooldown is the time for which the circuit breaker
+	// stays open before probing the server.
+	//
+	// Only valid when CircuitBreakerThreshold is positive.
+	// A non-positive value is treated as DefaultCircuitBreakerCooldown.
+	//
+	// Default: 30s
+	CircuitBreakerCooldown time.Duration `json:"circuitBreakerCooldown,omitempty"`
+
 	// onlyKeyedLiterals forces others to construct Options
 	// only with the keyed literals, so future additions to it
 	// will not violate compatibility.
@@ -106,6 +328,9 @@ var _ = Options{}.onlyKeyedLiterals // to suppress "field `onlyKeyedLiterals` is
 
 // GetHosts returns the hosts (including the hostname part and
 // the port number part) for the main server and status server.
+//
+// If Port is zero, the port is 443 for the scheme "https",
+// and 9000 otherwise.
 func (opt *Options) GetHosts() (main, status string) {
 	if opt == nil {
 		main = "127.0.0.1:9000"
@@ -118,7 +343,11 @@ func (opt *Options) GetHosts() (main, status string) {
 	}
 	port, statusPort := opt.Port, opt.StatusPort
 	if port == 0 {
-		port = 9000
+		if strings.EqualFold(strings.TrimSpace(opt.Scheme), "https") {
+			port = 443
+		} else {
+			port = 9000
+		}
 	}
 	if statusPort == 0 {
 		statusPort = port
@@ -144,3 +373,28 @@ func (opt *Options) GetHosts() (main, status string) {
 	}
 	return
 }
+
+// GetBaseURLs returns the base URLs for the main server and status server,
+// consisting of the scheme, the host (see GetHosts), and the path prefix.
+//
+// It reports an error if the scheme is neither "http" nor "https".
+func (opt *Options) GetBaseURLs() (main, status *url.URL, err error) {
+	scheme, prefix := "http", ""
+	if opt != nil {
+		if s := strings.ToLower(strings.TrimSpace(opt.Scheme)); len(s) > 0 {
+			scheme = s
+		}
+		prefix = strings.Trim(strings.TrimSpace(opt.PathPrefix), "/")
+		if len(prefix) > 0 {
+			prefix = "/" + prefix
+		}
+	}
+	if scheme != "http" && scheme != "https" {
+		return nil, nil, gogoerrors.AutoNew(fmt.Sprintf(
+			"unsupported scheme %q; want http or https", scheme))
+	}
+	mainHost, statusHost := opt.GetHosts()
+	main = &url.URL{Scheme: scheme, Host: mainHost, Path: prefix}
+	status = &url.URL{Scheme: scheme, Host: statusHost, Path: prefix}
+	return
+}
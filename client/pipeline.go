@@ -0,0 +1,447 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+
+	"github.com/donyori/gocorenlp/errors"
+)
+
+// Annotator is the name of a Stanford CoreNLP annotator,
+// such as "tokenize" and "depparse".
+type Annotator string
+
+// Built-in annotators of Stanford CoreNLP.
+const (
+	AnnotatorTokenize       Annotator = "tokenize"
+	AnnotatorCleanXML       Annotator = "cleanxml"
+	AnnotatorDocDate        Annotator = "docdate"
+	AnnotatorSSplit         Annotator = "ssplit"
+	AnnotatorMWT            Annotator = "mwt"
+	AnnotatorPOS            Annotator = "pos"
+	AnnotatorLemma          Annotator = "lemma"
+	AnnotatorNER            Annotator = "ner"
+	AnnotatorRegexNER       Annotator = "regexner"
+	AnnotatorTokensRegex    Annotator = "tokensregex"
+	AnnotatorEntityMentions Annotator = "entitymentions"
+	AnnotatorGender         Annotator = "gender"
+	AnnotatorTrueCase       Annotator = "truecase"
+	AnnotatorParse          Annotator = "parse"
+	AnnotatorDepParse       Annotator = "depparse"
+	AnnotatorUDFeats        Annotator = "udfeats"
+	AnnotatorSentiment      Annotator = "sentiment"
+	AnnotatorCoref          Annotator = "coref"
+	AnnotatorDCoref         Annotator = "dcoref"
+	AnnotatorNatLog         Annotator = "natlog"
+	AnnotatorOpenIE         Annotator = "openie"
+	AnnotatorRelation       Annotator = "relation"
+	AnnotatorKBP            Annotator = "kbp"
+	AnnotatorEntityLink     Annotator = "entitylink"
+	AnnotatorQuote          Annotator = "quote"
+)
+
+// ParseAnnotators splits the specified comma-separated annotators,
+// such as "tokenize,ssplit,pos", into a list of annotators.
+//
+// White space around the names is dropped, and empty names are ignored.
+func ParseAnnotators(s string) []Annotator {
+	fields := strings.Split(s, ",")
+	list := make([]Annotator, 0, len(fields))
+	for _, field := range fields {
+		field = strings.Join(strings.Fields(field), "")
+		if len(field) > 0 {
+			list = append(list, Annotator(field))
+		}
+	}
+	return list
+}
+
+// JoinAnnotators joins the specified annotators with commas (,)
+// into the string used by Options.Annotators and the methods of Client.
+func JoinAnnotators(annotators ...Annotator) string {
+	var b strings.Builder
+	for i, a := range annotators {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(string(a))
+	}
+	return b.String()
+}
+
+// AnnotatorSpec describes the prerequisites of an annotator.
+type AnnotatorSpec struct {
+	// Name is the name of the annotator.
+	Name Annotator
+
+	// Requires are the annotators that must run before this annotator,
+	// in the order in which they are inserted if missing.
+	//
+	// Only the direct prerequisites need to be listed.
+	// The prerequisites of prerequisites are resolved recursively.
+	Requires []Annotator
+
+	// Satisfies are the annotators whose role this annotator can take
+	// as a prerequisite of other annotators.
+	//
+	// For example, "parse" satisfies the requirement of "depparse"
+	// because it also builds dependency graphs.
+	Satisfies []Annotator
+
+	// onlyKeyedLiterals forces others to construct AnnotatorSpec
+	// only with the keyed literals, so future additions to it
+	// will not violate compatibility.
+	onlyKeyedLiterals struct{}
+}
+
+var _ = AnnotatorSpec{}.onlyKeyedLiterals // to suppress "field `onlyKeyedLiterals` is unused (unused)"
+
+// builtinAnnotatorSpecs are the specifications of the built-in annotators,
+// following the default requirements of Stanford CoreNLP 4.5.
+var builtinAnnotatorSpecs = []AnnotatorSpec{
+	{Name: AnnotatorTokenize},
+	{Name: AnnotatorCleanXML, Requires: []Annotator{AnnotatorTokenize}},
+	{Name: AnnotatorDocDate},
+	{Name: AnnotatorSSplit, Requires: []Annotator{AnnotatorTokenize}},
+	{Name: AnnotatorMWT, Requires: []Annotator{AnnotatorSSplit}},
+	{Name: AnnotatorPOS, Requires: []Annotator{AnnotatorSSplit}},
+	{Name: AnnotatorLemma, Requires: []Annotator{AnnotatorPOS}},
+	{Name: AnnotatorNER, Requires: []Annotator{AnnotatorLemma}},
+	{Name: AnnotatorRegexNER, Requires: []Annotator{AnnotatorPOS}},
+	{Name: AnnotatorTokensRegex, Requires: []Annotator{AnnotatorSSplit}},
+	{Name: AnnotatorEntityMentions, Requires: []Annotator{AnnotatorNER}},
+	{Name: AnnotatorGender, Requires: []Annotator{AnnotatorNER}},
+	{Name: AnnotatorTrueCase, Requires: []Annotator{AnnotatorLemma}},
+	{
+		Name:      AnnotatorParse,
+		Requires:  []Annotator{AnnotatorPOS},
+		Satisfies: []Annotator{AnnotatorDepParse},
+	},
+	{Name: AnnotatorDepParse, Requires: []Annotator{AnnotatorPOS}},
+	{Name: AnnotatorUDFeats, Requires: []Annotator{AnnotatorDepParse}},
+	{Name: AnnotatorSentiment, Requires: []Annotator{AnnotatorParse}},
+	{
+		Name:     AnnotatorCoref,
+		Requires: []Annotator{AnnotatorNER, AnnotatorDepParse},
+	},
+	{
+		Name:     AnnotatorDCoref,
+		Requires: []Annotator{AnnotatorNER, AnnotatorParse},
+	},
+	{
+		Name:     AnnotatorNatLog,
+		Requires: []Annotator{AnnotatorLemma, AnnotatorDepParse},
+	},
+	{Name: AnnotatorOpenIE, Requires: []Annotator{AnnotatorNatLog}},
+	{
+		Name:     AnnotatorRelation,
+		Requires: []Annotator{AnnotatorNER, AnnotatorDepParse},
+	},
+	{
+		Name:     AnnotatorKBP,
+		Requires: []Annotator{AnnotatorNER, AnnotatorDepParse},
+	},
+	{
+		Name:     AnnotatorEntityLink,
+		Requires: []Annotator{AnnotatorEntityMentions},
+	},
+	{Name: AnnotatorQuote, Requires: []Annotator{AnnotatorSSplit}},
+}
+
+// AnnotatorRegistry is a set of annotator specifications,
+// used by PipelineBuilder to resolve the prerequisites of annotators.
+//
+// It is safe for concurrent use.
+type AnnotatorRegistry struct {
+	mu    sync.RWMutex
+	specs map[Annotator]*AnnotatorSpec
+}
+
+// NewAnnotatorRegistry creates a new AnnotatorRegistry
+// with the built-in annotators of Stanford CoreNLP registered.
+//
+// Custom annotators can be registered by its method Register.
+func NewAnnotatorRegistry() *AnnotatorRegistry {
+	r := &AnnotatorRegistry{
+		specs: make(map[Annotator]*AnnotatorSpec, len(builtinAnnotatorSpecs)),
+	}
+	for i := range builtinAnnotatorSpecs {
+		r.specs[builtinAnnotatorSpecs[i].Name] = copyAnnotatorSpec(
+			&builtinAnnotatorSpecs[i])
+	}
+	return r
+}
+
+// Register adds the specified annotator specification to the registry,
+// replacing the existing one with the same name.
+//
+// It reports an error if the name of the annotator is empty
+// or contains commas or white space,
+// or if the annotator requires itself.
+func (r *AnnotatorRegistry) Register(spec AnnotatorSpec) error {
+	err := checkAnnotatorName(spec.Name)
+	if err != nil {
+		return gogoerrors.AutoWrap(err)
+	}
+	for _, a := range spec.Requires {
+		if a == spec.Name {
+			return gogoerrors.AutoNew(fmt.Sprintf(
+				"annotator %q requires itself", spec.Name))
+		} else if err = checkAnnotatorName(a); err != nil {
+			return gogoerrors.AutoWrap(err)
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.specs[spec.Name] = copyAnnotatorSpec(&spec)
+	return nil
+}
+
+// Lookup returns the specification of the specified annotator.
+//
+// It returns false if the annotator is not registered.
+func (r *AnnotatorRegistry) Lookup(name Annotator) (
+	spec AnnotatorSpec, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p := r.specs[name]
+	if p == nil {
+		return
+	}
+	return *copyAnnotatorSpec(p), true
+}
+
+// Annotators returns the names of the registered annotators,
+// sorted in ascending order.
+func (r *AnnotatorRegistry) Annotators() []Annotator {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]Annotator, 0, len(r.specs))
+	for name := range r.specs {
+		list = append(list, name)
+	}
+	slices.Sort(list)
+	return list
+}
+
+// PipelineBuilder builds a list of annotators for an annotation request.
+//
+// It resolves the prerequisites of the added annotators
+// according to an AnnotatorRegistry,
+// inserts the missing ones (or rejects them in the strict mode),
+// drops duplicates, and orders the annotators
+// so that each annotator follows its prerequisites.
+// Apart from that, the order in which annotators are added is preserved.
+//
+// The zero value is not ready for use.
+// Create it by NewPipelineBuilder.
+type PipelineBuilder struct {
+	registry   *AnnotatorRegistry
+	strict     bool
+	annotators []Annotator
+}
+
+// NewPipelineBuilder creates a new PipelineBuilder
+// that resolves prerequisites with the specified registry
+// and adds the specified annotators.
+//
+// If registry is nil, a registry returned by NewAnnotatorRegistry is used.
+func NewPipelineBuilder(
+	registry *AnnotatorRegistry,
+	annotators ...Annotator,
+) *PipelineBuilder {
+	if registry == nil {
+		registry = NewAnnotatorRegistry()
+	}
+	return &PipelineBuilder{
+		registry:   registry,
+		annotators: slices.Clone(annotators),
+	}
+}
+
+// Add adds the specified annotators to the pipeline.
+//
+// It returns the builder itself for chaining.
+func (b *PipelineBuilder) Add(annotators ...Annotator) *PipelineBuilder {
+	b.annotators = append(b.annotators, annotators...)
+	return b
+}
+
+// AddString adds the annotators in the specified comma-separated string,
+// such as "tokenize,ssplit,pos", to the pipeline.
+//
+// It returns the builder itself for chaining.
+func (b *PipelineBuilder) AddString(annotators string) *PipelineBuilder {
+	return b.Add(ParseAnnotators(annotators)...)
+}
+
+// Strict sets whether the builder rejects missing prerequisites
+// instead of inserting them.
+//
+// It returns the builder itself for chaining.
+func (b *PipelineBuilder) Strict(strict bool) *PipelineBuilder {
+	b.strict = strict
+	return b
+}
+
+// Annotators resolves the prerequisites and
+// returns the resulting list of annotators in order.
+//
+// It reports an *github.com/donyori/gocorenlp/errors.UnknownAnnotatorError
+// if an annotator is not registered,
+// and an *github.com/donyori/gocorenlp/errors.MissingAnnotatorDependencyError
+// if the builder is strict and a prerequisite is missing.
+// The field Response of these errors is nil.
+// It also reports an error if the prerequisites form a cycle.
+func (b *PipelineBuilder) Annotators() ([]Annotator, error) {
+	b.registry.mu.RLock()
+	defer b.registry.mu.RUnlock()
+	res := &pipelineResolver{
+		specs:     b.registry.specs,
+		strict:    b.strict,
+		requested: b.annotators,
+		state:     make(map[Annotator]int8),
+	}
+	for _, a := range b.annotators {
+		if _, ok := res.specs[a]; !ok {
+			return nil, gogoerrors.AutoWrap(
+				&errors.UnknownAnnotatorError{Annotator: string(a)})
+		}
+	}
+	for _, a := range b.annotators {
+		if err := res.visit(a); err != nil {
+			return nil, gogoerrors.AutoWrap(err)
+		}
+	}
+	return res.list, nil
+}
+
+// Build resolves the prerequisites and returns the resulting annotators
+// as a comma-separated string, which can be used as Options.Annotators
+// and the argument annotators of the methods of Client.
+//
+// It reports the same errors as the method Annotators.
+func (b *PipelineBuilder) Build() (string, error) {
+	list, err := b.Annotators()
+	if err != nil {
+		return "", gogoerrors.AutoWrap(err)
+	}
+	return JoinAnnotators(list...), nil
+}
+
+// pipelineResolver resolves the prerequisites of annotators
+// by depth-first search.
+type pipelineResolver struct {
+	specs     map[Annotator]*AnnotatorSpec
+	strict    bool
+	requested []Annotator        // requested are the annotators added by the user.
+	state     map[Annotator]int8 // state is 1 for visiting and 2 for visited.
+	list      []Annotator
+}
+
+// visit appends the prerequisites of the specified annotator
+// and then the annotator itself to the list, if not yet appended.
+func (res *pipelineResolver) visit(a Annotator) error {
+	switch res.state[a] {
+	case 1:
+		return gogoerrors.AutoNew(fmt.Sprintf(
+			"annotator %q depends on itself through its prerequisites", a))
+	case 2:
+		return nil
+	}
+	spec := res.specs[a]
+	if spec == nil {
+		return gogoerrors.AutoWrap(
+			&errors.UnknownAnnotatorError{Annotator: string(a)})
+	}
+	res.state[a] = 1
+	for _, r := range spec.Requires {
+		provider := res.provider(r)
+		if provider == "" {
+			if res.strict {
+				return gogoerrors.AutoWrap(&errors.MissingAnnotatorDependencyError{
+					Annotator:   string(a),
+					Requirement: string(r),
+				})
+			}
+			provider = r
+		}
+		if err := res.visit(provider); err != nil {
+			return gogoerrors.AutoWrap(err)
+		}
+	}
+	res.state[a] = 2
+	res.list = append(res.list, a)
+	return nil
+}
+
+// provider returns the annotator that fulfills
+// the specified requirement among the resolved and requested annotators.
+//
+// It returns the requirement itself if it is resolved or requested.
+// Otherwise, it returns an annotator that satisfies the requirement,
+// preferring the resolved ones.
+// It returns an empty string if no such annotator.
+func (res *pipelineResolver) provider(r Annotator) Annotator {
+	if res.state[r] != 0 || slices.Contains(res.requested, r) {
+		return r
+	}
+	for _, a := range res.list {
+		if slices.Contains(res.specs[a].Satisfies, r) {
+			return a
+		}
+	}
+	for _, a := range res.requested {
+		if spec := res.specs[a]; res.state[a] == 0 &&
+			slices.Contains(spec.Satisfies, r) {
+			return a
+		}
+	}
+	return ""
+}
+
+// checkAnnotatorName reports an error if the specified annotator name
+// is empty or contains commas or white space.
+func checkAnnotatorName(name Annotator) error {
+	if name == "" {
+		return gogoerrors.AutoNew("annotator name is empty")
+	} else if strings.ContainsFunc(string(name), func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	}) {
+		return gogoerrors.AutoNew(fmt.Sprintf(
+			"annotator name %q contains commas or white space", name))
+	}
+	return nil
+}
+
+// copyAnnotatorSpec returns a deep copy of the specified specification.
+func copyAnnotatorSpec(spec *AnnotatorSpec) *AnnotatorSpec {
+	return &AnnotatorSpec{
+		Name:      spec.Name,
+		Requires:  slices.Clone(spec.Requires),
+		Satisfies: slices.Clone(spec.Satisfies),
+	}
+}
@@ -0,0 +1,153 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/donyori/gocorenlp/client"
+	"github.com/donyori/gocorenlp/errors"
+)
+
+func TestParseAnnotators(t *testing.T) {
+	got := client.ParseAnnotators(" tokenize, ssplit,,pos ,")
+	want := []client.Annotator{
+		client.AnnotatorTokenize,
+		client.AnnotatorSSplit,
+		client.AnnotatorPOS,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if s := client.JoinAnnotators(got...); s != "tokenize,ssplit,pos" {
+		t.Errorf("got joined %q; want %q", s, "tokenize,ssplit,pos")
+	}
+}
+
+func TestPipelineBuilder_Build(t *testing.T) {
+	testCases := []struct {
+		annotators string
+		want       string
+	}{
+		{"", ""},
+		{"tokenize,ssplit,pos", "tokenize,ssplit,pos"},
+		{"pos", "tokenize,ssplit,pos"},
+		{"pos,tokenize,ssplit", "tokenize,ssplit,pos"},
+		{"ner", "tokenize,ssplit,pos,lemma,ner"},
+		{"ner,lemma,ner", "tokenize,ssplit,pos,lemma,ner"},
+		{"depparse,ner", "tokenize,ssplit,pos,depparse,lemma,ner"},
+		{"coref", "tokenize,ssplit,pos,lemma,ner,depparse,coref"},
+		{"coref,parse", "tokenize,ssplit,pos,lemma,ner,parse,coref"},
+		{"openie", "tokenize,ssplit,pos,lemma,depparse,natlog,openie"},
+		{"sentiment", "tokenize,ssplit,pos,parse,sentiment"},
+	}
+
+	for _, tc := range testCases {
+		t.Run("annotators="+tc.annotators, func(t *testing.T) {
+			got, err := client.NewPipelineBuilder(nil).
+				AddString(tc.annotators).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("got %q; want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPipelineBuilder_Strict(t *testing.T) {
+	b := client.NewPipelineBuilder(nil,
+		client.AnnotatorTokenize,
+		client.AnnotatorSSplit,
+		client.AnnotatorPOS,
+		client.AnnotatorNER,
+	).Strict(true)
+	_, err := b.Build()
+	var e *errors.MissingAnnotatorDependencyError
+	if !errors.As(err, &e) {
+		t.Fatalf("got %v; want a missing annotator dependency error", err)
+	}
+	if e.Annotator != "ner" || e.Requirement != "lemma" {
+		t.Errorf("got annotator %q, requirement %q; want %q, %q",
+			e.Annotator, e.Requirement, "ner", "lemma")
+	}
+
+	got, err := b.Add(client.AnnotatorLemma).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "tokenize,ssplit,pos,lemma,ner"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestPipelineBuilder_UnknownAnnotator(t *testing.T) {
+	_, err := client.NewPipelineBuilder(nil).AddString("tokenize,foo").Build()
+	var e *errors.UnknownAnnotatorError
+	if !errors.As(err, &e) {
+		t.Fatalf("got %v; want an unknown annotator error", err)
+	}
+	if e.Annotator != "foo" {
+		t.Errorf("got annotator %q; want %q", e.Annotator, "foo")
+	}
+}
+
+func TestAnnotatorRegistry_Register(t *testing.T) {
+	r := client.NewAnnotatorRegistry()
+	err := r.Register(client.AnnotatorSpec{
+		Name:     "custom",
+		Requires: []client.Annotator{client.AnnotatorLemma},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := client.NewPipelineBuilder(r, "custom").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "tokenize,ssplit,pos,lemma,custom"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+
+	// A cycle of prerequisites.
+	for _, spec := range []client.AnnotatorSpec{
+		{Name: "a", Requires: []client.Annotator{"b"}},
+		{Name: "b", Requires: []client.Annotator{"a"}},
+	} {
+		if err = r.Register(spec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = client.NewPipelineBuilder(r, "a").Build(); err == nil {
+		t.Error("got nil error with a cycle of prerequisites")
+	}
+
+	for _, spec := range []client.AnnotatorSpec{
+		{Name: ""},
+		{Name: "a,b"},
+		{Name: "self", Requires: []client.Annotator{"self"}},
+	} {
+		if r.Register(spec) == nil {
+			t.Errorf("got nil error when registering %q", spec.Name)
+		}
+	}
+}
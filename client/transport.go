@@ -0,0 +1,119 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+)
+
+// newTransport returns the HTTP transport for the client
+// according to the specified options.
+//
+// It returns opt.Transport unchanged if none of the TLS and
+// proxy options are set.
+func newTransport(opt *Options) (http.RoundTripper, error) {
+	caFile := strings.TrimSpace(opt.CAFile)
+	certFile := strings.TrimSpace(opt.CertFile)
+	keyFile := strings.TrimSpace(opt.KeyFile)
+	proxyURL := strings.TrimSpace(opt.ProxyURL)
+	if opt.TLSConfig == nil && caFile == "" && certFile == "" &&
+		keyFile == "" && proxyURL == "" && opt.Proxy == nil {
+		return opt.Transport, nil
+	}
+	var t *http.Transport
+	switch rt := opt.Transport.(type) {
+	case nil:
+		t = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		t = rt.Clone()
+	default:
+		return nil, gogoerrors.AutoNew(fmt.Sprintf(
+			"TLS and proxy options require the transport to be nil or "+
+				"*http.Transport, but got %T", rt))
+	}
+	if opt.TLSConfig != nil || caFile != "" || certFile != "" || keyFile != "" {
+		tlsConfig, err := newTLSConfig(opt.TLSConfig, caFile, certFile, keyFile)
+		if err != nil {
+			return nil, gogoerrors.AutoWrap(err)
+		}
+		t.TLSClientConfig = tlsConfig
+	}
+	if opt.Proxy != nil {
+		t.Proxy = opt.Proxy
+	} else if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, gogoerrors.AutoWrap(err)
+		}
+		t.Proxy = http.ProxyURL(u)
+	}
+	return t, nil
+}
+
+// newTLSConfig returns a clone of base (or a new configuration if base
+// is nil) with the CA bundle and the client certificate loaded
+// from the specified files.
+//
+// Empty file names are ignored.
+func newTLSConfig(base *tls.Config, caFile, certFile, keyFile string) (
+	*tls.Config, error) {
+	var cfg *tls.Config
+	if base != nil {
+		cfg = base.Clone()
+	} else {
+		cfg = new(tls.Config)
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, gogoerrors.AutoWrap(err)
+		}
+		pool := cfg.RootCAs
+		if pool != nil {
+			pool = pool.Clone()
+		} else if pool, err = x509.SystemCertPool(); err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, gogoerrors.AutoNew(fmt.Sprintf(
+				"no certificate found in CA file %q", caFile))
+		}
+		cfg.RootCAs = pool
+	}
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, gogoerrors.AutoNew(
+				"client certificate file and key file must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, gogoerrors.AutoWrap(err)
+		}
+		cfg.Certificates = append(cfg.Certificates, cert)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,262 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client_test
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/donyori/gocorenlp/client"
+	"github.com/donyori/gocorenlp/client/corenlptest"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+func TestOptions_GetBaseURLs(t *testing.T) {
+	testCases := []struct {
+		opt          *client.Options
+		main, status string
+	}{
+		{nil, "http://127.0.0.1:9000", "http://127.0.0.1:9000"},
+		{
+			&client.Options{
+				Scheme:     "HTTPS",
+				Hostname:   "nlp.example.com",
+				Port:       443,
+				StatusPort: 8443,
+				PathPrefix: "/corenlp/",
+			},
+			"https://nlp.example.com:443/corenlp",
+			"https://nlp.example.com:8443/corenlp",
+		},
+		{
+			&client.Options{Scheme: "https", Hostname: "nlp.example.com"},
+			"https://nlp.example.com:443",
+			"https://nlp.example.com:443",
+		},
+		{
+			&client.Options{Scheme: "https", StatusPort: 9001},
+			"https://127.0.0.1:443",
+			"https://127.0.0.1:9001",
+		},
+		{
+			&client.Options{PathPrefix: "a/b"},
+			"http://127.0.0.1:9000/a/b",
+			"http://127.0.0.1:9000/a/b",
+		},
+	}
+	for i, tc := range testCases {
+		t.Run("case "+strconv.Itoa(i), func(t *testing.T) {
+			main, status, err := tc.opt.GetBaseURLs()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := main.String(); s != tc.main {
+				t.Errorf("got main %q; want %q", s, tc.main)
+			}
+			if s := status.String(); s != tc.status {
+				t.Errorf("got status %q; want %q", s, tc.status)
+			}
+		})
+	}
+	_, _, err := (&client.Options{Scheme: "ftp"}).GetBaseURLs()
+	if err == nil {
+		t.Error("got nil error for scheme ftp")
+	}
+}
+
+func TestClient_HTTPS(t *testing.T) {
+	srv := NewFakeServerForTest(t, &corenlptest.Options{
+		TLS:                  true,
+		SeparateStatusServer: true,
+	})
+
+	t.Run("TLSConfig", func(t *testing.T) {
+		CheckFakeServerAnnotation(t, srv.ClientOptions())
+	})
+
+	t.Run("CAFile", func(t *testing.T) {
+		caFile := filepath.Join(t.TempDir(), "ca.pem")
+		b := pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: srv.Certificate().Raw,
+		})
+		if err := os.WriteFile(caFile, b, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		opt := srv.ClientOptions()
+		opt.TLSConfig, opt.CAFile = nil, caFile
+		CheckFakeServerAnnotation(t, opt)
+	})
+
+	t.Run("untrusted", func(t *testing.T) {
+		opt := srv.ClientOptions()
+		opt.TLSConfig = nil
+		if _, err := client.New(opt); err == nil {
+			t.Error("got nil error for an untrusted certificate")
+		}
+	})
+
+	t.Run("plain HTTP", func(t *testing.T) {
+		opt := srv.ClientOptions()
+		opt.Scheme = ""
+		if _, err := client.New(opt); err == nil {
+			t.Error("got nil error for HTTP to an HTTPS server")
+		}
+	})
+}
+
+func TestClient_PathPrefix(t *testing.T) {
+	srv := NewFakeServerForTest(t, nil)
+	target, err := url.Parse(srv.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gateway := httptest.NewServer(http.StripPrefix("/corenlp",
+		httputil.NewSingleHostReverseProxy(target)))
+	defer gateway.Close()
+	opt := srv.ClientOptions()
+	opt.Port = uint16(GatewayPort(t, gateway))
+	opt.PathPrefix = "/corenlp/"
+	CheckFakeServerAnnotation(t, opt)
+	opt.PathPrefix = ""
+	if _, err = client.New(opt); err == nil {
+		t.Error("got nil error without the path prefix")
+	}
+}
+
+func TestClient_Proxy(t *testing.T) {
+	srv := NewFakeServerForTest(t, nil)
+	var numProxied atomic.Int32
+	proxy := httptest.NewServer(&httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			numProxied.Add(1)
+			r.Out.URL = r.In.URL // forward to the absolute URL
+		},
+	})
+	defer proxy.Close()
+
+	t.Run("ProxyURL", func(t *testing.T) {
+		numProxied.Store(0)
+		opt := srv.ClientOptions()
+		opt.ProxyURL = proxy.URL
+		CheckFakeServerAnnotation(t, opt)
+		if n := numProxied.Load(); n != 3 { // live, ready, and annotate
+			t.Errorf("got %d proxied requests; want 3", n)
+		}
+	})
+
+	t.Run("Proxy", func(t *testing.T) {
+		numProxied.Store(0)
+		proxyURL, err := url.Parse(proxy.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var numCalls atomic.Int32
+		opt := srv.ClientOptions()
+		opt.ProxyURL = "http://invalid.invalid:1"
+		opt.Proxy = func(*http.Request) (*url.URL, error) {
+			numCalls.Add(1)
+			return proxyURL, nil
+		}
+		CheckFakeServerAnnotation(t, opt)
+		if n := numProxied.Load(); n != 3 {
+			t.Errorf("got %d proxied requests; want 3", n)
+		}
+		if numCalls.Load() == 0 {
+			t.Error("Proxy was not called")
+		}
+	})
+
+	t.Run("incompatible transport", func(t *testing.T) {
+		opt := srv.ClientOptions()
+		opt.Transport = roundTripperFunc(http.DefaultTransport.RoundTrip)
+		opt.ProxyURL = proxy.URL
+		if _, err := client.New(opt); err == nil {
+			t.Error("got nil error")
+		}
+	})
+}
+
+func TestNew_InvalidTLSFiles(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(empty, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, opt := range []*client.Options{
+		{CAFile: filepath.Join(dir, "nonexistent.pem")},
+		{CAFile: empty},
+		{CertFile: empty},
+		{CertFile: empty, KeyFile: empty},
+	} {
+		if _, err := client.NewClientWithoutCheckingLive(opt); err == nil {
+			t.Errorf("got nil error for CAFile %q, CertFile %q, KeyFile %q",
+				opt.CAFile, opt.CertFile, opt.KeyFile)
+		}
+	}
+}
+
+// CheckFakeServerAnnotation creates a client with the specified options
+// by client.New, checks the readiness of the server,
+// and checks the annotation of Text.
+func CheckFakeServerAnnotation(t *testing.T, opt *client.Options) {
+	t.Helper()
+	c, err := client.New(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = c.Ready(); err != nil {
+		t.Error("ready -", err)
+	}
+	doc := new(pb.Document)
+	if err = c.AnnotateString(Text, "tokenize,ssplit,pos", doc); err != nil {
+		t.Fatal(err)
+	}
+	CheckAnnotation(t, doc)
+}
+
+// GatewayPort returns the port of the specified test server.
+func GatewayPort(tb testing.TB, srv *httptest.Server) int {
+	tb.Helper()
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return port
+}
+
+// roundTripperFunc is an adapter to allow the use of
+// ordinary functions as http.RoundTripper.
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,245 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+
+	"github.com/donyori/gocorenlp/client"
+)
+
+// passwordEnv is the environment variable holding the password
+// for the server, used if the flag -password is not set.
+const passwordEnv = "GOCORENLP_PASSWORD"
+
+// propertiesFlag is a flag.Value collecting the properties
+// specified as key=value, which can be repeated.
+type propertiesFlag map[string]string
+
+func (p propertiesFlag) String() string {
+	pairs := make([]string, 0, len(p))
+	for k, v := range p {
+		pairs = append(pairs, k+"="+v)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (p propertiesFlag) Set(s string) error {
+	k, v, ok := strings.Cut(s, "=")
+	k = strings.TrimSpace(k)
+	if !ok || len(k) == 0 {
+		return gogoerrors.AutoNew(fmt.Sprintf("property %q is not in the form key=value", s))
+	}
+	p[k] = v
+	return nil
+}
+
+// annotateResult is the result of annotating an input.
+type annotateResult struct {
+	raw  []byte        // raw is the serialized response.
+	err  error         // err is the error that occurred, if any.
+	done chan struct{} // done is closed when the annotation finishes.
+}
+
+// runAnnotate runs the annotate command.
+func runAnnotate(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := newFlagSet("annotate", "[flags] [file ...]", stderr)
+	opt := new(client.Options)
+	fs.StringVar(&opt.Scheme, "scheme", "http", "URL scheme of the server, http or https")
+	fs.StringVar(&opt.Hostname, "host", "127.0.0.1", "hostname of the server")
+	port := fs.Uint("port", 0, "port of the server (0 for 443 with https, 9000 otherwise)")
+	statusPort := fs.Uint("status-port", 0, "port of the liveness and readiness server (0 for the same as -port)")
+	fs.StringVar(&opt.PathPrefix, "path-prefix", "", "URL path prefix of the server behind a gateway")
+	fs.DurationVar(&opt.ClientTimeout, "timeout", 0, "time limit of each request (0 for no limit)")
+	fs.StringVar(&opt.Username, "user", "", "username for basic auth")
+	fs.StringVar(&opt.Password, "password", "", "password for basic auth (default $"+passwordEnv+")")
+	fs.StringVar(&opt.ServerID, "server-id", "", "value of the option -server_id of the server")
+	fs.StringVar(&opt.Annotators, "annotators", "", "comma-separated annotators (default the server's)")
+	props := make(propertiesFlag)
+	fs.Var(props, "prop", "property key=value sent with the requests (repeatable)")
+	concurrency := fs.Int("concurrency", 1, "maximum number of inputs annotated at the same time")
+	format := fs.String("format", formatJSON, "output format: raw, json, or table")
+	outDir := fs.String("out", "", "directory to write one result file per input (default the standard output)")
+	modelName := fs.String("model", "", "model version to decode the results (default "+defaultModelVersion+")")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	usageError := func(msg string) int {
+		_, _ = fmt.Fprintln(stderr, "gocorenlp annotate:", msg)
+		fs.Usage()
+		return exitUsage
+	}
+	switch *format {
+	case formatRaw, formatJSON, formatTable:
+	default:
+		return usageError(fmt.Sprintf("unknown format %q", *format))
+	}
+	v, err := lookupModelVersion(*modelName)
+	if err != nil {
+		return usageError(err.Error())
+	}
+	if *port > 0xFFFF || *statusPort > 0xFFFF {
+		return usageError("port out of range")
+	}
+	if *concurrency < 1 {
+		return usageError("concurrency must be positive")
+	}
+	inputs := fs.Args()
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+	numStdin := 0
+	for _, name := range inputs {
+		if name == "-" {
+			numStdin++
+		}
+	}
+	if numStdin > 1 {
+		return usageError("the standard input (-) is specified more than once")
+	}
+	if *format == formatRaw && len(*outDir) == 0 && len(inputs) > 1 {
+		return usageError("raw results of multiple inputs require -out")
+	}
+	if len(*outDir) > 0 {
+		// The result files are named after the base names of the inputs.
+		seen := make(map[string]string, len(inputs))
+		for _, name := range inputs {
+			base := filepath.Base(displayName(name))
+			if prev, ok := seen[base]; ok {
+				return usageError(fmt.Sprintf(
+					"inputs %s and %s have the same base name, so their results would overwrite each other in -out",
+					prev, displayName(name)))
+			}
+			seen[base] = displayName(name)
+		}
+	}
+
+	opt.Port, opt.StatusPort = uint16(*port), uint16(*statusPort)
+	if len(opt.Password) == 0 {
+		opt.Password = os.Getenv(passwordEnv)
+	}
+	opt.Properties = props
+	if len(*outDir) > 0 {
+		if err = os.MkdirAll(*outDir, 0o755); err != nil {
+			_, _ = fmt.Fprintln(stderr, "gocorenlp annotate:", err)
+			return exitError
+		}
+	}
+	c, err := client.New(opt)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, "gocorenlp annotate:", err)
+		return exitError
+	}
+
+	results := make([]*annotateResult, len(inputs))
+	sem := make(chan struct{}, *concurrency)
+	for i, name := range inputs {
+		results[i] = &annotateResult{done: make(chan struct{})}
+		go func(name string, r *annotateResult) {
+			defer close(r.done)
+			sem <- struct{}{}
+			defer func() {
+				<-sem
+			}()
+			r.raw, r.err = annotateInput(c, name, stdin)
+		}(name, results[i])
+	}
+
+	// Write the results in the order of the inputs.
+	code := exitOK
+	for i, r := range results {
+		<-r.done
+		name := displayName(inputs[i])
+		if r.err == nil {
+			if len(*outDir) > 0 {
+				r.err = writeResultFile(
+					filepath.Join(*outDir, filepath.Base(name)+formatExt(*format)),
+					r.raw, *format, v)
+			} else {
+				if *format == formatTable && len(inputs) > 1 {
+					writeHeader(stdout, name, i)
+				}
+				r.err = writeResult(stdout, r.raw, *format, v)
+			}
+		}
+		if r.err != nil {
+			_, _ = fmt.Fprintf(stderr, "gocorenlp annotate: %s: %v\n", name, r.err)
+			code = exitError
+		}
+	}
+	return code
+}
+
+// annotateInput annotates the content of the file with the specified name
+// (or stdin if name is "-") with c and returns the serialized response.
+func annotateInput(c client.Client, name string, stdin io.Reader) ([]byte, error) {
+	input := stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, gogoerrors.AutoWrap(err)
+		}
+		defer func(f *os.File) {
+			_ = f.Close() // ignore error
+		}(f)
+		input = f
+	}
+	var b bytes.Buffer
+	if _, err := c.AnnotateRaw(input, "", &b); err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	return b.Bytes(), nil
+}
+
+// writeResultFile writes the serialized response raw to the file
+// with the specified name in the specified format.
+func writeResultFile(name string, raw []byte, format string, v modelVersion) error {
+	var b bytes.Buffer
+	if err := writeResult(&b, raw, format, v); err != nil {
+		return gogoerrors.AutoWrap(err)
+	}
+	return gogoerrors.AutoWrap(os.WriteFile(name, b.Bytes(), 0o644))
+}
+
+// writeHeader writes a header line naming the input before its table.
+//
+// i is the index of the input.
+func writeHeader(w io.Writer, name string, i int) {
+	if i > 0 {
+		_, _ = fmt.Fprintln(w)
+	}
+	_, _ = fmt.Fprintf(w, "==> %s <==\n", name)
+}
+
+// displayName returns the name of the input shown in messages,
+// "stdin" for "-".
+func displayName(name string) string {
+	if name == "-" {
+		return "stdin"
+	}
+	return name
+}
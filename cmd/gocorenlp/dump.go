@@ -0,0 +1,71 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// runDump runs the dump command.
+func runDump(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := newFlagSet("dump", "[flags] [file ...]", stderr)
+	format := fs.String("format", formatTable, "output format: json or table")
+	modelName := fs.String("model", "", "model version to decode the results (default "+defaultModelVersion+")")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if *format != formatJSON && *format != formatTable {
+		_, _ = fmt.Fprintf(stderr, "gocorenlp dump: unknown format %q\n", *format)
+		fs.Usage()
+		return exitUsage
+	}
+	v, err := lookupModelVersion(*modelName)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, "gocorenlp dump:", err)
+		fs.Usage()
+		return exitUsage
+	}
+	inputs := fs.Args()
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+
+	code := exitOK
+	for i, name := range inputs {
+		var raw []byte
+		if name == "-" {
+			raw, err = io.ReadAll(stdin)
+		} else {
+			raw, err = os.ReadFile(name)
+		}
+		if err == nil {
+			if *format == formatTable && len(inputs) > 1 {
+				writeHeader(stdout, displayName(name), i)
+			}
+			err = writeResult(stdout, raw, *format, v)
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "gocorenlp dump: %s: %v\n", displayName(name), err)
+			code = exitError
+		}
+	}
+	return code
+}
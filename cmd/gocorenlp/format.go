@@ -0,0 +1,206 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/donyori/gocorenlp/model"
+)
+
+// Output formats of the annotation results.
+const (
+	formatRaw   = "raw"   // formatRaw is the serialized response, as returned by the server.
+	formatJSON  = "json"  // formatJSON is the document in the ProtoBuf JSON format.
+	formatTable = "table" // formatTable is a readable table of the tokens.
+)
+
+// formatExt returns the file name extension for the specified format.
+func formatExt(format string) string {
+	switch format {
+	case formatRaw:
+		return ".pb"
+	case formatJSON:
+		return ".json"
+	}
+	return ".txt"
+}
+
+// writeResult writes the serialized response raw to w
+// in the specified format.
+//
+// For the formats other than formatRaw,
+// the response is decoded into a document of the model version v.
+func writeResult(w io.Writer, raw []byte, format string, v modelVersion) error {
+	if format == formatRaw {
+		_, err := w.Write(raw)
+		return gogoerrors.AutoWrap(err)
+	}
+	doc := v.newDocument()
+	if err := model.DecodeResponseBody(raw, doc); err != nil {
+		return gogoerrors.AutoWrap(err)
+	}
+	if format == formatTable {
+		return gogoerrors.AutoWrap(writeTable(w, doc))
+	}
+	b, err := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(doc)
+	if err != nil {
+		return gogoerrors.AutoWrap(err)
+	}
+	_, err = w.Write(append(b, '\n'))
+	return gogoerrors.AutoWrap(err)
+}
+
+// writeTable writes the tokens of doc to w as a table with columns:
+// the sentence index, the token index (1-based, as in dependency graphs),
+// the word, lemma, part-of-speech tag, named entity tag,
+// and the head and relation in the basic dependencies.
+//
+// Missing annotations are written as "-".
+//
+// The fields are read by their names through ProtoBuf reflection,
+// so that it works with the documents of all model versions.
+func writeTable(w io.Writer, doc proto.Message) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, err := fmt.Fprintln(tw, "SENT\tTOKEN\tWORD\tLEMMA\tPOS\tNER\tHEAD\tDEPREL")
+	if err != nil {
+		return gogoerrors.AutoWrap(err)
+	}
+	for i, sentence := range listField(doc.ProtoReflect(), "sentence") {
+		heads := dependencyHeads(sentence)
+		for j, token := range listField(sentence, "token") {
+			head, rel := "-", "-"
+			if h, ok := heads[uint64(j+1)]; ok {
+				head, rel = strconv.FormatUint(h.source, 10), cell(h.dep)
+			}
+			_, err = fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				i, j+1,
+				cell(stringField(token, "word")),
+				cell(stringField(token, "lemma")),
+				cell(stringField(token, "pos")),
+				cell(stringField(token, "ner")),
+				head, rel)
+			if err != nil {
+				return gogoerrors.AutoWrap(err)
+			}
+		}
+	}
+	return gogoerrors.AutoWrap(tw.Flush())
+}
+
+// dependencyHead is the head of a token in a dependency graph.
+type dependencyHead struct {
+	source uint64 // source is the index of the head token (0 for the root).
+	dep    string // dep is the dependency relation.
+}
+
+// dependencyHeads returns the heads of the tokens in
+// the basic dependencies of the specified sentence,
+// indexed by the (1-based) token indexes.
+func dependencyHeads(sentence protoreflect.Message) map[uint64]dependencyHead {
+	g, ok := messageField(sentence, "basicDependencies")
+	if !ok {
+		return nil
+	}
+	heads := make(map[uint64]dependencyHead)
+	for _, edge := range listField(g, "edge") {
+		heads[uintField(edge, "target")] = dependencyHead{
+			source: uintField(edge, "source"),
+			dep:    stringField(edge, "dep"),
+		}
+	}
+	if fd := g.Descriptor().Fields().ByName("root"); fd != nil && fd.IsList() {
+		roots := g.Get(fd).List()
+		for i := 0; i < roots.Len(); i++ {
+			heads[roots.Get(i).Uint()] = dependencyHead{dep: "root"}
+		}
+	}
+	return heads
+}
+
+// listField returns the elements of the repeated message field
+// with the specified name of m.
+//
+// It returns nil if m has no such field.
+func listField(m protoreflect.Message, name string) []protoreflect.Message {
+	fd := m.Descriptor().Fields().ByName(protoreflect.Name(name))
+	if fd == nil || !fd.IsList() || fd.Message() == nil {
+		return nil
+	}
+	list := m.Get(fd).List()
+	result := make([]protoreflect.Message, list.Len())
+	for i := range result {
+		result[i] = list.Get(i).Message()
+	}
+	return result
+}
+
+// messageField returns the message field with the specified name of m.
+//
+// It reports false if m has no such field or the field is not set.
+func messageField(m protoreflect.Message, name string) (protoreflect.Message, bool) {
+	fd := m.Descriptor().Fields().ByName(protoreflect.Name(name))
+	if fd == nil || fd.IsList() || fd.Message() == nil || !m.Has(fd) {
+		return nil, false
+	}
+	return m.Get(fd).Message(), true
+}
+
+// stringField returns the string field with the specified name of m.
+//
+// It returns an empty string if m has no such field.
+func stringField(m protoreflect.Message, name string) string {
+	fd := m.Descriptor().Fields().ByName(protoreflect.Name(name))
+	if fd == nil || fd.IsList() || fd.Kind() != protoreflect.StringKind {
+		return ""
+	}
+	return m.Get(fd).String()
+}
+
+// uintField returns the unsigned integer field with
+// the specified name of m.
+//
+// It returns 0 if m has no such field.
+func uintField(m protoreflect.Message, name string) uint64 {
+	fd := m.Descriptor().Fields().ByName(protoreflect.Name(name))
+	if fd == nil || fd.IsList() ||
+		(fd.Kind() != protoreflect.Uint32Kind && fd.Kind() != protoreflect.Uint64Kind) {
+		return 0
+	}
+	return m.Get(fd).Uint()
+}
+
+// cell returns s as a table cell:
+// "-" if s is empty, and s with white space characters
+// (such as tabs and newlines) replaced by spaces otherwise.
+func cell(s string) string {
+	if len(s) == 0 {
+		return "-"
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
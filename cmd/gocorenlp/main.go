@@ -0,0 +1,195 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Command gocorenlp annotates texts with a Stanford CoreNLP server
+// and inspects the annotation results from the command line.
+//
+// Usage:
+//
+//	gocorenlp <command> [flags] [arguments]
+//
+// The commands are:
+//
+//	annotate  annotate files (or the standard input) with a CoreNLP server
+//	dump      print serialized annotation results as JSON or a table
+//	versions  list the available model versions
+//	help      print the help of a command
+//
+// The annotate command sends each file specified by the arguments
+// (or the standard input if there is none, or for "-")
+// to the server and prints the annotation results in the order of
+// the arguments. For example:
+//
+//	gocorenlp annotate -annotators tokenize,ssplit,pos,lemma,ner \
+//	    -prop ner.applyFineGrained=false -concurrency 4 -format table \
+//	    a.txt b.txt
+//
+// With "-format raw -out DIR", it saves the serialized results,
+// as returned by the method AnnotateRaw of the package client,
+// to the directory DIR (one file named <input base name>.pb per input,
+// so the inputs must have distinct base names).
+// The dump command prints such files later:
+//
+//	gocorenlp dump -format json -model 4.5.6 DIR/a.txt.pb
+//
+// The flag -model selects the model version used to decode the results,
+// by the CoreNLP version (such as "4.5.6") or the name of
+// the version directory (such as "v4.5.6-eb50467fa8e3").
+// It defaults to the newest version.
+//
+// Run "gocorenlp help <command>" for the flags of each command.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// Exit codes of the command.
+const (
+	exitOK    = 0 // exitOK indicates success.
+	exitError = 1 // exitError indicates failures of annotation or dumping.
+	exitUsage = 2 // exitUsage indicates invalid command-line arguments.
+)
+
+// command is a subcommand of gocorenlp.
+type command struct {
+	name    string // name is the name of the command.
+	summary string // summary is a one-line description of the command.
+
+	// run runs the command with the specified arguments
+	// (excluding the command name) and returns the exit code.
+	run func(args []string, stdin io.Reader, stdout, stderr io.Writer) int
+}
+
+// commands returns the subcommands of gocorenlp.
+//
+// It is a function rather than a variable to avoid
+// an initialization cycle with runHelp.
+func commands() []command {
+	return []command{
+		{"annotate", "annotate files (or the standard input) with a CoreNLP server", runAnnotate},
+		{"dump", "print serialized annotation results as JSON or a table", runDump},
+		{"versions", "list the available model versions", runVersions},
+		{"help", "print the help of a command", runHelp},
+	}
+}
+
+// run runs gocorenlp with the specified arguments
+// (excluding the program name) and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return exitUsage
+	}
+	for _, cmd := range commands() {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdin, stdout, stderr)
+		}
+	}
+	if args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		printUsage(stdout)
+		return exitOK
+	}
+	_, _ = fmt.Fprintf(stderr, "gocorenlp: unknown command %q\n", args[0])
+	printUsage(stderr)
+	return exitUsage
+}
+
+// printUsage prints the usage of gocorenlp to w.
+func printUsage(w io.Writer) {
+	_, _ = fmt.Fprint(w, "Usage:\n\n\tgocorenlp <command> [flags] [arguments]\n\nThe commands are:\n\n")
+	for _, cmd := range commands() {
+		_, _ = fmt.Fprintf(w, "\t%-9s %s\n", cmd.name, cmd.summary)
+	}
+	_, _ = fmt.Fprint(w, "\nRun \"gocorenlp help <command>\" for the flags of each command.\n")
+}
+
+// runHelp runs the help command.
+func runHelp(args []string, _ io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stdout)
+		return exitOK
+	}
+	if len(args) == 1 && args[0] != "help" {
+		for _, cmd := range commands() {
+			if cmd.name == args[0] {
+				return cmd.run([]string{"-h"}, nil, stdout, stdout)
+			}
+		}
+	}
+	_, _ = fmt.Fprintf(stderr, "gocorenlp help: unknown command %q\n", args[0])
+	return exitUsage
+}
+
+// runVersions runs the versions command.
+func runVersions(args []string, _ io.Reader, stdout, stderr io.Writer) int {
+	fs := newFlagSet("versions", "", stderr)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	for _, v := range modelVersions {
+		suffix := ""
+		if v.name == defaultModelVersion {
+			suffix = " (default)"
+		}
+		_, _ = fmt.Fprintln(stdout, v.name+suffix)
+	}
+	return exitOK
+}
+
+// newFlagSet creates a flag set for the specified command,
+// printing the errors and usage to stderr.
+//
+// usage is the synopsis of the arguments of the command.
+func newFlagSet(name, usage string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("gocorenlp "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(stderr, strings.TrimSpace("usage: gocorenlp "+name+" "+usage))
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses args with fs.
+//
+// It reports false if the command should exit with the returned code,
+// that is, the help is requested or the arguments are invalid.
+func parseFlags(fs *flag.FlagSet, args []string) (code int, ok bool) {
+	err := fs.Parse(args)
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK, false
+	case err != nil:
+		return exitUsage, false
+	}
+	return exitOK, true
+}
@@ -0,0 +1,234 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/client/corenlptest"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+const (
+	text1      = "Hello world."
+	text2      = "Go fast!"
+	annotators = "tokenize,ssplit,pos"
+)
+
+func TestRun_Versions(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"versions"}, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("got exit code %d; stderr: %s", code, stderr.String())
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != len(modelVersions) {
+		t.Errorf("got %d lines; want %d", len(lines), len(modelVersions))
+	}
+	if want := defaultModelVersion + " (default)"; lines[len(lines)-1] != want {
+		t.Errorf("got last line %q; want %q", lines[len(lines)-1], want)
+	}
+}
+
+func TestLookupModelVersion(t *testing.T) {
+	for _, name := range []string{"", "4.5.6", "v4.5.6", "v4.5.6-eb50467fa8e3"} {
+		v, err := lookupModelVersion(name)
+		if err != nil {
+			t.Errorf("%q - %v", name, err)
+		} else if v.name != "v4.5.6-eb50467fa8e3" {
+			t.Errorf("%q - got %s", name, v.name)
+		}
+	}
+	v, err := lookupModelVersion("3.6.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.name != "v3.6.0-29765338a2e8" {
+		t.Errorf("got %s; want v3.6.0-29765338a2e8", v.name)
+	}
+	if _, err = lookupModelVersion("4.5"); err == nil {
+		t.Error("got nil error for 4.5")
+	}
+}
+
+func TestRun_AnnotateAndDump(t *testing.T) {
+	hostFlags := newFakeServer(t)
+	dir := t.TempDir()
+	file1 := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(file1, []byte(text1), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("table", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		args := append([]string{"annotate", "-annotators", annotators,
+			"-prop", "tokenize.language=en", "-concurrency", "2",
+			"-format", "table"}, hostFlags...)
+		args = append(args, file1, "-")
+		code := run(args, strings.NewReader(text2), &stdout, &stderr)
+		if code != exitOK {
+			t.Fatalf("got exit code %d; stderr: %s", code, stderr.String())
+		}
+		want := "==> " + file1 + " <==\n" +
+			"SENT  TOKEN  WORD   LEMMA  POS  NER  HEAD  DEPREL\n" +
+			"0     1      Hello  -      UH   -    2     discourse\n" +
+			"0     2      world  -      NN   -    0     root\n" +
+			"0     3      .      -      .    -    2     punct\n" +
+			"\n==> stdin <==\n" +
+			"SENT  TOKEN  WORD  LEMMA  POS  NER  HEAD  DEPREL\n" +
+			"0     1      Go    -      VB   -    -     -\n" +
+			"0     2      fast  -      RB   -    -     -\n" +
+			"0     3      !     -      .    -    -     -\n"
+		if stdout.String() != want {
+			t.Errorf("got\n%s\nwant\n%s", stdout.String(), want)
+		}
+	})
+
+	t.Run("raw and dump", func(t *testing.T) {
+		out := filepath.Join(dir, "out")
+		var stdout, stderr bytes.Buffer
+		args := append([]string{"annotate", "-annotators", annotators,
+			"-format", "raw", "-out", out}, hostFlags...)
+		args = append(args, file1)
+		if code := run(args, nil, &stdout, &stderr); code != exitOK {
+			t.Fatalf("annotate - got exit code %d; stderr: %s", code, stderr.String())
+		}
+		stdout.Reset()
+		args = []string{"dump", "-format", "json", "-model", "4.5.6",
+			filepath.Join(out, "a.txt.pb")}
+		if code := run(args, nil, &stdout, &stderr); code != exitOK {
+			t.Fatalf("dump - got exit code %d; stderr: %s", code, stderr.String())
+		}
+		// Compare the fields rather than the output,
+		// as protojson does not guarantee stable formatting.
+		got := new(pb.Document)
+		if err := protojson.Unmarshal(stdout.Bytes(), got); err != nil {
+			t.Fatalf("unmarshal - %v\n%s", err, stdout.String())
+		}
+		if got.GetText() != text1 {
+			t.Errorf("got text %q; want %q", got.GetText(), text1)
+		}
+		if len(got.GetSentence()) != 1 || len(got.GetSentence()[0].GetToken()) != 3 {
+			t.Fatalf("got %v; want 1 sentence with 3 tokens", got)
+		}
+		token := got.GetSentence()[0].GetToken()[0]
+		if token.GetWord() != "Hello" || token.GetPos() != "UH" {
+			t.Errorf("got first token %q/%q; want %q/%q",
+				token.GetWord(), token.GetPos(), "Hello", "UH")
+		}
+	})
+
+	t.Run("unknown text", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		args := append([]string{"annotate", "-annotators", annotators}, hostFlags...)
+		code := run(args, strings.NewReader("Unknown."), &stdout, &stderr)
+		if code != exitError {
+			t.Errorf("got exit code %d; want %d", code, exitError)
+		}
+		if !strings.Contains(stderr.String(), "stdin") {
+			t.Errorf("got stderr %q; want it to mention stdin", stderr.String())
+		}
+	})
+}
+
+func TestRun_Usage(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"foo"},
+		{"annotate", "-format", "xml"},
+		{"annotate", "-model", "1.0.0"},
+		{"annotate", "-concurrency", "0"},
+		{"annotate", "-", "-"},
+		{"annotate", "-format", "raw", "a.txt", "b.txt"},
+		{"annotate", "-out", "results", "a/x.txt", "b/x.txt"},
+		{"dump", "-format", "raw"},
+		{"versions", "x"},
+		{"help", "foo"},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := run(args, nil, &stdout, &stderr); code != exitUsage {
+			t.Errorf("%q - got exit code %d; want %d", args, code, exitUsage)
+		}
+	}
+	for _, args := range [][]string{{"help"}, {"help", "dump"}, {"annotate", "-h"}} {
+		var stdout, stderr bytes.Buffer
+		if code := run(args, nil, &stdout, &stderr); code != exitOK {
+			t.Errorf("%q - got exit code %d; want %d", args, code, exitOK)
+		}
+		if stdout.Len() == 0 && stderr.Len() == 0 {
+			t.Errorf("%q - no help printed", args)
+		}
+	}
+}
+
+// newFakeServer starts a fake server with the annotations of
+// text1 and text2, registers its closing to tb.Cleanup,
+// and returns the flags for the annotate command to connect to it.
+func newFakeServer(tb testing.TB) []string {
+	tb.Helper()
+	srv, err := corenlptest.NewServer(nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(srv.Close)
+	doc1 := newDocument(text1, []string{"Hello", "world", "."}, []string{"UH", "NN", "."})
+	doc1.Sentence[0].BasicDependencies = &pb.DependencyGraph{
+		Node: []*pb.DependencyGraph_Node{
+			{SentenceIndex: proto.Uint32(0), Index: proto.Uint32(1)},
+			{SentenceIndex: proto.Uint32(0), Index: proto.Uint32(2)},
+			{SentenceIndex: proto.Uint32(0), Index: proto.Uint32(3)},
+		},
+		Edge: []*pb.DependencyGraph_Edge{
+			{Source: proto.Uint32(2), Target: proto.Uint32(1), Dep: proto.String("discourse")},
+			{Source: proto.Uint32(2), Target: proto.Uint32(3), Dep: proto.String("punct")},
+		},
+		Root: []uint32{2},
+	}
+	doc2 := newDocument(text2, []string{"Go", "fast", "!"}, []string{"VB", "RB", "."})
+	for _, doc := range []*pb.Document{doc1, doc2} {
+		if err = srv.AddDocument(doc.GetText(), annotators, doc); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	opt := srv.ClientOptions()
+	return []string{"-host", opt.Hostname, "-port", strconv.Itoa(int(opt.Port))}
+}
+
+// newDocument creates a document of one sentence
+// with the specified text, words, and part-of-speech tags.
+func newDocument(text string, words, tags []string) *pb.Document {
+	sentence := &pb.Sentence{
+		TokenOffsetBegin: proto.Uint32(0),
+		TokenOffsetEnd:   proto.Uint32(uint32(len(words))),
+	}
+	for i, w := range words {
+		sentence.Token = append(sentence.Token, &pb.Token{
+			Word: proto.String(w),
+			Pos:  proto.String(tags[i]),
+		})
+	}
+	return &pb.Document{Text: proto.String(text), Sentence: []*pb.Sentence{sentence}}
+}
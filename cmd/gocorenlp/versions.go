@@ -0,0 +1,93 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+	"google.golang.org/protobuf/proto"
+
+	pb360 "github.com/donyori/gocorenlp/model/v3.6.0-29765338a2e8/pb"
+	pb400 "github.com/donyori/gocorenlp/model/v4.0.0-2b3dd38abe00/pb"
+	pb410 "github.com/donyori/gocorenlp/model/v4.1.0-a1427196ba6e/pb"
+	pb420 "github.com/donyori/gocorenlp/model/v4.2.0-3ad83fc2e42e/pb"
+	pb421 "github.com/donyori/gocorenlp/model/v4.2.1-d8d09b2c81a5/pb"
+	pb430 "github.com/donyori/gocorenlp/model/v4.3.0-f885cd198767/pb"
+	pb440 "github.com/donyori/gocorenlp/model/v4.4.0-e90f30f13c40/pb"
+	pb450 "github.com/donyori/gocorenlp/model/v4.5.0-45b47e245c36/pb"
+	pb452 "github.com/donyori/gocorenlp/model/v4.5.2-9c3dfee5af50/pb"
+	pb453 "github.com/donyori/gocorenlp/model/v4.5.3-5250f9faf9f1/pb"
+	pb455 "github.com/donyori/gocorenlp/model/v4.5.5-f1b929e47a57/pb"
+	pb456 "github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+// modelVersion is a version of the document model,
+// corresponding to a subpackage of github.com/donyori/gocorenlp/model.
+type modelVersion struct {
+	// name is the name of the version directory,
+	// such as "v4.5.6-eb50467fa8e3".
+	name string
+
+	// newDocument returns a new empty document of this version.
+	newDocument func() proto.Message
+}
+
+// modelVersions are the available model versions, from oldest to newest.
+var modelVersions = []modelVersion{
+	{"v3.6.0-29765338a2e8", func() proto.Message { return new(pb360.Document) }},
+	{"v4.0.0-2b3dd38abe00", func() proto.Message { return new(pb400.Document) }},
+	{"v4.1.0-a1427196ba6e", func() proto.Message { return new(pb410.Document) }},
+	{"v4.2.0-3ad83fc2e42e", func() proto.Message { return new(pb420.Document) }},
+	{"v4.2.1-d8d09b2c81a5", func() proto.Message { return new(pb421.Document) }},
+	{"v4.3.0-f885cd198767", func() proto.Message { return new(pb430.Document) }},
+	{"v4.4.0-e90f30f13c40", func() proto.Message { return new(pb440.Document) }},
+	{"v4.5.0-45b47e245c36", func() proto.Message { return new(pb450.Document) }},
+	{"v4.5.2-9c3dfee5af50", func() proto.Message { return new(pb452.Document) }},
+	{"v4.5.3-5250f9faf9f1", func() proto.Message { return new(pb453.Document) }},
+	{"v4.5.5-f1b929e47a57", func() proto.Message { return new(pb455.Document) }},
+	{"v4.5.6-eb50467fa8e3", func() proto.Message { return new(pb456.Document) }},
+}
+
+// defaultModelVersion is the name of the model version
+// used when no version is specified (the newest one).
+var defaultModelVersion = modelVersions[len(modelVersions)-1].name
+
+// lookupModelVersion returns the model version with the specified name.
+//
+// The name can be the full name of the version directory
+// (such as "v4.5.6-eb50467fa8e3") or the CoreNLP version
+// (such as "4.5.6" and "v4.5.6").
+// If name is empty, the newest version is returned.
+func lookupModelVersion(name string) (modelVersion, error) {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "v")
+	if len(name) == 0 {
+		return modelVersions[len(modelVersions)-1], nil
+	}
+	for _, v := range modelVersions {
+		full := strings.TrimPrefix(v.name, "v")
+		if name == full || name == full[:strings.IndexByte(full, '-')] {
+			return v, nil
+		}
+	}
+	return modelVersion{}, gogoerrors.AutoNew(fmt.Sprintf(
+		"unknown model version %q; run \"gocorenlp versions\" for the available ones",
+		name))
+}
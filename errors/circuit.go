@@ -0,0 +1,71 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package errors
+
+import (
+	stderrors "errors"
+	"strconv"
+	"time"
+)
+
+// IsCircuitOpenError reports whether the specified error is caused by
+// a request rejected by an open circuit breaker of the client.
+func IsCircuitOpenError(err error) bool {
+	var e *CircuitOpenError
+	return stderrors.As(err, &e)
+}
+
+// CircuitOpenError records that a request is rejected without being sent
+// because the circuit breaker of the client is open,
+// after consecutive failures to reach the server.
+type CircuitOpenError struct {
+	// Failures is the number of consecutive failures
+	// that opened the circuit breaker.
+	Failures int
+
+	// LastError is the error of the last failure,
+	// either of a request or of the readiness probe.
+	//
+	// It is not wrapped by CircuitOpenError,
+	// so the predicates of this package, except IsCircuitOpenError,
+	// report false for *CircuitOpenError.
+	LastError error
+
+	// Until is the time after which the circuit breaker
+	// probes the server for readiness.
+	// It is zero if a probe is in progress.
+	Until time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	if e == nil {
+		return ""
+	}
+	msg := "circuit breaker is open after " + strconv.Itoa(e.Failures) +
+		" consecutive failures"
+	if !e.Until.IsZero() {
+		msg += " until " + e.Until.Format(time.RFC3339)
+	} else {
+		msg += "; probing the server"
+	}
+	if e.LastError != nil {
+		msg += "; last error: " + e.LastError.Error()
+	}
+	return msg
+}
@@ -0,0 +1,60 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package errors_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/donyori/gocorenlp/errors"
+)
+
+func TestIsCircuitOpenError(t *testing.T) {
+	lastErr := &errors.UnacceptableResponseError{StatusCode: 503}
+	openErr := &errors.CircuitOpenError{Failures: 3, LastError: lastErr}
+	IsErrorFunc(t, errors.IsCircuitOpenError, []IsErrorTestCase{
+		{nil, false},
+		{lastErr, false},
+		{openErr, true},
+		{WrapError(openErr), true},
+	})
+	if errors.IsUnacceptableResponseError(openErr) {
+		t.Error("CircuitOpenError wraps its last error")
+	}
+}
+
+func TestCircuitOpenError_Error(t *testing.T) {
+	until := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := &errors.CircuitOpenError{
+		Failures:  3,
+		LastError: errors.New("connection refused"),
+		Until:     until,
+	}
+	got := err.Error()
+	for _, want := range []string{
+		"after 3 consecutive failures",
+		"until 2024-01-02T03:04:05Z",
+		"last error: connection refused",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("got %q; want it to contain %q", got, want)
+		}
+	}
+}
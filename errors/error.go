@@ -25,7 +25,8 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
-	"unicode"
+
+	"github.com/donyori/gocorenlp/internal/strutil"
 )
 
 // IsTimeoutError reports whether the specified error is caused by a timeout.
@@ -80,7 +81,7 @@ func (e *UnacceptableResponseError) Error() string {
 	if e == nil {
 		return ""
 	}
-	body, wantBody := shortenN(e.Body, 40), shortenN(e.WantBody, 40)
+	body, wantBody := strutil.Shorten(e.Body, 40), strutil.Shorten(e.WantBody, 40)
 	var b strings.Builder
 	switch {
 	case e.StatusCode != 0, len(e.Status) > 0:
@@ -209,24 +210,3 @@ func (e *ProtoBufError) Unwrap() error {
 	}
 	return e.Err
 }
-
-// shortenN cuts the specified string if it is too long;
-// otherwise, shortenN returns s itself.
-//
-// It preserves at least the first n bytes.
-// The returned string is at most (n + 10) bytes.
-//
-// If the returned string is different from s,
-// it must have the suffix "...".
-func shortenN(s string, n int) string {
-	if len(s) <= n+10 {
-		return s
-	}
-	// Try to preserve a complete word.
-	end := n + 7 // 7 = 10 (at most 10 more bytes) - 3 ("...")
-	idx := strings.LastIndexFunc(s[n:end], unicode.IsSpace)
-	if idx < 0 {
-		return s[:end] + "..."
-	}
-	return s[:n+idx] + "..."
-}
@@ -0,0 +1,267 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package errors
+
+import (
+	stderrors "errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/donyori/gocorenlp/internal/strutil"
+)
+
+// IsServerTimeoutError reports whether the specified error is caused by
+// the Stanford CoreNLP server timing out the request.
+//
+// Such an error is also reported as a timeout error by IsTimeoutError.
+func IsServerTimeoutError(err error) bool {
+	var e *ServerTimeoutError
+	return stderrors.As(err, &e)
+}
+
+// IsOutOfMemoryError reports whether the specified error is caused by
+// the Stanford CoreNLP server running out of memory.
+func IsOutOfMemoryError(err error) bool {
+	var e *OutOfMemoryError
+	return stderrors.As(err, &e)
+}
+
+// IsUnknownAnnotatorError reports whether the specified error is caused by
+// requesting an annotator unknown to the Stanford CoreNLP server.
+func IsUnknownAnnotatorError(err error) bool {
+	var e *UnknownAnnotatorError
+	return stderrors.As(err, &e)
+}
+
+// IsMissingAnnotatorDependencyError reports whether the specified error
+// is caused by requesting an annotator without its prerequisites.
+func IsMissingAnnotatorDependencyError(err error) bool {
+	var e *MissingAnnotatorDependencyError
+	return stderrors.As(err, &e)
+}
+
+// IsBadPropertiesError reports whether the specified error is caused by
+// invalid properties of the request.
+func IsBadPropertiesError(err error) bool {
+	var e *BadPropertiesError
+	return stderrors.As(err, &e)
+}
+
+// ServerTimeoutError records that the Stanford CoreNLP server
+// timed out the request, usually because the document is too long.
+type ServerTimeoutError struct {
+	// Response is the unacceptable response reporting the error.
+	Response *UnacceptableResponseError
+}
+
+func (e *ServerTimeoutError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return "CoreNLP server request timed out"
+}
+
+// Unwrap returns the unacceptable response reporting the error.
+func (e *ServerTimeoutError) Unwrap() error {
+	if e == nil || e.Response == nil {
+		return nil
+	}
+	return e.Response
+}
+
+// Timeout returns true.
+//
+// It makes IsTimeoutError report true for *ServerTimeoutError.
+func (e *ServerTimeoutError) Timeout() bool {
+	return e != nil
+}
+
+// OutOfMemoryError records that the Stanford CoreNLP server
+// ran out of memory (java.lang.OutOfMemoryError).
+type OutOfMemoryError struct {
+	// Detail is the detail message of the Java error,
+	// such as "Java heap space" and "GC overhead limit exceeded".
+	Detail string
+
+	// Response is the unacceptable response reporting the error.
+	Response *UnacceptableResponseError
+}
+
+func (e *OutOfMemoryError) Error() string {
+	if e == nil {
+		return ""
+	}
+	if len(e.Detail) > 0 {
+		return "CoreNLP server ran out of memory: " + e.Detail
+	}
+	return "CoreNLP server ran out of memory"
+}
+
+// Unwrap returns the unacceptable response reporting the error.
+func (e *OutOfMemoryError) Unwrap() error {
+	if e == nil || e.Response == nil {
+		return nil
+	}
+	return e.Response
+}
+
+// UnknownAnnotatorError records that the request specifies an annotator
+// unknown to the Stanford CoreNLP server or the client-side annotator registry.
+type UnknownAnnotatorError struct {
+	Annotator string // Annotator is the name of the unknown annotator.
+
+	// Response is the unacceptable response reporting the error,
+	// or nil if the error is detected on the client side.
+	Response *UnacceptableResponseError
+}
+
+func (e *UnknownAnnotatorError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return "unknown CoreNLP annotator " + strconv.Quote(e.Annotator)
+}
+
+// Unwrap returns the unacceptable response reporting the error.
+func (e *UnknownAnnotatorError) Unwrap() error {
+	if e == nil || e.Response == nil {
+		return nil
+	}
+	return e.Response
+}
+
+// MissingAnnotatorDependencyError records that the request specifies
+// an annotator without an annotator or annotation it requires.
+type MissingAnnotatorDependencyError struct {
+	Annotator string // Annotator is the name of the annotator that has the requirement.
+
+	// Requirement is the name of the missing annotator or annotation,
+	// as reported by the server, such as "lemma" and "LemmaAnnotation".
+	Requirement string
+
+	// Response is the unacceptable response reporting the error,
+	// or nil if the error is detected on the client side.
+	Response *UnacceptableResponseError
+}
+
+func (e *MissingAnnotatorDependencyError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return "CoreNLP annotator " + strconv.Quote(e.Annotator) +
+		" requires " + strconv.Quote(e.Requirement)
+}
+
+// Unwrap returns the unacceptable response reporting the error.
+func (e *MissingAnnotatorDependencyError) Unwrap() error {
+	if e == nil || e.Response == nil {
+		return nil
+	}
+	return e.Response
+}
+
+// BadPropertiesError records that the Stanford CoreNLP server
+// rejected the properties of the request.
+type BadPropertiesError struct {
+	Message string // Message is the error message reported by the server.
+
+	// Response is the unacceptable response reporting the error.
+	Response *UnacceptableResponseError
+}
+
+func (e *BadPropertiesError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return "CoreNLP server rejected the properties: " + strutil.Shorten(e.Message, 80)
+}
+
+// Unwrap returns the unacceptable response reporting the error.
+func (e *BadPropertiesError) Unwrap() error {
+	if e == nil || e.Response == nil {
+		return nil
+	}
+	return e.Response
+}
+
+var (
+	// oomRegexp matches the Java out-of-memory error with its detail message.
+	oomRegexp = regexp.MustCompile(`java\.lang\.OutOfMemoryError(?::[ \t]*([^\r\n]*))?`)
+
+	// unknownAnnotatorRegexp matches the message of the unknown annotator.
+	unknownAnnotatorRegexp = regexp.MustCompile(`No annotator named[ \t]+"?([^\s"]+)"?`)
+
+	// missingDependencyRegexp matches the message of
+	// the missing annotator dependency.
+	missingDependencyRegexp = regexp.MustCompile(
+		`annotator "([^"]+)" requires (?:annotator|annotation) "([^"]+)"`)
+
+	// badPropertiesRegexp matches the messages of invalid properties.
+	badPropertiesRegexp = regexp.MustCompile(
+		`(?i)[^\r\n]*(?:could not (?:parse|read) properties|` +
+			`invalid propert(?:y|ies)|unknown property|` +
+			`unrecognized property)[^\r\n]*`)
+)
+
+// ParseServerError parses the body of the specified unacceptable response
+// reported by the Stanford CoreNLP server into a typed error
+// if the body describes a known failure of the server.
+//
+// The known failures and their typed errors are as follows:
+//   - The request timed out: *ServerTimeoutError.
+//   - The server ran out of memory: *OutOfMemoryError.
+//   - An annotator is unknown to the server: *UnknownAnnotatorError.
+//   - An annotator lacks its prerequisites: *MissingAnnotatorDependencyError.
+//   - The properties are invalid: *BadPropertiesError.
+//
+// The typed errors wrap e, so IsUnacceptableResponseError
+// still reports true for them.
+//
+// If e is nil, ParseServerError returns nil.
+// If e has no body or its body describes no known failure,
+// ParseServerError returns e itself.
+func ParseServerError(e *UnacceptableResponseError) error {
+	if e == nil {
+		return nil
+	}
+	body := e.Body
+	if strings.Contains(body, "CoreNLP request timed out") {
+		return &ServerTimeoutError{Response: e}
+	} else if m := oomRegexp.FindStringSubmatch(body); m != nil {
+		return &OutOfMemoryError{
+			Detail:   strings.TrimSpace(m[1]),
+			Response: e,
+		}
+	} else if m = unknownAnnotatorRegexp.FindStringSubmatch(body); m != nil {
+		return &UnknownAnnotatorError{Annotator: m[1], Response: e}
+	} else if m = missingDependencyRegexp.FindStringSubmatch(body); m != nil {
+		return &MissingAnnotatorDependencyError{
+			Annotator:   m[1],
+			Requirement: m[2],
+			Response:    e,
+		}
+	} else if msg := badPropertiesRegexp.FindString(body); len(msg) > 0 {
+		return &BadPropertiesError{
+			Message:  strings.TrimSpace(msg),
+			Response: e,
+		}
+	}
+	return e
+}
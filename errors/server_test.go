@@ -0,0 +1,144 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package errors_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/donyori/gocorenlp/errors"
+)
+
+func TestParseServerError(t *testing.T) {
+	const stackTrace = "\n\tat edu.stanford.nlp.pipeline.StanfordCoreNLP.construct(StanfordCoreNLP.java:1)\n\tat java.base/java.lang.Thread.run(Thread.java:2)\n"
+	testCases := []struct {
+		name  string
+		body  string
+		check func(t *testing.T, err error)
+	}{
+		{
+			"timeout",
+			"CoreNLP request timed out. Your document may be too long.",
+			func(t *testing.T, err error) {
+				if !errors.IsServerTimeoutError(err) {
+					t.Error("not a server timeout error")
+				}
+				if !errors.IsTimeoutError(err) {
+					t.Error("not a timeout error")
+				}
+			},
+		},
+		{
+			"out of memory",
+			"java.util.concurrent.ExecutionException: java.lang.OutOfMemoryError: Java heap space" + stackTrace,
+			func(t *testing.T, err error) {
+				var e *errors.OutOfMemoryError
+				if !errors.As(err, &e) {
+					t.Fatal("not an out-of-memory error")
+				}
+				if e.Detail != "Java heap space" {
+					t.Errorf("got detail %q; want %q", e.Detail, "Java heap space")
+				}
+			},
+		},
+		{
+			"unknown annotator",
+			"java.lang.IllegalArgumentException: No annotator named foo" + stackTrace,
+			func(t *testing.T, err error) {
+				var e *errors.UnknownAnnotatorError
+				if !errors.As(err, &e) {
+					t.Fatal("not an unknown annotator error")
+				}
+				if e.Annotator != "foo" {
+					t.Errorf("got annotator %q; want %q", e.Annotator, "foo")
+				}
+			},
+		},
+		{
+			"missing dependency",
+			`java.lang.IllegalArgumentException: annotator "ner" requires annotation "LemmaAnnotation". The usual requirements for this annotator are: tokenize,ssplit,pos,lemma` + stackTrace,
+			func(t *testing.T, err error) {
+				var e *errors.MissingAnnotatorDependencyError
+				if !errors.As(err, &e) {
+					t.Fatal("not a missing annotator dependency error")
+				}
+				if e.Annotator != "ner" || e.Requirement != "LemmaAnnotation" {
+					t.Errorf("got annotator %q, requirement %q; want %q, %q",
+						e.Annotator, e.Requirement, "ner", "LemmaAnnotation")
+				}
+			},
+		},
+		{
+			"bad properties",
+			"java.lang.IllegalArgumentException: Could not parse properties: {annotators: " + stackTrace,
+			func(t *testing.T, err error) {
+				var e *errors.BadPropertiesError
+				if !errors.As(err, &e) {
+					t.Fatal("not a bad properties error")
+				}
+				want := "java.lang.IllegalArgumentException: Could not parse properties: {annotators:"
+				if e.Message != want {
+					t.Errorf("got message %q; want %q", e.Message, want)
+				}
+			},
+		},
+		{
+			"unknown failure",
+			"java.lang.NullPointerException" + stackTrace,
+			func(t *testing.T, err error) {
+				for _, f := range []func(err error) bool{
+					errors.IsServerTimeoutError,
+					errors.IsOutOfMemoryError,
+					errors.IsUnknownAnnotatorError,
+					errors.IsMissingAnnotatorDependencyError,
+					errors.IsBadPropertiesError,
+				} {
+					if f(err) {
+						t.Error("got a typed error for an unknown failure:", err)
+					}
+				}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			respErr := &errors.UnacceptableResponseError{
+				StatusCode: http.StatusInternalServerError,
+				Status:     "500 Internal Server Error",
+				Body:       tc.body,
+			}
+			err := errors.ParseServerError(respErr)
+			if err == nil {
+				t.Fatal("got nil error")
+			}
+			var e *errors.UnacceptableResponseError
+			if !errors.As(err, &e) || e != respErr {
+				t.Error("the result does not wrap the response error")
+			}
+			tc.check(t, WrapError(err))
+		})
+	}
+}
+
+func TestParseServerError_Nil(t *testing.T) {
+	if err := errors.ParseServerError(nil); err != nil {
+		t.Errorf("got %v; want nil", err)
+	}
+}
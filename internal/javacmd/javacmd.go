@@ -0,0 +1,113 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package javacmd builds commands to run Java classes of Stanford CoreNLP.
+//
+// It is shared by the packages processor and server
+// so that they locate Java and CoreNLP in the same way.
+package javacmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Config describes how to start a Java virtual machine
+// to run a class of Stanford CoreNLP.
+type Config struct {
+	// Java is the path to the Java executable.
+	//
+	// If empty, it is "bin/java" under the environment variable JAVA_HOME
+	// if JAVA_HOME is set, or "java" (looked up in PATH) otherwise.
+	Java string
+
+	// Classpath is the Java class path containing Stanford CoreNLP
+	// and its models.
+	//
+	// If empty, it is "*" under the environment variable CORENLP_HOME
+	// if CORENLP_HOME is set, or the value of the environment variable
+	// CLASSPATH otherwise (may also be empty).
+	Classpath string
+
+	// Memory is the maximum heap size of the Java virtual machine,
+	// passed as the option -Xmx, such as "4g".
+	//
+	// If empty, the option -Xmx is not set.
+	Memory string
+
+	// Options are additional options for the Java virtual machine,
+	// inserted before the class name.
+	Options []string
+}
+
+// JavaPath returns the path to the Java executable.
+func (c *Config) JavaPath() string {
+	if c != nil {
+		if java := strings.TrimSpace(c.Java); len(java) > 0 {
+			return java
+		}
+	}
+	if home := strings.TrimSpace(os.Getenv("JAVA_HOME")); len(home) > 0 {
+		return filepath.Join(home, "bin", "java")
+	}
+	return "java"
+}
+
+// ClasspathValue returns the Java class path.
+//
+// It returns an empty string if neither the class path in the config
+// nor the environment variables CORENLP_HOME and CLASSPATH are set.
+func (c *Config) ClasspathValue() string {
+	if c != nil {
+		if cp := strings.TrimSpace(c.Classpath); len(cp) > 0 {
+			return cp
+		}
+	}
+	if home := strings.TrimSpace(os.Getenv("CORENLP_HOME")); len(home) > 0 {
+		return filepath.Join(home, "*")
+	}
+	return strings.TrimSpace(os.Getenv("CLASSPATH"))
+}
+
+// Args returns the arguments to the Java executable
+// for running the specified class with the specified class arguments.
+func (c *Config) Args(class string, args ...string) []string {
+	var memory string
+	var options []string
+	if c != nil {
+		memory, options = strings.TrimSpace(c.Memory), c.Options
+	}
+	r := make([]string, 0, len(options)+len(args)+4)
+	if cp := c.ClasspathValue(); len(cp) > 0 {
+		r = append(r, "-cp", cp)
+	}
+	if len(memory) > 0 {
+		r = append(r, "-Xmx"+memory)
+	}
+	r = append(r, options...)
+	r = append(r, class)
+	return append(r, args...)
+}
+
+// Command returns the exec.Cmd to run the specified class
+// with the specified class arguments.
+func (c *Config) Command(class string, args ...string) *exec.Cmd {
+	return exec.Command(c.JavaPath(), c.Args(class, args...)...)
+}
@@ -55,6 +55,12 @@ var (
 		{"\n", " ", " ", "", "\n  "},
 		{"\n  ", " ", " ", " ", "", "\n"},
 	}
+	RosesAreRedSentenceTokenBeginCharLists = [NumRosesAreRedSentence][]uint32{
+		{1, 7, 11, 14},
+		{18, 26, 30, 34},
+		{36, 42, 45, 50},
+		{54, 58, 61, 65, 68},
+	}
 	RosesAreRedSentenceTokenPosLists = [NumRosesAreRedSentence][]string{
 		{"NNPS", "VBP", "JJ", "."},
 		{"NNS", "VBP", "JJ", "."},
@@ -271,6 +277,23 @@ func checkRosesAreRedToken(
 			return gogoerrors.AutoWrap(err)
 		}
 	}
+	// The character offsets must agree with the gaps checked above.
+	beginChar := RosesAreRedSentenceTokenBeginCharLists[sentIdx][tokenIdx]
+	endChar := beginChar + uint32(len(
+		RosesAreRedSentenceTokenWordLists[sentIdx][tokenIdx]))
+	offsetCases := []struct {
+		method string
+		want   uint32
+	}{
+		{method: "GetBeginChar", want: beginChar},
+		{method: "GetEndChar", want: endChar},
+	}
+	for _, c := range offsetCases {
+		err := checkUint32Method(tokenV, c.method, c.want, sentIdx, tokenIdx)
+		if err != nil {
+			return gogoerrors.AutoWrap(err)
+		}
+	}
 	return nil
 }
 
@@ -352,3 +375,53 @@ func checkStringMethod(
 	}
 	return nil
 }
+
+// checkUint32Method is a sub procedure of the function checkRosesAreRedToken.
+func checkUint32Method(
+	tokenV reflect.Value,
+	methodName string,
+	want uint32,
+	sentIdx int,
+	tokenIdx int,
+) error {
+	m := tokenV.MethodByName(methodName)
+	if !m.IsValid() {
+		return gogoerrors.AutoNew(fmt.Sprintf(
+			"token#%d in sentence#%d does not have method %s",
+			tokenIdx,
+			sentIdx,
+			methodName,
+		))
+	}
+	retV := m.Call(nil)
+	if n := len(retV); n != 1 {
+		return gogoerrors.AutoNew(fmt.Sprintf(
+			"token#%d.%s in sentence#%d returned %d values; want 1",
+			tokenIdx,
+			methodName,
+			sentIdx,
+			n,
+		))
+	}
+	uintV := retV[0]
+	if kind := uintV.Kind(); kind != reflect.Uint32 {
+		return gogoerrors.AutoNew(fmt.Sprintf(
+			"token#%d.%s in sentence#%d returned a %v; want a uint32",
+			tokenIdx,
+			methodName,
+			sentIdx,
+			kind,
+		))
+	}
+	if x := uint32(uintV.Uint()); x != want {
+		return gogoerrors.AutoNew(fmt.Sprintf(
+			"token#%d.%s in sentence#%d returned %d; want %d",
+			tokenIdx,
+			methodName,
+			sentIdx,
+			x,
+			want,
+		))
+	}
+	return nil
+}
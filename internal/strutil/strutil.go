@@ -0,0 +1,47 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package strutil provides string helpers shared by
+// the packages errors and client.
+package strutil
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Shorten cuts the specified string if it is too long;
+// otherwise, Shorten returns s itself.
+//
+// It preserves at least the first n bytes.
+// The returned string is at most (n + 10) bytes.
+//
+// If the returned string is different from s,
+// it must have the suffix "...".
+func Shorten(s string, n int) string {
+	if len(s) <= n+10 {
+		return s
+	}
+	// Try to preserve a complete word.
+	end := n + 7 // 7 = 10 (at most 10 more bytes) - 3 ("...")
+	idx := strings.LastIndexFunc(s[n:end], unicode.IsSpace)
+	if idx < 0 {
+		return s[:end] + "..."
+	}
+	return s[:n+idx] + "..."
+}
@@ -38,6 +38,10 @@
 // packages and in different directories, but the conflict still exists.)
 // Although this naming style seems verbose and redundant, it works.
 //
+// Besides pb, a version directory may contain other subpackages
+// providing utilities for working with the structures of that version,
+// such as github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/depgraph.
+//
 // The .pb.go files are auto-generated according to
 // the corresponding .proto files. For more information,
 // see <https://protobuf.dev/getting-started/gotutorial/>.
@@ -0,0 +1,275 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package coref
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/textoffset"
+)
+
+// MentionTypePronominal is the mention type of pronouns.
+const MentionTypePronominal = "PRONOMINAL"
+
+// Mention is a mention in a coreference chain,
+// resolved against the sentences of its document.
+type Mention struct {
+	ID            int32  // ID is the mention ID.
+	Type          string // Type is the mention type, such as "PROPER", "NOMINAL", and "PRONOMINAL".
+	Number        string // Number is the grammatical number, such as "SINGULAR".
+	Gender        string // Gender is the gender, such as "FEMALE".
+	Animacy       string // Animacy is the animacy, such as "ANIMATE".
+	SentenceIndex uint32 // SentenceIndex is the 0-based index of the sentence containing the mention.
+
+	// TokenBegin and TokenEnd are the 0-based token positions
+	// of the mention in its sentence,
+	// including TokenBegin and excluding TokenEnd.
+	TokenBegin, TokenEnd uint32
+
+	// Head is the 0-based token position of
+	// the head word of the mention in its sentence.
+	Head uint32
+
+	// BeginChar and EndChar are the character offsets of the mention
+	// in the document text, including BeginChar and excluding EndChar,
+	// taken from the fields beginChar and endChar of the tokens.
+	//
+	// Note that CoreNLP counts characters in UTF-16 code units.
+	BeginChar, EndChar uint32
+
+	// Text is the original text of the mention,
+	// including the whitespace between its tokens.
+	Text string
+
+	// IsRepresentative reports whether the mention is
+	// the representative mention of its chain.
+	IsRepresentative bool
+
+	// Tokens are the tokens of the mention.
+	Tokens []*pb.Token
+}
+
+// Chain is a coreference chain with resolved mentions.
+type Chain struct {
+	ID       int32     // ID is the chain ID.
+	Mentions []Mention // Mentions are the mentions of the chain, in the same order as in the ProtoBuf message.
+
+	// Representative is the index of the representative mention in Mentions.
+	Representative int
+}
+
+// RepresentativeMention returns the representative mention of the chain.
+//
+// It returns nil if the chain has no mention.
+func (c *Chain) RepresentativeMention() *Mention {
+	if c == nil || c.Representative < 0 ||
+		c.Representative >= len(c.Mentions) {
+		return nil
+	}
+	return &c.Mentions[c.Representative]
+}
+
+// Chains resolves the coreference chains of the document.
+//
+// The chains are sorted by their IDs.
+//
+// It reports an error if doc is nil, or a mention or
+// a representative refers to a nonexistent sentence or token.
+func Chains(doc *pb.Document) ([]Chain, error) {
+	if doc == nil {
+		return nil, gogoerrors.AutoNew("document is nil")
+	}
+	pbChains := doc.GetCorefChain()
+	chains := make([]Chain, len(pbChains))
+	for i, pbChain := range pbChains {
+		chain, err := resolveChain(doc.GetSentence(), pbChain)
+		if err != nil {
+			return nil, gogoerrors.AutoWrap(err)
+		}
+		chains[i] = *chain
+	}
+	slices.SortStableFunc(chains, func(a, b Chain) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return chains, nil
+}
+
+// Resolve returns the original text of the document,
+// in which each pronominal mention (see MentionTypePronominal)
+// is replaced by the text of the representative mention of its chain.
+//
+// A possessive pronoun (with a part-of-speech tag "PRP$" or "WP$")
+// is replaced by the representative mention followed by "'s".
+// Pronominal mentions whose representative mention is also pronominal,
+// or that overlap with an earlier replaced mention, remain unchanged.
+//
+// The whitespace between tokens is taken from
+// the fields before and after of the tokens,
+// and the surface text of a multi-word token is written once
+// for all its pieces (see function textoffset.SpanText),
+// so the returned text equals the document text
+// if there is nothing to replace.
+// Mentions that begin or end inside a multi-word token remain unchanged,
+// as only part of its surface text cannot be replaced.
+//
+// It reports an error if doc is nil or the coreference chains are invalid
+// (see function Chains).
+func Resolve(doc *pb.Document) (string, error) {
+	chains, err := Chains(doc)
+	if err != nil {
+		return "", gogoerrors.AutoWrap(err)
+	}
+	// replacements[sentence][begin] is the replacement of
+	// the mention starting from the token begin in the sentence.
+	replacements := make(map[uint32]map[uint32]replacement)
+	for i := range chains {
+		rep := chains[i].RepresentativeMention()
+		if rep == nil || rep.Type == MentionTypePronominal {
+			continue
+		}
+		for j := range chains[i].Mentions {
+			m := &chains[i].Mentions[j]
+			if m.IsRepresentative || m.Type != MentionTypePronominal {
+				continue
+			}
+			text := rep.Text
+			if isPossessive(m) {
+				text += "'s"
+			}
+			sentReps := replacements[m.SentenceIndex]
+			if sentReps == nil {
+				sentReps = make(map[uint32]replacement)
+				replacements[m.SentenceIndex] = sentReps
+			}
+			if _, ok := sentReps[m.TokenBegin]; !ok {
+				sentReps[m.TokenBegin] = replacement{
+					end:  m.TokenEnd,
+					text: text,
+				}
+			}
+		}
+	}
+
+	var b strings.Builder
+	first := true
+	for sentIdx, sentence := range doc.GetSentence() {
+		tokens := sentence.GetToken()
+		sentReps := replacements[uint32(sentIdx)]
+		for i := 0; i < len(tokens); i++ {
+			if first {
+				b.WriteString(tokens[i].GetBefore())
+				first = false
+			}
+			// Once a mention is replaced, the scan skips its tokens,
+			// so mentions overlapping with it remain unchanged.
+			if r, ok := sentReps[uint32(i)]; ok && !splitsMWT(tokens, i, int(r.end)) {
+				b.WriteString(r.text)
+				i = int(r.end) - 1
+			} else if i == 0 || !isMWTContinuation(tokens[i]) {
+				b.WriteString(textoffset.SurfaceText(tokens[i]))
+			}
+			b.WriteString(tokens[i].GetAfter())
+		}
+	}
+	return b.String(), nil
+}
+
+// replacement is the replacement of a mention in the function Resolve.
+type replacement struct {
+	end  uint32 // end is the token position after the mention in its sentence.
+	text string // text is the text replacing the mention.
+}
+
+// resolveChain resolves the mentions of the specified chain
+// against the specified sentences.
+func resolveChain(sentences []*pb.Sentence, pbChain *pb.CorefChain) (
+	*Chain, error) {
+	pbMentions := pbChain.GetMention()
+	chain := &Chain{
+		ID:             pbChain.GetChainID(),
+		Mentions:       make([]Mention, len(pbMentions)),
+		Representative: int(pbChain.GetRepresentative()),
+	}
+	if len(pbMentions) > 0 && chain.Representative >= len(pbMentions) {
+		return nil, gogoerrors.AutoNew(fmt.Sprintf(
+			"representative %d of chain %d is out of range; the chain has %d mention(s)",
+			chain.Representative, chain.ID, len(pbMentions)))
+	}
+	for i, pbMention := range pbMentions {
+		m := Mention{
+			ID:               pbMention.GetMentionID(),
+			Type:             pbMention.GetMentionType(),
+			Number:           pbMention.GetNumber(),
+			Gender:           pbMention.GetGender(),
+			Animacy:          pbMention.GetAnimacy(),
+			SentenceIndex:    pbMention.GetSentenceIndex(),
+			TokenBegin:       pbMention.GetBeginIndex(),
+			TokenEnd:         pbMention.GetEndIndex(),
+			Head:             pbMention.GetHeadIndex(),
+			IsRepresentative: i == chain.Representative,
+		}
+		if uint64(m.SentenceIndex) >= uint64(len(sentences)) {
+			return nil, gogoerrors.AutoNew(fmt.Sprintf(
+				"mention %d of chain %d refers to nonexistent sentence %d",
+				m.ID, chain.ID, m.SentenceIndex))
+		}
+		tokens := sentences[m.SentenceIndex].GetToken()
+		if m.TokenBegin >= m.TokenEnd ||
+			uint64(m.TokenEnd) > uint64(len(tokens)) {
+			return nil, gogoerrors.AutoNew(fmt.Sprintf(
+				"mention %d of chain %d has invalid token range [%d, %d) in sentence %d with %d token(s)",
+				m.ID, chain.ID, m.TokenBegin, m.TokenEnd,
+				m.SentenceIndex, len(tokens)))
+		}
+		m.Tokens = tokens[m.TokenBegin:m.TokenEnd:m.TokenEnd]
+		m.BeginChar = m.Tokens[0].GetBeginChar()
+		m.EndChar = m.Tokens[len(m.Tokens)-1].GetEndChar()
+		m.Text = textoffset.SpanText(m.Tokens)
+		chain.Mentions[i] = m
+	}
+	return chain, nil
+}
+
+// splitsMWT reports whether the token range [begin, end) of tokens
+// begins or ends inside a multi-word token.
+func splitsMWT(tokens []*pb.Token, begin, end int) bool {
+	return isMWTContinuation(tokens[begin]) ||
+		end < len(tokens) && isMWTContinuation(tokens[end])
+}
+
+// isMWTContinuation reports whether the token is a piece of
+// a multi-word token other than the first one.
+func isMWTContinuation(token *pb.Token) bool {
+	return token.GetIsMWT() && !token.GetIsFirstMWT()
+}
+
+// isPossessive reports whether the mention is a possessive pronoun.
+func isPossessive(m *Mention) bool {
+	if len(m.Tokens) != 1 {
+		return false
+	}
+	pos := m.Tokens[0].GetPos()
+	return pos == "PRP$" || pos == "WP$"
+}
@@ -0,0 +1,221 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package coref_test
+
+import (
+	"testing"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/coref"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/internal/testdoc"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/textoffset"
+)
+
+func TestChains(t *testing.T) {
+	doc := NewMaryDocument()
+	chains, err := coref.Chains(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chains) != 2 {
+		t.Fatalf("got %d chain(s); want 2", len(chains))
+	}
+	if chains[0].ID != 2 || chains[1].ID != 5 {
+		t.Errorf("got chain IDs %d, %d; want 2, 5", chains[0].ID, chains[1].ID)
+	}
+	mary := chains[1]
+	rep := mary.RepresentativeMention()
+	if rep == nil || rep.Text != "Mary" || !rep.IsRepresentative {
+		t.Errorf("got representative %+v; want Mary", rep)
+	}
+	wantMentions := []struct {
+		text                 string
+		beginChar, endChar   uint32
+		tokenBegin, tokenEnd uint32
+	}{
+		{"Mary", 0, 4, 0, 1},
+		{"she", 10, 13, 2, 3},
+		{"her", 20, 23, 4, 5},
+	}
+	if len(mary.Mentions) != len(wantMentions) {
+		t.Fatalf("got %d mention(s); want %d",
+			len(mary.Mentions), len(wantMentions))
+	}
+	for i, want := range wantMentions {
+		m := mary.Mentions[i]
+		if m.Text != want.text || m.BeginChar != want.beginChar ||
+			m.EndChar != want.endChar || m.TokenBegin != want.tokenBegin ||
+			m.TokenEnd != want.tokenEnd {
+			t.Errorf("mention#%d: got %q [%d, %d) tokens [%d, %d); want %q [%d, %d) tokens [%d, %d)",
+				i, m.Text, m.BeginChar, m.EndChar, m.TokenBegin, m.TokenEnd,
+				want.text, want.beginChar, want.endChar,
+				want.tokenBegin, want.tokenEnd)
+		}
+		if got := doc.GetText()[m.BeginChar:m.EndChar]; got != m.Text {
+			t.Errorf("mention#%d: got document text %q; want %q",
+				i, got, m.Text)
+		}
+	}
+	if dog := chains[0].RepresentativeMention(); dog.Text != "her dog" {
+		t.Errorf("got representative %q; want \"her dog\"", dog.Text)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	got, err := coref.Resolve(NewMaryDocument())
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "Mary said Mary likes Mary's dog. her dog barks."
+	if got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestResolve_NoChain(t *testing.T) {
+	doc := NewMaryDocument()
+	doc.CorefChain = nil
+	got, err := coref.Resolve(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := doc.GetText(); got != text {
+		t.Errorf("got %q; want %q", got, text)
+	}
+}
+
+func TestResolve_MultiWordToken(t *testing.T) {
+	doc := testdoc.New(
+		[]string{"Juan", "vino", "del", "mercado", "."},
+		[]string{"Él", "compró", "pan", "."},
+	)
+	testdoc.SplitMWT(doc, 0, 2, "de", "el")
+	got, err := coref.Resolve(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := doc.GetText(); got != text {
+		t.Errorf("got %q; want %q", got, text)
+	}
+	if got := textoffset.SpanText(doc.Sentence[0].Token[1:5]); got != "vino del mercado" {
+		t.Errorf("got span text %q; want \"vino del mercado\"", got)
+	}
+
+	doc.CorefChain = []*pb.CorefChain{{
+		ChainID:        testdoc.I32(0),
+		Representative: testdoc.U32(0),
+		Mention: []*pb.CorefChain_CorefMention{
+			NewMention(0, "PROPER", 0, 0, 1),
+			NewMention(1, "PRONOMINAL", 1, 0, 1),
+			// "el" begins inside the multi-word token "del".
+			NewMention(2, "PRONOMINAL", 0, 3, 4),
+		},
+	}}
+	got, err = coref.Resolve(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "Juan vino del mercado. Juan compró pan."
+	if got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestChains_Invalid(t *testing.T) {
+	testCases := []struct {
+		name   string
+		modify func(chain *pb.CorefChain)
+	}{
+		{"nonexistent sentence", func(chain *pb.CorefChain) {
+			chain.Mention[0].SentenceIndex = testdoc.U32(2)
+		}},
+		{"empty token range", func(chain *pb.CorefChain) {
+			chain.Mention[0].EndIndex = testdoc.U32(0)
+		}},
+		{"token range out of bound", func(chain *pb.CorefChain) {
+			chain.Mention[0].EndIndex = testdoc.U32(8)
+		}},
+		{"representative out of range", func(chain *pb.CorefChain) {
+			chain.Representative = testdoc.U32(3)
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc := NewMaryDocument()
+			tc.modify(doc.CorefChain[0])
+			if _, err := coref.Chains(doc); err == nil {
+				t.Error("got nil error")
+			}
+		})
+	}
+	if _, err := coref.Chains(nil); err == nil {
+		t.Error("got nil error for nil document")
+	}
+}
+
+// NewMaryDocument returns a document of the text
+// "Mary said she likes her dog. It barks."
+// with two coreference chains:
+// chain 5 {Mary*, she, her} and chain 2 {her dog*, It},
+// where * marks the representative mentions.
+func NewMaryDocument() *pb.Document {
+	doc := testdoc.New(
+		[]string{"Mary", "said", "she", "likes", "her", "dog", "."},
+		[]string{"It", "barks", "."},
+	)
+	testdoc.SetPOS(doc, 0, "NNP", "VBD", "PRP", "VBZ", "PRP$", "NN", ".")
+	testdoc.SetPOS(doc, 1, "PRP", "VBZ", ".")
+	doc.CorefChain = []*pb.CorefChain{
+		{
+			ChainID:        testdoc.I32(5),
+			Representative: testdoc.U32(0),
+			Mention: []*pb.CorefChain_CorefMention{
+				NewMention(0, "PROPER", 0, 0, 1),
+				NewMention(1, "PRONOMINAL", 0, 2, 3),
+				NewMention(2, "PRONOMINAL", 0, 4, 5),
+			},
+		},
+		{
+			ChainID:        testdoc.I32(2),
+			Representative: testdoc.U32(1),
+			Mention: []*pb.CorefChain_CorefMention{
+				NewMention(4, "PRONOMINAL", 1, 0, 1),
+				NewMention(3, "NOMINAL", 0, 4, 6),
+			},
+		},
+	}
+	return doc
+}
+
+// NewMention returns a coreference mention
+// whose head is its last token.
+func NewMention(
+	id int32,
+	mentionType string,
+	sentenceIndex, begin, end uint32,
+) *pb.CorefChain_CorefMention {
+	return &pb.CorefChain_CorefMention{
+		MentionID:     testdoc.I32(id),
+		MentionType:   testdoc.S(mentionType),
+		SentenceIndex: testdoc.U32(sentenceIndex),
+		BeginIndex:    testdoc.U32(begin),
+		EndIndex:      testdoc.U32(end),
+		HeadIndex:     testdoc.U32(end - 1),
+	}
+}
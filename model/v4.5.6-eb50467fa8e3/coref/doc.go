@@ -0,0 +1,29 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package coref provides helpers for working with the coreference chains
+// of Stanford CoreNLP 4.5.6 documents.
+//
+// A github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb.CorefChain
+// records its mentions by a sentence index and
+// 0-based token indexes within that sentence.
+// The function Chains resolves these indexes to
+// the text and character offsets of the mentions,
+// and the function Resolve produces a text in which pronouns
+// are replaced by the representative mentions of their chains.
+package coref
@@ -0,0 +1,38 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package depgraph provides a navigable and renderable view over
+// the dependency graphs of Stanford CoreNLP 4.5.6.
+//
+// A github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb.DependencyGraph
+// stores its nodes, edges, and roots in flat lists,
+// with 1-based token indexes that refer to the tokens of its sentence.
+// The type Graph in this package indexes these lists so that
+// heads, children, ancestors, subtrees, and paths can be looked up directly,
+// and resolves nodes back to the tokens of the sentence.
+//
+// Graph supports enhanced dependency graphs,
+// which may contain extra edges (isExtra), copy nodes (copyAnnotation),
+// empty nodes (emptyIndex), and cycles.
+// All traversals in this package visit each node at most once.
+//
+// Graph can also be rendered for debugging,
+// in the Graphviz DOT language (method WriteDOT) or
+// as a self-contained SVG arc diagram (method WriteSVG)
+// that requires no external programs.
+package depgraph
@@ -0,0 +1,139 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package depgraph
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+)
+
+// RenderOptions are the options for rendering a dependency graph
+// by the methods WriteDOT and WriteSVG.
+type RenderOptions struct {
+	// Title is the name of the DOT graph or the title of the SVG image.
+	//
+	// Default: "" (empty, no title)
+	Title string
+
+	// ShowPOS indicates whether to show the part-of-speech tags
+	// of the tokens under their words.
+	//
+	// Default: false
+	ShowPOS bool
+
+	// onlyKeyedLiterals forces others to construct RenderOptions
+	// only with the keyed literals, so future additions to it
+	// will not violate compatibility.
+	onlyKeyedLiterals struct{}
+}
+
+var _ = RenderOptions{}.onlyKeyedLiterals // to suppress "field `onlyKeyedLiterals` is unused (unused)"
+
+// WriteDOT writes the graph in the Graphviz DOT language to w.
+//
+// Each node is labeled with the word of its token
+// (and the part-of-speech tag if opt.ShowPOS is true),
+// or its node ID if the token is unavailable.
+// Roots are drawn with bold borders and pointed to by
+// an edge "root" from an additional node "ROOT".
+// Extra edges are drawn as dashed blue lines.
+//
+// If opt is nil, it uses default options.
+func (g *Graph) WriteDOT(w io.Writer, opt *RenderOptions) error {
+	if opt == nil {
+		opt = new(RenderOptions)
+	}
+	var b strings.Builder
+	b.WriteString("digraph")
+	if len(opt.Title) > 0 {
+		b.WriteByte(' ')
+		b.WriteString(dotQuote(opt.Title))
+	}
+	b.WriteString(" {\n\tnode [shape=box];\n")
+	if len(g.roots) > 0 {
+		b.WriteString("\tROOT [shape=plaintext];\n")
+	}
+	for _, n := range g.nodes {
+		fmt.Fprintf(&b, "\t%s [label=%s", dotQuote(n.String()),
+			dotQuote(g.nodeLabel(n, opt.ShowPOS, "\n")))
+		if g.IsRoot(n) {
+			b.WriteString(", style=bold")
+		}
+		b.WriteString("];\n")
+	}
+	for _, r := range g.roots {
+		fmt.Fprintf(&b, "\tROOT -> %s [label=\"root\"];\n", dotQuote(r.String()))
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(&b, "\t%s -> %s [label=%s",
+			dotQuote(e.Source.String()),
+			dotQuote(e.Target.String()),
+			dotQuote(e.Dep),
+		)
+		if e.IsExtra {
+			b.WriteString(", style=dashed, color=blue, fontcolor=blue")
+		}
+		b.WriteString("];\n")
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return gogoerrors.AutoWrap(err)
+}
+
+// nodeLabel returns the label of the node n for rendering.
+//
+// If showPOS is true and the part-of-speech tag of the token is available,
+// the label consists of the word and the tag, separated by sep.
+func (g *Graph) nodeLabel(n NodeID, showPOS bool, sep string) string {
+	t := g.Token(n)
+	if t == nil {
+		return n.String()
+	}
+	label := t.GetWord()
+	if n.Copy != 0 {
+		label += strings.Repeat("'", int(n.Copy))
+	}
+	if showPOS && len(t.GetPos()) > 0 {
+		label += sep + t.GetPos()
+	}
+	return label
+}
+
+// dotQuote returns a double-quoted DOT string literal representing s.
+func dotQuote(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
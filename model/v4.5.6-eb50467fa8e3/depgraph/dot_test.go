@@ -0,0 +1,88 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package depgraph_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/donyori/gocorenlp/internal/pbtest"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/depgraph"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+func TestGraph_WriteDOT(t *testing.T) {
+	doc := new(pb.Document)
+	err := pbtest.DecodeBase64ToPb(pbtest.RosesAreRedRespV456, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := depgraph.FromSentence(doc.GetSentence()[0], depgraph.Basic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `digraph "Roses \"are\" red" {
+	node [shape=box];
+	ROOT [shape=plaintext];
+	"1" [label="Roses\nNNPS"];
+	"2" [label="are\nVBP"];
+	"3" [label="red\nJJ", style=bold];
+	"4" [label=".\n."];
+	ROOT -> "3" [label="root"];
+	"3" -> "1" [label="nsubj"];
+	"3" -> "2" [label="cop"];
+	"3" -> "4" [label="punct"];
+}
+`
+	var b strings.Builder
+	err = g.WriteDOT(&b, &depgraph.RenderOptions{
+		Title:   `Roses "are" red`,
+		ShowPOS: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGraph_WriteDOT_Enhanced(t *testing.T) {
+	g, err := depgraph.New(NewEnhancedGraph(), EnhancedTokens())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err = g.WriteDOT(&b, nil); err != nil {
+		t.Fatal(err)
+	}
+	dot := b.String()
+	if n := strings.Count(dot, "style=dashed"); n != 3 {
+		t.Errorf("got %d dashed edge(s); want 3", n)
+	}
+	for _, s := range []string{
+		`"2'1" [label="man'"];`,
+		`"5.1" [label="5.1"];`,
+		`"5" -> "5.1" [label="orphan"];`,
+	} {
+		if !strings.Contains(dot, s) {
+			t.Errorf("got\n%s\nwhich does not contain %s", dot, s)
+		}
+	}
+}
@@ -0,0 +1,637 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package depgraph
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+// Kind specifies one of the dependency graphs stored in a sentence.
+type Kind int8
+
+const (
+	Basic                Kind = iota // basicDependencies
+	Collapsed                        // collapsedDependencies
+	CollapsedCCProcessed             // collapsedCCProcessedDependencies
+	Alternative                      // alternativeDependencies
+	Enhanced                         // enhancedDependencies
+	EnhancedPlusPlus                 // enhancedPlusPlusDependencies
+)
+
+// String returns the name of the corresponding field in Sentence.
+func (k Kind) String() string {
+	switch k {
+	case Basic:
+		return "basicDependencies"
+	case Collapsed:
+		return "collapsedDependencies"
+	case CollapsedCCProcessed:
+		return "collapsedCCProcessedDependencies"
+	case Alternative:
+		return "alternativeDependencies"
+	case Enhanced:
+		return "enhancedDependencies"
+	case EnhancedPlusPlus:
+		return "enhancedPlusPlusDependencies"
+	}
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Of returns the dependency graph of kind k in the specified sentence.
+//
+// It returns nil if s is nil, the sentence does not have such a graph,
+// or k is unknown.
+func (k Kind) Of(s *pb.Sentence) *pb.DependencyGraph {
+	switch k {
+	case Basic:
+		return s.GetBasicDependencies()
+	case Collapsed:
+		return s.GetCollapsedDependencies()
+	case CollapsedCCProcessed:
+		return s.GetCollapsedCCProcessedDependencies()
+	case Alternative:
+		return s.GetAlternativeDependencies()
+	case Enhanced:
+		return s.GetEnhancedDependencies()
+	case EnhancedPlusPlus:
+		return s.GetEnhancedPlusPlusDependencies()
+	}
+	return nil
+}
+
+// NodeID identifies a node in a dependency graph.
+type NodeID struct {
+	// Index is the 1-based index of the token in its sentence.
+	Index uint32
+
+	// Copy is the copy number of the node,
+	// corresponding to copyAnnotation of the node
+	// and sourceCopy/targetCopy of the edge.
+	// It is 0 for the original token.
+	Copy uint32
+
+	// Empty is the index of an empty node (e.g., 1 for the node "8.1"
+	// in CoNLL-U) after the token Index,
+	// corresponding to emptyIndex of the node
+	// and sourceEmpty/targetEmpty of the edge.
+	// It is 0 for a node that is not empty.
+	Empty uint32
+}
+
+// String returns the node ID in the form "Index[.Empty]['Copy]",
+// for example, "3", "8.1", and "5'1".
+func (id NodeID) String() string {
+	s := strconv.FormatUint(uint64(id.Index), 10)
+	if id.Empty != 0 {
+		s += "." + strconv.FormatUint(uint64(id.Empty), 10)
+	}
+	if id.Copy != 0 {
+		s += "'" + strconv.FormatUint(uint64(id.Copy), 10)
+	}
+	return s
+}
+
+// compareNodeID compares two node IDs in token order.
+func compareNodeID(a, b NodeID) int {
+	if c := cmp.Compare(a.Index, b.Index); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Empty, b.Empty); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Copy, b.Copy)
+}
+
+// Edge is a dependency relation from the head Source to the dependent Target.
+type Edge struct {
+	Source   NodeID      // Source is the head of the relation.
+	Target   NodeID      // Target is the dependent of the relation.
+	Dep      string      // Dep is the relation name, such as "nsubj" and "obl:tmod".
+	IsExtra  bool        // IsExtra reports whether the edge is an extra edge of an enhanced graph.
+	Language pb.Language // Language is the language of the relation.
+}
+
+// Graph is a read-only view over a dependency graph
+// with indexes for navigation.
+//
+// A Graph must be created by the function New or FromSentence.
+type Graph struct {
+	sentenceIndex uint32
+	nodes         []NodeID // sorted in token order
+	nodeSet       map[NodeID]struct{}
+	roots         []NodeID
+	edges         []Edge // in the order of the ProtoBuf message
+	out           map[NodeID][]int
+	in            map[NodeID][]int
+	tokens        map[NodeID]*pb.Token
+}
+
+// New creates a Graph over the specified dependency graph.
+//
+// tokens are the tokens of the sentence that the graph belongs to,
+// used to resolve nodes back to tokens.
+// If tokens is empty, New uses the tokens stored in the graph
+// (the field token of DependencyGraph), if any.
+// A token is associated with the node whose index is the position
+// of the token in the list plus 1, unless the token has
+// the field index set explicitly.
+// Empty nodes are resolved only through tokens with the field emptyIndex set.
+//
+// New reports an error if g is nil, an edge refers to a node
+// not in the node list, or a root refers to a nonexistent node.
+func New(g *pb.DependencyGraph, tokens []*pb.Token) (*Graph, error) {
+	if g == nil {
+		return nil, gogoerrors.AutoNew("dependency graph is nil")
+	}
+	pbNodes := g.GetNode()
+	graph := &Graph{
+		nodes:   make([]NodeID, 0, len(pbNodes)),
+		nodeSet: make(map[NodeID]struct{}, len(pbNodes)),
+		out:     make(map[NodeID][]int),
+		in:      make(map[NodeID][]int),
+	}
+	if len(pbNodes) > 0 {
+		graph.sentenceIndex = pbNodes[0].GetSentenceIndex()
+	}
+	nodeOf := make([]NodeID, len(pbNodes))
+	for i, node := range pbNodes {
+		id := NodeID{
+			Index: node.GetIndex(),
+			Copy:  node.GetCopyAnnotation(),
+			Empty: node.GetEmptyIndex(),
+		}
+		nodeOf[i] = id
+		if _, ok := graph.nodeSet[id]; !ok {
+			graph.nodeSet[id] = struct{}{}
+			graph.nodes = append(graph.nodes, id)
+		}
+	}
+	slices.SortFunc(graph.nodes, compareNodeID)
+
+	pbEdges := g.GetEdge()
+	graph.edges = make([]Edge, len(pbEdges))
+	for i, edge := range pbEdges {
+		e := Edge{
+			Source: NodeID{
+				Index: edge.GetSource(),
+				Copy:  edge.GetSourceCopy(),
+				Empty: edge.GetSourceEmpty(),
+			},
+			Target: NodeID{
+				Index: edge.GetTarget(),
+				Copy:  edge.GetTargetCopy(),
+				Empty: edge.GetTargetEmpty(),
+			},
+			Dep:      edge.GetDep(),
+			IsExtra:  edge.GetIsExtra(),
+			Language: edge.GetLanguage(),
+		}
+		if !graph.HasNode(e.Source) {
+			return nil, gogoerrors.AutoNew(fmt.Sprintf(
+				"edge#%d refers to nonexistent source node %v", i, e.Source))
+		}
+		if !graph.HasNode(e.Target) {
+			return nil, gogoerrors.AutoNew(fmt.Sprintf(
+				"edge#%d refers to nonexistent target node %v", i, e.Target))
+		}
+		graph.edges[i] = e
+		graph.out[e.Source] = append(graph.out[e.Source], i)
+		graph.in[e.Target] = append(graph.in[e.Target], i)
+	}
+
+	// Prefer rootNode as it carries the copy and empty information.
+	if rootNodes := g.GetRootNode(); len(rootNodes) > 0 {
+		graph.roots = make([]NodeID, 0, len(rootNodes))
+		for _, r := range rootNodes {
+			if uint64(r) >= uint64(len(nodeOf)) {
+				return nil, gogoerrors.AutoNew(fmt.Sprintf(
+					"root node %d is out of range; the graph has %d node(s)",
+					r, len(nodeOf)))
+			}
+			graph.roots = appendIfAbsent(graph.roots, nodeOf[r])
+		}
+	} else if roots := g.GetRoot(); len(roots) > 0 {
+		graph.roots = make([]NodeID, 0, len(roots))
+		for _, r := range roots {
+			id := NodeID{Index: r}
+			if !graph.HasNode(id) {
+				return nil, gogoerrors.AutoNew(fmt.Sprintf(
+					"root %d refers to nonexistent node", r))
+			}
+			graph.roots = appendIfAbsent(graph.roots, id)
+		}
+	}
+
+	if len(tokens) == 0 {
+		tokens = g.GetToken()
+	}
+	if len(tokens) > 0 {
+		graph.tokens = make(map[NodeID]*pb.Token, len(tokens))
+		for i, token := range tokens {
+			id := NodeID{Index: token.GetIndex(), Empty: token.GetEmptyIndex()}
+			if id.Index == 0 {
+				id.Index = uint32(i + 1)
+			}
+			graph.tokens[id] = token
+		}
+	}
+	return graph, nil
+}
+
+// FromSentence creates a Graph over the dependency graph of
+// the specified kind in the sentence s.
+// The nodes are resolved to the tokens of s.
+//
+// It reports an error if s is nil or does not have such a graph.
+func FromSentence(s *pb.Sentence, kind Kind) (*Graph, error) {
+	if s == nil {
+		return nil, gogoerrors.AutoNew("sentence is nil")
+	}
+	g := kind.Of(s)
+	if g == nil {
+		return nil, gogoerrors.AutoNew(fmt.Sprintf(
+			"sentence#%d does not have %v", s.GetSentenceIndex(), kind))
+	}
+	graph, err := New(g, s.GetToken())
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	return graph, nil
+}
+
+// SentenceIndex returns the index of the sentence that
+// the graph belongs to, recorded in the nodes of the graph.
+func (g *Graph) SentenceIndex() uint32 {
+	return g.sentenceIndex
+}
+
+// Nodes returns all nodes of the graph in token order.
+//
+// The caller is free to modify the returned slice.
+func (g *Graph) Nodes() []NodeID {
+	return slices.Clone(g.nodes)
+}
+
+// Roots returns the roots of the graph.
+//
+// The caller is free to modify the returned slice.
+func (g *Graph) Roots() []NodeID {
+	return slices.Clone(g.roots)
+}
+
+// Edges returns all edges of the graph,
+// in the same order as in the ProtoBuf message.
+//
+// The caller is free to modify the returned slice.
+func (g *Graph) Edges() []Edge {
+	return slices.Clone(g.edges)
+}
+
+// HasNode reports whether n is a node of the graph.
+func (g *Graph) HasNode(n NodeID) bool {
+	_, ok := g.nodeSet[n]
+	return ok
+}
+
+// IsRoot reports whether n is a root of the graph.
+func (g *Graph) IsRoot(n NodeID) bool {
+	return slices.Contains(g.roots, n)
+}
+
+// Head returns the head of the node n and the relation between them.
+//
+// If n has more than one head (possible in enhanced graphs),
+// Head returns the first one connected by a non-extra edge,
+// or the first one if all are extra.
+// Use HeadEdges to get all heads.
+//
+// ok is false if n has no head (e.g., n is a root) or
+// n is not a node of the graph.
+func (g *Graph) Head(n NodeID) (head NodeID, dep string, ok bool) {
+	indexes := g.in[n]
+	if len(indexes) == 0 {
+		return
+	}
+	e := &g.edges[indexes[0]]
+	for _, i := range indexes {
+		if !g.edges[i].IsExtra {
+			e = &g.edges[i]
+			break
+		}
+	}
+	return e.Source, e.Dep, true
+}
+
+// HeadEdges returns all edges whose dependent is the node n,
+// in the same order as in the ProtoBuf message.
+func (g *Graph) HeadEdges(n NodeID) []Edge {
+	return g.collectEdges(g.in[n], nil)
+}
+
+// ChildEdges returns the edges whose head is the node n and
+// whose relation matches any of deps,
+// in the same order as in the ProtoBuf message.
+//
+// A relation matches d if it is d or starts with d followed by
+// a colon (e.g., both "obl" and "obl:tmod" match "obl").
+// If deps is empty, all relations match.
+func (g *Graph) ChildEdges(n NodeID, deps ...string) []Edge {
+	return g.collectEdges(g.out[n], deps)
+}
+
+// Children returns the distinct dependents of the node n
+// through relations matching any of deps, in token order.
+//
+// A relation matches d if it is d or starts with d followed by
+// a colon (e.g., both "obl" and "obl:tmod" match "obl").
+// If deps is empty, all relations match.
+func (g *Graph) Children(n NodeID, deps ...string) []NodeID {
+	var children []NodeID
+	for _, i := range g.out[n] {
+		e := &g.edges[i]
+		if matchDeps(e.Dep, deps) {
+			children = appendIfAbsent(children, e.Target)
+		}
+	}
+	slices.SortFunc(children, compareNodeID)
+	return children
+}
+
+// Ancestors returns all nodes from which the node n can be reached,
+// nearest first.
+// Nodes at the same distance are in token order.
+// n itself is excluded even if it is on a cycle.
+//
+// All edges, including extra edges, are followed.
+func (g *Graph) Ancestors(n NodeID) []NodeID {
+	var ancestors []NodeID
+	visited := map[NodeID]bool{n: true}
+	frontier := []NodeID{n}
+	for len(frontier) > 0 {
+		var next []NodeID
+		for _, x := range frontier {
+			for _, i := range g.in[x] {
+				head := g.edges[i].Source
+				if !visited[head] {
+					visited[head] = true
+					next = append(next, head)
+				}
+			}
+		}
+		slices.SortFunc(next, compareNodeID)
+		ancestors = append(ancestors, next...)
+		frontier = next
+	}
+	return ancestors
+}
+
+// Subtree returns the node n and all nodes reachable from n
+// through non-extra edges, in token order.
+//
+// It returns nil if n is not a node of the graph.
+func (g *Graph) Subtree(n NodeID) []NodeID {
+	if !g.HasNode(n) {
+		return nil
+	}
+	visited := map[NodeID]bool{n: true}
+	subtree, stack := []NodeID{n}, []NodeID{n}
+	for len(stack) > 0 {
+		x := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, i := range g.out[x] {
+			e := &g.edges[i]
+			if !e.IsExtra && !visited[e.Target] {
+				visited[e.Target] = true
+				subtree = append(subtree, e.Target)
+				stack = append(stack, e.Target)
+			}
+		}
+	}
+	slices.SortFunc(subtree, compareNodeID)
+	return subtree
+}
+
+// SubtreeSpan returns the smallest token span covering
+// the subtree of the node n (see Subtree).
+//
+// The span is represented by 0-based token positions in the sentence,
+// including begin and excluding end,
+// the same as NERMention.tokenStartInSentenceInclusive and
+// NERMention.tokenEndInSentenceExclusive.
+// Empty nodes are counted as the token after which they are.
+// For non-projective trees, the span may cover tokens
+// outside the subtree.
+//
+// ok is false if n is not a node of the graph or
+// the subtree only contains empty nodes after the token 0.
+func (g *Graph) SubtreeSpan(n NodeID) (begin, end int, ok bool) {
+	subtree := g.Subtree(n)
+	if len(subtree) == 0 {
+		return
+	}
+	first, last := subtree[0].Index, subtree[len(subtree)-1].Index
+	if first == 0 {
+		first = 1 // empty nodes before the first token
+	}
+	if last == 0 {
+		return
+	}
+	return int(first) - 1, int(last), true
+}
+
+// ShortestPath finds a shortest path between the nodes from and to,
+// ignoring the direction of edges.
+//
+// nodes are the nodes on the path, starting with from and ending with to.
+// edges are the edges on the path, where edges[i] connects
+// nodes[i] and nodes[i+1] (in either direction).
+// If from and to are the same node, nodes contains only that node
+// and edges is empty.
+//
+// Among multiple shortest paths, ShortestPath prefers
+// the one that uses edges earlier in the ProtoBuf message.
+//
+// ok is false if from or to is not a node of the graph
+// or there is no path between them.
+func (g *Graph) ShortestPath(from, to NodeID) (
+	nodes []NodeID, edges []Edge, ok bool) {
+	if !g.HasNode(from) || !g.HasNode(to) {
+		return
+	}
+	// via records the edge index used to reach each node.
+	via := map[NodeID]int{from: -1}
+	queue := []NodeID{from}
+	for len(queue) > 0 {
+		x := queue[0]
+		queue = queue[1:]
+		if x == to {
+			break
+		}
+		for _, i := range g.incident(x) {
+			e := &g.edges[i]
+			y := e.Target
+			if y == x {
+				y = e.Source
+			}
+			if _, visited := via[y]; !visited {
+				via[y] = i
+				queue = append(queue, y)
+			}
+		}
+	}
+	if _, reached := via[to]; !reached {
+		return nil, nil, false
+	}
+	for x := to; ; {
+		nodes = append(nodes, x)
+		i := via[x]
+		if i < 0 {
+			break
+		}
+		edges = append(edges, g.edges[i])
+		if g.edges[i].Source == x {
+			x = g.edges[i].Target
+		} else {
+			x = g.edges[i].Source
+		}
+	}
+	slices.Reverse(nodes)
+	slices.Reverse(edges)
+	return nodes, edges, true
+}
+
+// Walk traverses the graph in breadth-first order from its roots,
+// following all edges including extra edges,
+// and calls visit on each node with its distance from the nearest root.
+//
+// Each node is visited at most once,
+// so Walk terminates on graphs with cycles.
+// Nodes unreachable from the roots are not visited.
+//
+// Walk stops if visit returns false.
+func (g *Graph) Walk(visit func(n NodeID, depth int) (cont bool)) {
+	if visit == nil {
+		return
+	}
+	visited := make(map[NodeID]bool, len(g.nodes))
+	frontier := make([]NodeID, 0, len(g.roots))
+	for _, r := range g.roots {
+		if !visited[r] {
+			visited[r] = true
+			frontier = append(frontier, r)
+		}
+	}
+	for depth := 0; len(frontier) > 0; depth++ {
+		var next []NodeID
+		for _, x := range frontier {
+			if !visit(x, depth) {
+				return
+			}
+			for _, i := range g.out[x] {
+				y := g.edges[i].Target
+				if !visited[y] {
+					visited[y] = true
+					next = append(next, y)
+				}
+			}
+		}
+		frontier = next
+	}
+}
+
+// Token returns the token corresponding to the node n.
+//
+// A copy node resolves to the token it copies.
+// It returns nil if the token is unavailable.
+func (g *Graph) Token(n NodeID) *pb.Token {
+	n.Copy = 0
+	return g.tokens[n]
+}
+
+// Words returns the words of the tokens corresponding to the nodes,
+// joined by a space.
+// Nodes without a corresponding token are represented by their IDs.
+func (g *Graph) Words(nodes []NodeID) string {
+	var b strings.Builder
+	for i, n := range nodes {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		if t := g.Token(n); t != nil {
+			b.WriteString(t.GetWord())
+		} else {
+			b.WriteString(n.String())
+		}
+	}
+	return b.String()
+}
+
+// collectEdges returns the edges with the specified indexes
+// whose relation matches any of deps.
+func (g *Graph) collectEdges(indexes []int, deps []string) []Edge {
+	var edges []Edge
+	for _, i := range indexes {
+		if matchDeps(g.edges[i].Dep, deps) {
+			edges = append(edges, g.edges[i])
+		}
+	}
+	return edges
+}
+
+// incident returns the indexes of all edges connected to the node n,
+// in ascending order.
+func (g *Graph) incident(n NodeID) []int {
+	indexes := make([]int, 0, len(g.out[n])+len(g.in[n]))
+	indexes = append(indexes, g.out[n]...)
+	indexes = append(indexes, g.in[n]...)
+	slices.Sort(indexes)
+	return slices.Compact(indexes) // remove duplicate self-loops
+}
+
+// matchDeps reports whether the relation dep matches any of deps.
+//
+// If deps is empty, it returns true.
+func matchDeps(dep string, deps []string) bool {
+	if len(deps) == 0 {
+		return true
+	}
+	for _, d := range deps {
+		if dep == d || len(dep) > len(d) && dep[len(d)] == ':' &&
+			strings.HasPrefix(dep, d) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendIfAbsent appends id to s if s does not contain id.
+func appendIfAbsent(s []NodeID, id NodeID) []NodeID {
+	if slices.Contains(s, id) {
+		return s
+	}
+	return append(s, id)
+}
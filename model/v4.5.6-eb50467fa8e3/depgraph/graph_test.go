@@ -0,0 +1,300 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package depgraph_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/donyori/gocorenlp/internal/pbtest"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/depgraph"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+func TestFromSentence_RosesAreRed(t *testing.T) {
+	doc := new(pb.Document)
+	err := pbtest.DecodeBase64ToPb(pbtest.RosesAreRedRespV456, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := depgraph.FromSentence(doc.GetSentence()[0], depgraph.Basic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	red := N(3)
+	if roots := g.Roots(); !slices.Equal(roots, []depgraph.NodeID{red}) {
+		t.Errorf("got roots %v; want [3]", roots)
+	}
+	if head, dep, ok := g.Head(N(1)); !ok || head != red || dep != "nsubj" {
+		t.Errorf("got head %v, %q, %t; want 3, \"nsubj\", true", head, dep, ok)
+	}
+	if _, _, ok := g.Head(red); ok {
+		t.Error("got ok true for the head of the root")
+	}
+	if children := g.Children(red); !slices.Equal(children, Ns(1, 2, 4)) {
+		t.Errorf("got children %v; want [1 2 4]", children)
+	}
+	if children := g.Children(red, "nsubj"); !slices.Equal(children, Ns(1)) {
+		t.Errorf("got nsubj children %v; want [1]", children)
+	}
+	if begin, end, ok := g.SubtreeSpan(red); !ok || begin != 0 || end != 4 {
+		t.Errorf("got subtree span [%d, %d), %t; want [0, 4), true",
+			begin, end, ok)
+	}
+	if words := g.Words(g.Subtree(red)); words != "Roses are red ." {
+		t.Errorf("got words %q; want %q", words, "Roses are red .")
+	}
+	nodes, edges, ok := g.ShortestPath(N(1), N(4))
+	if !ok || !slices.Equal(nodes, Ns(1, 3, 4)) || len(edges) != 2 ||
+		edges[0].Dep != "nsubj" || edges[1].Dep != "punct" {
+		t.Errorf("got path %v, %v, %t; want [1 3 4] via nsubj and punct",
+			nodes, edges, ok)
+	}
+}
+
+func TestGraph_Enhanced(t *testing.T) {
+	g, err := depgraph.New(NewEnhancedGraph(), EnhancedTokens())
+	if err != nil {
+		t.Fatal(err)
+	}
+	man, left, slept := N(2), N(4), N(5)
+	manCopy := depgraph.NodeID{Index: 2, Copy: 1}
+	empty := depgraph.NodeID{Index: 5, Empty: 1}
+
+	t.Run("Nodes", func(t *testing.T) {
+		want := []depgraph.NodeID{N(1), man, manCopy, N(3), left, slept, empty}
+		if nodes := g.Nodes(); !slices.Equal(nodes, want) {
+			t.Errorf("got %v; want %v", nodes, want)
+		}
+	})
+	t.Run("Head", func(t *testing.T) {
+		// The extra edge 4 -> 2 precedes the edge 5 -> 2 in the message.
+		if head, dep, ok := g.Head(man); !ok || head != slept || dep != "nsubj" {
+			t.Errorf("got %v, %q, %t; want 5, \"nsubj\", true", head, dep, ok)
+		}
+		if edges := g.HeadEdges(man); len(edges) != 2 {
+			t.Errorf("got %d head edge(s); want 2", len(edges))
+		}
+	})
+	t.Run("Children", func(t *testing.T) {
+		if children := g.Children(man, "acl"); !slices.Equal(children, Ns(4)) {
+			t.Errorf("got %v; want [4]", children)
+		}
+		if children := g.Children(man, "ac"); len(children) != 0 {
+			t.Errorf("got %v; want none", children)
+		}
+		if edges := g.ChildEdges(slept, "obj"); len(edges) != 1 ||
+			edges[0].Target != manCopy || !edges[0].IsExtra {
+			t.Errorf("got %v; want one extra edge to 2'1", edges)
+		}
+	})
+	t.Run("Ancestors", func(t *testing.T) {
+		if a := g.Ancestors(man); !slices.Equal(a, Ns(4, 5)) {
+			t.Errorf("got %v; want [4 5]", a)
+		}
+		if a := g.Ancestors(left); !slices.Equal(a, Ns(2, 5)) {
+			t.Errorf("got %v; want [2 5]", a)
+		}
+	})
+	t.Run("Subtree", func(t *testing.T) {
+		if s := g.Subtree(man); !slices.Equal(s, Ns(1, 2, 4)) {
+			t.Errorf("got %v; want [1 2 4]", s)
+		}
+		if begin, end, ok := g.SubtreeSpan(man); !ok || begin != 0 || end != 4 {
+			t.Errorf("got [%d, %d), %t; want [0, 4), true", begin, end, ok)
+		}
+		if s := g.Subtree(N(9)); s != nil {
+			t.Errorf("got %v for nonexistent node; want nil", s)
+		}
+	})
+	t.Run("ShortestPath", func(t *testing.T) {
+		nodes, edges, ok := g.ShortestPath(N(1), N(3))
+		if !ok || !slices.Equal(nodes, Ns(1, 2, 3)) || len(edges) != 2 {
+			t.Errorf("got %v, %v, %t; want [1 2 3]", nodes, edges, ok)
+		}
+		nodes, edges, ok = g.ShortestPath(left, left)
+		if !ok || !slices.Equal(nodes, Ns(4)) || len(edges) != 0 {
+			t.Errorf("got %v, %v, %t; want [4], [], true", nodes, edges, ok)
+		}
+		if _, _, ok = g.ShortestPath(left, N(9)); ok {
+			t.Error("got ok true for nonexistent node")
+		}
+	})
+	t.Run("Walk", func(t *testing.T) {
+		depths := make(map[depgraph.NodeID]int)
+		g.Walk(func(n depgraph.NodeID, depth int) bool {
+			if _, ok := depths[n]; ok {
+				t.Errorf("visited %v twice", n)
+			}
+			depths[n] = depth
+			return true
+		})
+		want := map[depgraph.NodeID]int{
+			slept: 0, man: 1, manCopy: 1, empty: 1, N(1): 2, N(3): 2, left: 2,
+		}
+		if len(depths) != len(want) {
+			t.Errorf("visited %d node(s); want %d", len(depths), len(want))
+		}
+		for n, d := range want {
+			if depths[n] != d {
+				t.Errorf("got depth %d for %v; want %d", depths[n], n, d)
+			}
+		}
+		var count int
+		g.Walk(func(depgraph.NodeID, int) bool {
+			count++
+			return count < 2
+		})
+		if count != 2 {
+			t.Errorf("visited %d node(s) after stopping; want 2", count)
+		}
+	})
+	t.Run("Token", func(t *testing.T) {
+		if tok := g.Token(manCopy); tok.GetWord() != "man" {
+			t.Errorf("got %q for the copy node; want \"man\"", tok.GetWord())
+		}
+		if tok := g.Token(empty); tok != nil {
+			t.Errorf("got %q for the empty node; want nil", tok.GetWord())
+		}
+		if words := g.Words([]depgraph.NodeID{slept, empty}); words != "slept 5.1" {
+			t.Errorf("got %q; want \"slept 5.1\"", words)
+		}
+	})
+}
+
+func TestNew_Invalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		g    *pb.DependencyGraph
+	}{
+		{"nil", nil},
+		{"nonexistent source", &pb.DependencyGraph{
+			Node: []*pb.DependencyGraph_Node{PbNode(1)},
+			Edge: []*pb.DependencyGraph_Edge{PbEdge(2, 1, "dep")},
+		}},
+		{"nonexistent target", &pb.DependencyGraph{
+			Node: []*pb.DependencyGraph_Node{PbNode(1)},
+			Edge: []*pb.DependencyGraph_Edge{PbEdge(1, 2, "dep")},
+		}},
+		{"root node out of range", &pb.DependencyGraph{
+			Node:     []*pb.DependencyGraph_Node{PbNode(1)},
+			RootNode: []uint32{1},
+		}},
+		{"nonexistent root", &pb.DependencyGraph{
+			Node: []*pb.DependencyGraph_Node{PbNode(1)},
+			Root: []uint32{2},
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := depgraph.New(tc.g, nil); err == nil {
+				t.Error("got nil error")
+			}
+		})
+	}
+}
+
+func TestKind_String(t *testing.T) {
+	if s := depgraph.EnhancedPlusPlus.String(); s != "enhancedPlusPlusDependencies" {
+		t.Errorf("got %q; want \"enhancedPlusPlusDependencies\"", s)
+	}
+	if s := depgraph.Kind(-1).String(); s != "Kind(-1)" {
+		t.Errorf("got %q; want \"Kind(-1)\"", s)
+	}
+}
+
+// N returns the node ID of the token with the specified 1-based index.
+func N(index uint32) depgraph.NodeID {
+	return depgraph.NodeID{Index: index}
+}
+
+// Ns returns the node IDs of the tokens with the specified 1-based indexes.
+func Ns(indexes ...uint32) []depgraph.NodeID {
+	ids := make([]depgraph.NodeID, len(indexes))
+	for i := range indexes {
+		ids[i] = N(indexes[i])
+	}
+	return ids
+}
+
+// PbNode returns a dependency graph node in sentence 0.
+func PbNode(index uint32) *pb.DependencyGraph_Node {
+	return &pb.DependencyGraph_Node{
+		SentenceIndex: new(uint32),
+		Index:         &index,
+	}
+}
+
+// PbEdge returns a non-extra dependency graph edge.
+func PbEdge(source, target uint32, dep string) *pb.DependencyGraph_Edge {
+	return &pb.DependencyGraph_Edge{
+		Source: &source,
+		Target: &target,
+		Dep:    &dep,
+	}
+}
+
+// EnhancedTokens returns the tokens of the sentence
+// "the man who left slept".
+func EnhancedTokens() []*pb.Token {
+	words := []string{"the", "man", "who", "left", "slept"}
+	tokens := make([]*pb.Token, len(words))
+	for i := range words {
+		tokens[i] = &pb.Token{Word: &words[i]}
+	}
+	return tokens
+}
+
+// NewEnhancedGraph returns an enhanced dependency graph of
+// the sentence "the man who left slept",
+// with a cycle (2 -> 4 -> 2), a copy node 2'1, and an empty node 5.1.
+func NewEnhancedGraph() *pb.DependencyGraph {
+	one, extra := uint32(1), true
+	manCopy := PbNode(2)
+	manCopy.CopyAnnotation = &one
+	empty := PbNode(5)
+	empty.EmptyIndex = &one
+
+	leftMan := PbEdge(4, 2, "nsubj")
+	leftMan.IsExtra = &extra
+	manWho := PbEdge(2, 3, "ref")
+	manWho.IsExtra = &extra
+	sleptManCopy := PbEdge(5, 2, "obj")
+	sleptManCopy.TargetCopy, sleptManCopy.IsExtra = &one, &extra
+	sleptEmpty := PbEdge(5, 5, "orphan")
+	sleptEmpty.TargetEmpty = &one
+
+	return &pb.DependencyGraph{
+		Node: []*pb.DependencyGraph_Node{
+			PbNode(5), PbNode(1), PbNode(2), PbNode(3), PbNode(4),
+			manCopy, empty,
+		},
+		Edge: []*pb.DependencyGraph_Edge{
+			leftMan,
+			PbEdge(5, 2, "nsubj"),
+			PbEdge(2, 1, "det"),
+			PbEdge(2, 4, "acl:relcl"),
+			manWho,
+			sleptManCopy,
+			sleptEmpty,
+		},
+		Root:     []uint32{5},
+		RootNode: []uint32{0},
+	}
+}
@@ -0,0 +1,189 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package depgraph
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+)
+
+// Layout parameters of the SVG arc diagram, in pixels.
+const (
+	svgMargin      = 10
+	svgCharWidth   = 8  // approximate width of a character in the monospace font
+	svgColumnPad   = 16 // horizontal padding of a column
+	svgMinColumn   = 40 // minimum width of a column
+	svgLevelHeight = 24 // height of an arc per column it spans
+	svgLineHeight  = 18
+	svgRootHeight  = 24 // height reserved above the highest arc for roots
+)
+
+// svgStyle is the embedded stylesheet of the SVG arc diagram.
+const svgStyle = `<style>
+text{font-family:monospace;font-size:13px;text-anchor:middle}
+.arc{fill:none;stroke:#000;marker-end:url(#arrow)}
+.arc.extra{stroke:#00f;stroke-dasharray:4 3;marker-end:url(#arrow-extra)}
+.dep{font-size:11px}
+.dep.extra{fill:#00f}
+.root{font-weight:bold}
+.pos{fill:#666}
+</style>
+<defs>
+<marker id="arrow" viewBox="0 0 8 8" refX="8" refY="4" markerWidth="8" markerHeight="8" orient="auto"><path d="M0,0L8,4L0,8z"/></marker>
+<marker id="arrow-extra" viewBox="0 0 8 8" refX="8" refY="4" markerWidth="8" markerHeight="8" orient="auto"><path d="M0,0L8,4L0,8z" fill="#00f"/></marker>
+</defs>
+`
+
+// WriteSVG writes the graph as a self-contained SVG arc diagram to w.
+//
+// The nodes are laid out from left to right in token order,
+// each labeled with the word of its token
+// (and the part-of-speech tag if opt.ShowPOS is true),
+// or its node ID if the token is unavailable.
+// Each edge is drawn as an arc above the words,
+// pointing from the head to the dependent.
+// Roots are drawn in bold and pointed to by a vertical arrow "root".
+// Extra edges are drawn as dashed blue arcs.
+//
+// The diagram uses no external resources,
+// so it can be viewed directly in a browser
+// or embedded in an HTML document.
+//
+// If opt is nil, it uses default options.
+func (g *Graph) WriteSVG(w io.Writer, opt *RenderOptions) error {
+	if opt == nil {
+		opt = new(RenderOptions)
+	}
+	n := len(g.nodes)
+	column := make(map[NodeID]int, n)
+	centers := make([]int, n)
+	width := svgMargin
+	for i, id := range g.nodes {
+		column[id] = i
+		colWidth := svgCharWidth*max(
+			utf8.RuneCountInString(g.nodeLabel(id, false, "")),
+			utf8.RuneCountInString(g.posLabel(id, opt.ShowPOS)),
+		) + svgColumnPad
+		colWidth = max(colWidth, svgMinColumn)
+		centers[i] = width + colWidth/2
+		width += colWidth
+	}
+	width += svgMargin
+
+	maxSpan := 1
+	for _, e := range g.edges {
+		maxSpan = max(maxSpan, absInt(column[e.Target]-column[e.Source]))
+	}
+	baseline := svgMargin + svgRootHeight + maxSpan*svgLevelHeight
+	height := baseline + svgLineHeight + svgMargin
+	if opt.ShowPOS {
+		height += svgLineHeight
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %[1]d %[2]d">`+"\n",
+		width, height)
+	if len(opt.Title) > 0 {
+		fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(opt.Title))
+	}
+	b.WriteString(svgStyle)
+	for _, e := range g.edges {
+		s, t := column[e.Source], column[e.Target]
+		writeSVGArc(&b, e, centers[s], centers[t],
+			max(absInt(t-s), 1), baseline)
+	}
+	for _, r := range g.roots {
+		x := centers[column[r]]
+		fmt.Fprintf(&b, `<path class="arc" d="M%d,%d L%d,%d"/>`+"\n",
+			x, svgMargin+svgLineHeight, x, baseline)
+		fmt.Fprintf(&b, `<text class="dep" x="%d" y="%d">root</text>`+"\n",
+			x, svgMargin+svgLineHeight-4)
+	}
+	for i, id := range g.nodes {
+		class := "word"
+		if g.IsRoot(id) {
+			class += " root"
+		}
+		fmt.Fprintf(&b, `<text class="%s" x="%d" y="%d">%s</text>`+"\n",
+			class, centers[i], baseline+svgLineHeight-4,
+			html.EscapeString(g.nodeLabel(id, false, "")))
+		if pos := g.posLabel(id, opt.ShowPOS); len(pos) > 0 {
+			fmt.Fprintf(&b, `<text class="pos" x="%d" y="%d">%s</text>`+"\n",
+				centers[i], baseline+2*svgLineHeight-4, html.EscapeString(pos))
+		}
+	}
+	b.WriteString("</svg>\n")
+	_, err := io.WriteString(w, b.String())
+	return gogoerrors.AutoWrap(err)
+}
+
+// posLabel returns the part-of-speech tag of the node n
+// if showPOS is true and the tag is available.
+// Otherwise, it returns an empty string.
+func (g *Graph) posLabel(n NodeID, showPOS bool) string {
+	if !showPOS {
+		return ""
+	}
+	return g.Token(n).GetPos()
+}
+
+// writeSVGArc writes the arc of the edge e from the column centered at
+// the source x-coordinate sx to that centered at the target x-coordinate tx.
+//
+// span is the number of columns the arc spans (at least 1),
+// which determines the height of the arc.
+func writeSVGArc(b *strings.Builder, e Edge, sx, tx, span, baseline int) {
+	class := "arc"
+	labelClass := "dep"
+	if e.IsExtra {
+		class += " extra"
+		labelClass += " extra"
+	}
+	// Shift the endpoints slightly so that
+	// the incoming and outgoing arcs of a word do not overlap.
+	switch {
+	case sx < tx:
+		sx, tx = sx+4, tx-2
+	case sx > tx:
+		sx, tx = sx-4, tx+2
+	default:
+		// Self-loop: every node has its own column,
+		// so the source and the target are the same node.
+		sx, tx = sx-6, sx+6
+	}
+	h := span * svgLevelHeight * 4 / 3 // the top of a cubic Bézier curve is at 3/4 h
+	labelY := baseline - span*svgLevelHeight - 2
+	fmt.Fprintf(b, `<path class="%s" d="M%d,%d C%d,%d %d,%d %d,%d"/>`+"\n",
+		class, sx, baseline, sx, baseline-h, tx, baseline-h, tx, baseline)
+	fmt.Fprintf(b, `<text class="%s" x="%d" y="%d">%s</text>`+"\n",
+		labelClass, (sx+tx)/2, labelY, html.EscapeString(e.Dep))
+}
+
+// absInt returns the absolute value of x.
+func absInt(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
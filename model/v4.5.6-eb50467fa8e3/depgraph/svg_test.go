@@ -0,0 +1,90 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package depgraph_test
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/depgraph"
+)
+
+func TestGraph_WriteSVG(t *testing.T) {
+	g, err := depgraph.New(NewEnhancedGraph(), EnhancedTokens())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	err = g.WriteSVG(&b, &depgraph.RenderOptions{Title: "<the man>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svg := b.String()
+	var numArc, numExtraArc, numRoot int
+	var title string
+	dec := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatalf("invalid SVG: %v\n%s", err, svg)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "title":
+			if err = dec.DecodeElement(&title, &start); err != nil {
+				t.Fatal(err)
+			}
+		case "path", "text":
+			for _, attr := range start.Attr {
+				if attr.Name.Local != "class" {
+					continue
+				}
+				switch attr.Value {
+				case "arc":
+					numArc++
+				case "arc extra":
+					numExtraArc++
+				case "word root":
+					numRoot++
+				}
+			}
+		}
+	}
+	if title != "<the man>" {
+		t.Errorf("got title %q; want %q", title, "<the man>")
+	}
+	// 4 non-extra edges and 1 root arrow.
+	if numArc != 5 {
+		t.Errorf("got %d arc(s); want 5", numArc)
+	}
+	if numExtraArc != 3 {
+		t.Errorf("got %d extra arc(s); want 3", numExtraArc)
+	}
+	if numRoot != 1 {
+		t.Errorf("got %d root word(s); want 1", numRoot)
+	}
+}
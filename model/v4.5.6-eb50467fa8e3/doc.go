@@ -20,4 +20,19 @@
 // with commit hash eb50467fa8e3f44b5aee53394231d2f68e6d130b.
 //
 // See its subpackage pb for the structures.
+//
+// The other subpackages provide utilities working on these structures:
+//   - depgraph: navigation and rendering (DOT and SVG) of dependency graphs.
+//   - coref: resolution of coreference chains to text.
+//   - mention: extraction of entity mentions as typed spans.
+//   - textoffset: reconstruction of the original text and alignment of
+//     token offsets with it.
+//   - parsetree: conversion of constituency parse trees between
+//     the Penn Treebank format, ParseTree, and FlattenedParseTree,
+//     and evalb-style evaluation of them.
+//   - docutil: slicing, merging, and validation of documents,
+//     and chunked annotation of long texts.
+//   - docdiff: comparison of the annotations of two documents
+//     over the same text.
+//   - evaluation: scoring of annotations against gold annotations.
 package v4_5_6_eb50467fa8e3
@@ -0,0 +1,657 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package docdiff
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/coref"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/depgraph"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/parsetree"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/textoffset"
+)
+
+// Kind is the kind of annotation that differs between two documents.
+type Kind int8
+
+const (
+	Tokenization  Kind = iota // token boundaries
+	SentenceSplit             // sentence boundaries
+	POS                       // part-of-speech tags (the field pos of Token)
+	Lemma                     // lemmas (the field lemma of Token)
+	NER                       // named entity tags (the field ner of Token)
+	Dependency                // dependency edges
+	ParseTree                 // constituency parse trees
+	Coref                     // coreference clusters
+
+	numKinds // the number of kinds, for internal use only
+)
+
+// kindNames are the names of the kinds, indexed by Kind.
+var kindNames = [numKinds]string{
+	"tokenization",
+	"sentenceSplit",
+	"pos",
+	"lemma",
+	"ner",
+	"dependency",
+	"parseTree",
+	"coref",
+}
+
+// String returns the name of the kind, such as "tokenization" and "pos".
+func (k Kind) String() string {
+	if k >= 0 && k < numKinds {
+		return kindNames[k]
+	}
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// MarshalText returns the name of the kind (see method String).
+//
+// It reports an error if k is unknown.
+func (k Kind) MarshalText() ([]byte, error) {
+	if k < 0 || k >= numKinds {
+		return nil, gogoerrors.AutoNew(fmt.Sprintf("unknown kind %d", k))
+	}
+	return []byte(kindNames[k]), nil
+}
+
+// UnmarshalText sets k to the kind with the specified name
+// (see method String).
+//
+// It reports an error if the name is unknown.
+func (k *Kind) UnmarshalText(text []byte) error {
+	i := slices.Index(kindNames[:], string(text))
+	if i < 0 {
+		return gogoerrors.AutoNew(fmt.Sprintf("unknown kind %q", text))
+	}
+	*k = Kind(i)
+	return nil
+}
+
+// Difference is a difference between two documents
+// at a span of their common text.
+type Difference struct {
+	// Kind is the kind of annotation that differs.
+	Kind Kind `json:"kind"`
+
+	// BeginChar and EndChar are the character offsets of the span
+	// in the text, including BeginChar and excluding EndChar.
+	//
+	// The span is the token for token-level annotations,
+	// the sentence for parse trees,
+	// the first token of a sentence for sentence splits,
+	// the first mention of a cluster for coreference clusters,
+	// and the tokens with different boundaries for tokenization.
+	//
+	// Note that CoreNLP counts characters in UTF-16 code units.
+	BeginChar uint32 `json:"beginChar"`
+	EndChar   uint32 `json:"endChar"`
+
+	// Text is the text of the span.
+	Text string `json:"text"`
+
+	// Old and New describe the annotation in the old and new documents,
+	// respectively. An empty string means the annotation is absent.
+	//
+	// For tokenization, they are the words of the tokens,
+	// separated by " | ".
+	// For sentence splits, they are "sentence break" and "no sentence break".
+	// For dependency edges, they are the incoming edges of the token
+	// in the form "dep(headWord@headBeginChar)", separated by ", ",
+	// with the head "ROOT" for the roots.
+	// For parse trees, they are the trees in the Penn Treebank format.
+	// For coreference clusters, they are the mentions of the clusters
+	// in the form "\"text\"@beginChar", separated by ", ", within braces.
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// Options are the options for Compare.
+type Options struct {
+	// Kinds are the kinds of annotations to compare.
+	//
+	// Default: nil (empty, to compare all kinds)
+	Kinds []Kind `json:"kinds,omitempty"`
+
+	// DependencyKind is the kind of dependency graphs to compare.
+	//
+	// Default: depgraph.Basic
+	DependencyKind depgraph.Kind `json:"dependencyKind,omitempty"`
+
+	// onlyKeyedLiterals forces others to construct Options
+	// only with the keyed literals, so future additions to it
+	// will not violate compatibility.
+	onlyKeyedLiterals struct{}
+}
+
+var _ = Options{}.onlyKeyedLiterals // to suppress "field `onlyKeyedLiterals` is unused (unused)"
+
+// Compare reports the differences between the documents oldDoc and newDoc,
+// which must be annotated over the same text.
+//
+// The tokens of the two documents are aligned by their character offsets.
+// Tokens with the same offsets are compared for
+// their part-of-speech tags, lemmas, named entity tags,
+// and incoming dependency edges.
+// Tokens without a counterpart are reported as tokenization differences.
+// Sentences with the same span are compared for their parse trees.
+// Coreference clusters are paired by their shared mentions
+// and compared for their mention spans.
+//
+// The differences in the report are sorted by their spans and kinds.
+//
+// If opt is nil, it uses default options.
+//
+// It reports an error if either document is nil or invalid
+// (see textoffset.NewAligner, depgraph.New, and coref.Chains),
+// or the two documents have different texts.
+func Compare(oldDoc, newDoc *pb.Document, opt *Options) (*Report, error) {
+	if opt == nil {
+		opt = new(Options)
+	}
+	d := &differ{depKind: opt.DependencyKind}
+	if len(opt.Kinds) == 0 {
+		for k := range d.kinds {
+			d.kinds[k] = true
+		}
+	} else {
+		for _, k := range opt.Kinds {
+			if k < 0 || k >= numKinds {
+				return nil, gogoerrors.AutoNew(fmt.Sprintf("unknown kind %d", k))
+			}
+			d.kinds[k] = true
+		}
+	}
+	var err error
+	d.old, err = newView(oldDoc, "old")
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	d.new, err = newView(newDoc, "new")
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	if d.old.aligner.Text() != d.new.aligner.Text() {
+		return nil, gogoerrors.AutoNew("the documents have different texts")
+	}
+	d.index = d.old.aligner.Index()
+
+	d.alignTokens()
+	if d.kinds[SentenceSplit] {
+		d.compareSentenceSplits()
+	}
+	d.compareTokens()
+	if d.kinds[Dependency] {
+		err = d.compareDependencies()
+		if err != nil {
+			return nil, gogoerrors.AutoWrap(err)
+		}
+	}
+	if d.kinds[ParseTree] {
+		d.compareParseTrees()
+	}
+	if d.kinds[Coref] {
+		err = d.compareCoref()
+		if err != nil {
+			return nil, gogoerrors.AutoWrap(err)
+		}
+	}
+	slices.SortStableFunc(d.diffs, func(a, b Difference) int {
+		if c := cmp.Compare(a.BeginChar, b.BeginChar); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.EndChar, b.EndChar); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Kind, b.Kind)
+	})
+	return &Report{Differences: d.diffs}, nil
+}
+
+// charSpan is a span of the text in characters (UTF-16 code units),
+// including begin and excluding end.
+type charSpan struct {
+	begin, end uint32
+}
+
+// view is one of the two documents under comparison,
+// with its tokens indexed by their positions in the document.
+type view struct {
+	doc     *pb.Document
+	aligner *textoffset.Aligner
+	spans   []charSpan                  // spans[i] is the span of the i-th token
+	pos     map[textoffset.Location]int // pos[loc] is the position of the token at loc
+}
+
+// newView creates a view of doc.
+//
+// name is "old" or "new", used in the error message.
+func newView(doc *pb.Document, name string) (*view, error) {
+	if doc == nil {
+		return nil, gogoerrors.AutoNew(name + " document is nil")
+	}
+	a, err := textoffset.NewAligner(doc)
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	v := &view{
+		doc:     doc,
+		aligner: a,
+		spans:   make([]charSpan, a.NumTokens()),
+		pos:     make(map[textoffset.Location]int, a.NumTokens()),
+	}
+	for i := range v.spans {
+		token := a.Token(i)
+		v.spans[i] = charSpan{
+			begin: token.GetBeginChar(),
+			end:   token.GetEndChar(),
+		}
+		v.pos[a.Location(i)] = i
+	}
+	return v, nil
+}
+
+// sentenceSpan returns the span of the sentence from its first token
+// to its last token.
+//
+// ok is false if the sentence has no token.
+func (v *view) sentenceSpan(sentence *pb.Sentence) (span charSpan, ok bool) {
+	tokens := sentence.GetToken()
+	if len(tokens) == 0 {
+		return charSpan{}, false
+	}
+	return charSpan{
+		begin: tokens[0].GetBeginChar(),
+		end:   tokens[len(tokens)-1].GetEndChar(),
+	}, true
+}
+
+// differ holds the state of Compare.
+type differ struct {
+	old, new *view
+	index    *textoffset.Index
+	kinds    [numKinds]bool
+	depKind  depgraph.Kind
+	pairs    [][2]int // pairs of the positions of the matched old and new tokens
+	diffs    []Difference
+}
+
+// report appends a difference at the specified span.
+func (d *differ) report(kind Kind, span charSpan, oldDesc, newDesc string) {
+	text, _ := d.index.SliceChars(int(span.begin), int(span.end))
+	d.diffs = append(d.diffs, Difference{
+		Kind:      kind,
+		BeginChar: span.begin,
+		EndChar:   span.end,
+		Text:      text,
+		Old:       oldDesc,
+		New:       newDesc,
+	})
+}
+
+// alignTokens pairs the old and new tokens with the same spans
+// and reports the others as tokenization differences.
+func (d *differ) alignTokens() {
+	oldSpans, newSpans := d.old.spans, d.new.spans
+	var i, j int
+	for i < len(oldSpans) || j < len(newSpans) {
+		if i < len(oldSpans) && j < len(newSpans) &&
+			oldSpans[i] == newSpans[j] {
+			d.pairs = append(d.pairs, [2]int{i, j})
+			i, j = i+1, j+1
+			continue
+		}
+		// Collect the old and new tokens overlapping with one another,
+		// starting from the one that begins first.
+		var region charSpan
+		if j >= len(newSpans) ||
+			i < len(oldSpans) && oldSpans[i].begin <= newSpans[j].begin {
+			region = oldSpans[i]
+		} else {
+			region = newSpans[j]
+		}
+		oldBegin, newBegin := i, j
+		for {
+			if i < len(oldSpans) && oldSpans[i].begin < region.end {
+				region.end = max(region.end, oldSpans[i].end)
+				i++
+			} else if j < len(newSpans) && newSpans[j].begin < region.end {
+				region.end = max(region.end, newSpans[j].end)
+				j++
+			} else {
+				break
+			}
+		}
+		if i == oldBegin && j == newBegin {
+			// Empty tokens at the same position; consume them one by one.
+			if i < len(oldSpans) && oldSpans[i] == region {
+				i++
+			} else {
+				j++
+			}
+		}
+		if d.kinds[Tokenization] {
+			d.report(Tokenization, region,
+				d.old.words(oldBegin, i), d.new.words(newBegin, j))
+		}
+	}
+}
+
+// words returns the words of the tokens at positions [begin, end),
+// separated by " | ".
+func (v *view) words(begin, end int) string {
+	words := make([]string, end-begin)
+	for i := range words {
+		words[i] = v.aligner.Token(begin + i).GetWord()
+	}
+	return strings.Join(words, " | ")
+}
+
+// compareSentenceSplits reports the sentence boundaries
+// present in only one of the documents.
+func (d *differ) compareSentenceSplits() {
+	oldBreaks, newBreaks := d.old.sentenceBreaks(), d.new.sentenceBreaks()
+	var i, j int
+	for i < len(oldBreaks) || j < len(newBreaks) {
+		switch {
+		case j >= len(newBreaks) ||
+			i < len(oldBreaks) && oldBreaks[i].begin < newBreaks[j].begin:
+			d.report(SentenceSplit, oldBreaks[i],
+				"sentence break", "no sentence break")
+			i++
+		case i >= len(oldBreaks) || newBreaks[j].begin < oldBreaks[i].begin:
+			d.report(SentenceSplit, newBreaks[j],
+				"no sentence break", "sentence break")
+			j++
+		default:
+			i, j = i+1, j+1
+		}
+	}
+}
+
+// sentenceBreaks returns the spans of the first tokens of the sentences,
+// except for the first sentence.
+func (v *view) sentenceBreaks() []charSpan {
+	var breaks []charSpan
+	first := true
+	for _, sentence := range v.doc.GetSentence() {
+		tokens := sentence.GetToken()
+		if len(tokens) == 0 {
+			continue
+		}
+		if first {
+			first = false
+			continue
+		}
+		breaks = append(breaks, charSpan{
+			begin: tokens[0].GetBeginChar(),
+			end:   tokens[0].GetEndChar(),
+		})
+	}
+	return breaks
+}
+
+// compareTokens reports the differences in the part-of-speech tags,
+// lemmas, and named entity tags of the matched tokens.
+func (d *differ) compareTokens() {
+	fields := [...]struct {
+		kind Kind
+		get  func(token *pb.Token) string
+	}{
+		{POS, (*pb.Token).GetPos},
+		{Lemma, (*pb.Token).GetLemma},
+		{NER, (*pb.Token).GetNer},
+	}
+	for _, pair := range d.pairs {
+		oldToken, newToken := d.old.aligner.Token(pair[0]), d.new.aligner.Token(pair[1])
+		for _, f := range fields {
+			if !d.kinds[f.kind] {
+				continue
+			}
+			oldValue, newValue := f.get(oldToken), f.get(newToken)
+			if oldValue != newValue {
+				d.report(f.kind, d.old.spans[pair[0]], oldValue, newValue)
+			}
+		}
+	}
+}
+
+// compareDependencies reports the differences in the incoming
+// dependency edges of the matched tokens.
+func (d *differ) compareDependencies() error {
+	oldHeads, err := d.old.heads(d.depKind)
+	if err != nil {
+		return gogoerrors.AutoWrap(err)
+	}
+	newHeads, err := d.new.heads(d.depKind)
+	if err != nil {
+		return gogoerrors.AutoWrap(err)
+	}
+	for _, pair := range d.pairs {
+		oldDesc := strings.Join(oldHeads[pair[0]], ", ")
+		newDesc := strings.Join(newHeads[pair[1]], ", ")
+		if oldDesc != newDesc {
+			d.report(Dependency, d.old.spans[pair[0]], oldDesc, newDesc)
+		}
+	}
+	return nil
+}
+
+// heads returns the sorted descriptions of the incoming edges
+// of each token in the dependency graphs of the specified kind,
+// indexed by the token positions in the document.
+//
+// Sentences without such a graph are skipped.
+// Edges to copy nodes or empty nodes are ignored.
+func (v *view) heads(kind depgraph.Kind) ([][]string, error) {
+	heads := make([][]string, len(v.spans))
+	for sentIdx, sentence := range v.doc.GetSentence() {
+		if kind.Of(sentence) == nil {
+			continue
+		}
+		g, err := depgraph.FromSentence(sentence, kind)
+		if err != nil {
+			return nil, gogoerrors.AutoWrap(err)
+		}
+		add := func(target depgraph.NodeID, desc string) {
+			if target.Copy != 0 || target.Empty != 0 || target.Index == 0 {
+				return
+			}
+			i, ok := v.pos[textoffset.Location{
+				Sentence: sentIdx,
+				Token:    int(target.Index) - 1,
+			}]
+			if ok {
+				heads[i] = append(heads[i], desc)
+			}
+		}
+		for _, root := range g.Roots() {
+			add(root, "root(ROOT)")
+		}
+		for _, e := range g.Edges() {
+			head := e.Source.String()
+			if token := g.Token(e.Source); token != nil {
+				head = token.GetWord() + "@" +
+					strconv.FormatUint(uint64(token.GetBeginChar()), 10)
+				if e.Source.Copy != 0 {
+					head += "'" + strconv.FormatUint(uint64(e.Source.Copy), 10)
+				}
+			}
+			add(e.Target, e.Dep+"("+head+")")
+		}
+	}
+	for _, h := range heads {
+		slices.Sort(h)
+	}
+	return heads, nil
+}
+
+// compareParseTrees reports the differences in the parse trees of
+// the sentences with the same span.
+func (d *differ) compareParseTrees() {
+	newSentences := make(map[charSpan]*pb.Sentence, len(d.new.doc.GetSentence()))
+	for _, sentence := range d.new.doc.GetSentence() {
+		if span, ok := d.new.sentenceSpan(sentence); ok {
+			newSentences[span] = sentence
+		}
+	}
+	for _, oldSentence := range d.old.doc.GetSentence() {
+		span, ok := d.old.sentenceSpan(oldSentence)
+		if !ok {
+			continue
+		}
+		newSentence, ok := newSentences[span]
+		if !ok {
+			continue
+		}
+		oldTree := parsetree.String(oldSentence.GetParseTree())
+		newTree := parsetree.String(newSentence.GetParseTree())
+		if oldTree != newTree {
+			d.report(ParseTree, span, oldTree, newTree)
+		}
+	}
+}
+
+// cluster is a coreference cluster as a list of mentions sorted by span.
+type cluster []coref.Mention
+
+// clusters returns the coreference clusters of the document.
+func (v *view) clusters() ([]cluster, error) {
+	chains, err := coref.Chains(v.doc)
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	clusters := make([]cluster, 0, len(chains))
+	for _, chain := range chains {
+		if len(chain.Mentions) == 0 {
+			continue
+		}
+		c := slices.Clone(cluster(chain.Mentions))
+		slices.SortFunc(c, func(a, b coref.Mention) int {
+			return compareSpan(mentionSpan(a), mentionSpan(b))
+		})
+		clusters = append(clusters, c)
+	}
+	// Sort the clusters by their first mentions for a stable pairing.
+	slices.SortStableFunc(clusters, func(a, b cluster) int {
+		return compareSpan(mentionSpan(a[0]), mentionSpan(b[0]))
+	})
+	return clusters, nil
+}
+
+// String returns the description of the cluster in the form
+// {"text"@beginChar, ...}.
+func (c cluster) String() string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, m := range c {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(strconv.Quote(m.Text))
+		b.WriteByte('@')
+		b.WriteString(strconv.FormatUint(uint64(m.BeginChar), 10))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// shared returns the number of mentions with the same spans
+// in the clusters c and other.
+func (c cluster) shared(other cluster) int {
+	var n, i, j int
+	for i < len(c) && j < len(other) {
+		switch compareSpan(mentionSpan(c[i]), mentionSpan(other[j])) {
+		case -1:
+			i++
+		case 1:
+			j++
+		default:
+			n++
+			i, j = i+1, j+1
+		}
+	}
+	return n
+}
+
+// compareCoref reports the differences in the coreference clusters.
+//
+// Each old cluster is paired with the unpaired new cluster
+// sharing the most mentions with it.
+// Paired clusters with different mentions are reported together,
+// and unpaired clusters are reported alone.
+func (d *differ) compareCoref() error {
+	oldClusters, err := d.old.clusters()
+	if err != nil {
+		return gogoerrors.AutoWrap(err)
+	}
+	newClusters, err := d.new.clusters()
+	if err != nil {
+		return gogoerrors.AutoWrap(err)
+	}
+	paired := make([]bool, len(newClusters))
+	for _, oc := range oldClusters {
+		best, bestShared := -1, 0
+		for j, nc := range newClusters {
+			if paired[j] {
+				continue
+			}
+			if n := oc.shared(nc); n > bestShared {
+				best, bestShared = j, n
+			}
+		}
+		if best < 0 {
+			d.report(Coref, mentionSpan(oc[0]), oc.String(), "")
+			continue
+		}
+		paired[best] = true
+		nc := newClusters[best]
+		if bestShared != len(oc) || bestShared != len(nc) {
+			d.report(Coref, mentionSpan(oc[0]), oc.String(), nc.String())
+		}
+	}
+	for j, nc := range newClusters {
+		if !paired[j] {
+			d.report(Coref, mentionSpan(nc[0]), "", nc.String())
+		}
+	}
+	return nil
+}
+
+// mentionSpan returns the span of the coreference mention.
+func mentionSpan(m coref.Mention) charSpan {
+	return charSpan{begin: m.BeginChar, end: m.EndChar}
+}
+
+// compareSpan compares two spans by their beginnings and then their ends.
+func compareSpan(a, b charSpan) int {
+	if c := cmp.Compare(a.begin, b.begin); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.end, b.end)
+}
@@ -0,0 +1,331 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package docdiff_test
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/docdiff"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/internal/testdoc"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/parsetree"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+// Text is the text of the document created by NewDocument.
+//
+// The character offsets of the tokens are:
+// Zoe [0,3), met [4,7), Tom [8,11), . [11,12),
+// She [13,16), likes [17,22), him [23,26), . [26,27).
+const Text = "Zoe met Tom. She likes him."
+
+func TestCompare(t *testing.T) {
+	testCases := []struct {
+		name   string
+		mutate func(doc *pb.Document)
+		opt    *docdiff.Options
+		want   []docdiff.Difference
+	}{
+		{
+			name:   "identical",
+			mutate: func(doc *pb.Document) {},
+		},
+		{
+			name: "tokenization",
+			mutate: func(doc *pb.Document) {
+				testdoc.JoinTokens(doc.Sentence[0], 2) // Tom .
+			},
+			want: []docdiff.Difference{
+				{Kind: docdiff.Coref, BeginChar: 8, EndChar: 11, Text: "Tom",
+					Old: `{"Tom"@8, "him"@23}`, New: `{"Tom."@8, "him"@23}`},
+				{Kind: docdiff.Tokenization, BeginChar: 8, EndChar: 12,
+					Text: "Tom.", Old: "Tom | .", New: "Tom."},
+			},
+		},
+		{
+			name: "missing token",
+			mutate: func(doc *pb.Document) {
+				tokens := doc.Sentence[1].Token
+				tokens[2].After = proto.String(".")
+				doc.Sentence[1].Token = tokens[:3]
+			},
+			opt: &docdiff.Options{Kinds: []docdiff.Kind{docdiff.Tokenization}},
+			want: []docdiff.Difference{
+				{Kind: docdiff.Tokenization, BeginChar: 26, EndChar: 27,
+					Text: ".", Old: ".", New: ""},
+			},
+		},
+		{
+			name: "sentence split",
+			mutate: func(doc *pb.Document) {
+				doc.Sentence[0].Token = append(
+					doc.Sentence[0].Token, doc.Sentence[1].Token...)
+				doc.Sentence = doc.Sentence[:1]
+			},
+			opt: &docdiff.Options{Kinds: []docdiff.Kind{docdiff.SentenceSplit}},
+			want: []docdiff.Difference{
+				{Kind: docdiff.SentenceSplit, BeginChar: 13, EndChar: 16,
+					Text: "She", Old: "sentence break", New: "no sentence break"},
+			},
+		},
+		{
+			name: "token attributes",
+			mutate: func(doc *pb.Document) {
+				doc.Sentence[0].Token[1].Lemma = proto.String("met")
+				doc.Sentence[0].Token[2].Ner = proto.String("LOCATION")
+				doc.Sentence[1].Token[1].Pos = proto.String("NNS")
+			},
+			want: []docdiff.Difference{
+				{Kind: docdiff.Lemma, BeginChar: 4, EndChar: 7,
+					Text: "met", Old: "meet", New: "met"},
+				{Kind: docdiff.NER, BeginChar: 8, EndChar: 11,
+					Text: "Tom", Old: "PERSON", New: "LOCATION"},
+				{Kind: docdiff.POS, BeginChar: 17, EndChar: 22,
+					Text: "likes", Old: "VBZ", New: "NNS"},
+			},
+		},
+		{
+			name: "kinds",
+			mutate: func(doc *pb.Document) {
+				doc.Sentence[0].Token[1].Lemma = proto.String("met")
+				doc.Sentence[1].Token[1].Pos = proto.String("NNS")
+			},
+			opt: &docdiff.Options{Kinds: []docdiff.Kind{docdiff.POS}},
+			want: []docdiff.Difference{
+				{Kind: docdiff.POS, BeginChar: 17, EndChar: 22,
+					Text: "likes", Old: "VBZ", New: "NNS"},
+			},
+		},
+		{
+			name: "dependency",
+			mutate: func(doc *pb.Document) {
+				g := doc.Sentence[1].BasicDependencies
+				g.Edge[1].Source = proto.Uint32(1) // obj(likes, him) -> obj(She, him)
+				g.Edge[2].Dep = proto.String("punct:period")
+			},
+			want: []docdiff.Difference{
+				{Kind: docdiff.Dependency, BeginChar: 23, EndChar: 26,
+					Text: "him", Old: "obj(likes@17)", New: "obj(She@13)"},
+				{Kind: docdiff.Dependency, BeginChar: 26, EndChar: 27,
+					Text: ".", Old: "punct(likes@17)", New: "punct:period(likes@17)"},
+			},
+		},
+		{
+			name: "dependency root",
+			mutate: func(doc *pb.Document) {
+				doc.Sentence[0].BasicDependencies.Root = []uint32{1}
+			},
+			want: []docdiff.Difference{
+				{Kind: docdiff.Dependency, BeginChar: 0, EndChar: 3,
+					Text: "Zoe", Old: "nsubj(met@4)", New: "nsubj(met@4), root(ROOT)"},
+				{Kind: docdiff.Dependency, BeginChar: 4, EndChar: 7,
+					Text: "met", Old: "root(ROOT)", New: ""},
+			},
+		},
+		{
+			name: "parse tree",
+			mutate: func(doc *pb.Document) {
+				doc.Sentence[0].ParseTree = mustParse(
+					"(ROOT (S (NP (NNP Zoe)) (VP (VBD met) (NP (NNP Tom))) (. .)))")
+			},
+			want: []docdiff.Difference{
+				{Kind: docdiff.ParseTree, BeginChar: 0, EndChar: 12,
+					Text: "Zoe met Tom.",
+					Old:  "(ROOT (S (NP (NNP Zoe)) (VP (VBD met) (NP (NNP Tom)) (. .))))",
+					New:  "(ROOT (S (NP (NNP Zoe)) (VP (VBD met) (NP (NNP Tom))) (. .)))"},
+			},
+		},
+		{
+			name: "coref changed",
+			mutate: func(doc *pb.Document) {
+				him := doc.CorefChain[1].Mention[1]
+				doc.CorefChain[0].Mention = append(doc.CorefChain[0].Mention, him)
+				doc.CorefChain[1].Mention = doc.CorefChain[1].Mention[:1]
+			},
+			want: []docdiff.Difference{
+				{Kind: docdiff.Coref, BeginChar: 0, EndChar: 3, Text: "Zoe",
+					Old: `{"Zoe"@0, "She"@13}`,
+					New: `{"Zoe"@0, "She"@13, "him"@23}`},
+				{Kind: docdiff.Coref, BeginChar: 8, EndChar: 11, Text: "Tom",
+					Old: `{"Tom"@8, "him"@23}`, New: `{"Tom"@8}`},
+			},
+		},
+		{
+			name: "coref removed and added",
+			mutate: func(doc *pb.Document) {
+				doc.CorefChain[1] = &pb.CorefChain{
+					ChainID:        proto.Int32(6),
+					Representative: proto.Uint32(0),
+					Mention: []*pb.CorefChain_CorefMention{
+						testdoc.NewCorefMention(6, 1, 1),
+					},
+				}
+			},
+			want: []docdiff.Difference{
+				{Kind: docdiff.Coref, BeginChar: 8, EndChar: 11, Text: "Tom",
+					Old: `{"Tom"@8, "him"@23}`, New: ""},
+				{Kind: docdiff.Coref, BeginChar: 17, EndChar: 22, Text: "likes",
+					Old: "", New: `{"likes"@17}`},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			oldDoc, newDoc := NewDocument(), NewDocument()
+			tc.mutate(newDoc)
+			report, err := docdiff.Compare(oldDoc, newDoc, tc.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := report.Differences
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %d difference(s) %v; want %d %v",
+					len(got), got, len(tc.want), tc.want)
+			}
+			for i := range tc.want {
+				if got[i] != tc.want[i] {
+					t.Errorf("difference#%d - got %+v; want %+v",
+						i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCompare_Reversed(t *testing.T) {
+	oldDoc, newDoc := NewDocument(), NewDocument()
+	testdoc.JoinTokens(newDoc.Sentence[1], 0) // She likes
+	newDoc.Sentence[1].Token[0].Pos = proto.String("NN")
+	report, err := docdiff.Compare(newDoc, oldDoc,
+		&docdiff.Options{Kinds: []docdiff.Kind{docdiff.Tokenization, docdiff.POS}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []docdiff.Difference{
+		{Kind: docdiff.Tokenization, BeginChar: 13, EndChar: 22,
+			Text: "She likes", Old: "She likes", New: "She | likes"},
+	}
+	if len(report.Differences) != len(want) {
+		t.Fatalf("got %v; want %v", report.Differences, want)
+	}
+	if report.Differences[0] != want[0] {
+		t.Errorf("got %+v; want %+v", report.Differences[0], want[0])
+	}
+}
+
+func TestCompare_Error(t *testing.T) {
+	doc := NewDocument()
+	other := testdoc.Tokenize("Zoe met Tom. She likes her.")
+	testCases := []struct {
+		name           string
+		oldDoc, newDoc *pb.Document
+		opt            *docdiff.Options
+	}{
+		{"old nil", nil, doc, nil},
+		{"new nil", doc, nil, nil},
+		{"different texts", doc, other, nil},
+		{"unknown kind", doc, doc,
+			&docdiff.Options{Kinds: []docdiff.Kind{docdiff.Kind(-1)}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := docdiff.Compare(tc.oldDoc, tc.newDoc, tc.opt)
+			if err == nil {
+				t.Error("got nil error")
+			}
+		})
+	}
+}
+
+// NewDocument creates a document of Text with part-of-speech tags, lemmas,
+// named entity tags, basic dependencies, parse trees,
+// and coreference chains {Zoe, She} and {Tom, him}.
+func NewDocument() *pb.Document {
+	doc := testdoc.Tokenize(Text)
+	testdoc.SetPOS(doc, 0, "NNP", "VBD", "NNP", ".")
+	testdoc.SetPOS(doc, 1, "PRP", "VBZ", "PRP", ".")
+	testdoc.SetNER(doc, 0, "PERSON", "O", "PERSON", "O")
+	testdoc.SetNER(doc, 1, "O", "O", "O", "O")
+	for _, sentence := range doc.Sentence {
+		for _, token := range sentence.Token {
+			token.Lemma = token.Word
+		}
+	}
+	doc.Sentence[0].Token[1].Lemma = proto.String("meet")
+	doc.Sentence[1].Token[1].Lemma = proto.String("like")
+
+	// Both sentences are: nsubj(2, 1), obj(2, 3), punct(2, 4), root 2.
+	for i, sentence := range doc.Sentence {
+		g := new(pb.DependencyGraph)
+		for j := range sentence.Token {
+			g.Node = append(g.Node, &pb.DependencyGraph_Node{
+				SentenceIndex: proto.Uint32(uint32(i)),
+				Index:         proto.Uint32(uint32(j + 1)),
+			})
+		}
+		for _, e := range []struct {
+			target uint32
+			dep    string
+		}{{1, "nsubj"}, {3, "obj"}, {4, "punct"}} {
+			g.Edge = append(g.Edge, &pb.DependencyGraph_Edge{
+				Source: proto.Uint32(2),
+				Target: proto.Uint32(e.target),
+				Dep:    proto.String(e.dep),
+			})
+		}
+		g.Root = []uint32{2}
+		sentence.BasicDependencies = g
+	}
+	doc.Sentence[0].ParseTree = mustParse(
+		"(ROOT (S (NP (NNP Zoe)) (VP (VBD met) (NP (NNP Tom)) (. .))))")
+	doc.Sentence[1].ParseTree = mustParse(
+		"(ROOT (S (NP (PRP She)) (VP (VBZ likes) (NP (PRP him))) (. .)))")
+
+	doc.CorefChain = []*pb.CorefChain{
+		{
+			ChainID:        proto.Int32(0),
+			Representative: proto.Uint32(0),
+			Mention: []*pb.CorefChain_CorefMention{
+				testdoc.NewCorefMention(0, 0, 0),
+				testdoc.NewCorefMention(2, 1, 0),
+			},
+		},
+		{
+			ChainID:        proto.Int32(1),
+			Representative: proto.Uint32(0),
+			Mention: []*pb.CorefChain_CorefMention{
+				testdoc.NewCorefMention(1, 0, 2),
+				testdoc.NewCorefMention(3, 1, 2),
+			},
+		},
+	}
+	return doc
+}
+
+// mustParse parses the tree in the Penn Treebank format.
+// It panics if the tree is invalid.
+func mustParse(s string) *pb.ParseTree {
+	t, err := parsetree.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
@@ -0,0 +1,35 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package docdiff compares two Stanford CoreNLP 4.5.6 documents
+// annotated over the same text, for example,
+// the outputs of two CoreNLP versions or two pipeline configurations.
+//
+// The function Compare aligns the tokens of the two documents
+// by their character offsets and reports the differences in tokenization,
+// sentence splits, part-of-speech tags, lemmas, named entity tags,
+// dependency edges, constituency parse trees, and coreference clusters.
+// The resulting Report can be written in a human-readable text format
+// by its method WriteText, or in JSON by its method WriteJSON.
+//
+// To compare with the output of another CoreNLP version,
+// decode it into the Document of this version
+// (e.g., by github.com/donyori/gocorenlp/model.DecodeResponseBody).
+// The ProtoBuf messages of different versions are wire-compatible,
+// and the fields unknown to this version are ignored in the comparison.
+package docdiff
@@ -0,0 +1,119 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package docdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+)
+
+// Report is the result of Compare.
+type Report struct {
+	// Differences are the differences between the two documents,
+	// sorted by their spans and kinds.
+	Differences []Difference `json:"differences"`
+}
+
+// Empty reports whether the report has no difference.
+func (r *Report) Empty() bool {
+	return r == nil || len(r.Differences) == 0
+}
+
+// Count returns the number of differences of the specified kind.
+func (r *Report) Count(kind Kind) int {
+	if r == nil {
+		return 0
+	}
+	var n int
+	for i := range r.Differences {
+		if r.Differences[i].Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// Counts returns the number of differences of each kind.
+//
+// Kinds without differences are absent from the returned map.
+func (r *Report) Counts() map[Kind]int {
+	counts := make(map[Kind]int)
+	if r != nil {
+		for i := range r.Differences {
+			counts[r.Differences[i].Kind]++
+		}
+	}
+	return counts
+}
+
+// WriteText writes the report to w in a human-readable text format.
+//
+// Each difference is written on a line in the form
+//
+//	kind [beginChar,endChar) "text": "old" -> "new"
+//
+// followed by a summary line with the number of differences of each kind,
+// or "no differences" if the report is empty.
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+	if r.Empty() {
+		b.WriteString("no differences\n")
+	} else {
+		for _, diff := range r.Differences {
+			_, _ = fmt.Fprintf(&b, "%v [%d,%d) %q: %q -> %q\n", diff.Kind,
+				diff.BeginChar, diff.EndChar, diff.Text, diff.Old, diff.New)
+		}
+		_, _ = fmt.Fprintf(&b, "%d difference(s):", len(r.Differences))
+		counts := r.Counts()
+		sep := " "
+		for k := Kind(0); k < numKinds; k++ {
+			if counts[k] > 0 {
+				_, _ = fmt.Fprintf(&b, "%s%d %v", sep, counts[k], k)
+				sep = ", "
+			}
+		}
+		b.WriteByte('\n')
+	}
+	_, err := io.WriteString(w, b.String())
+	return gogoerrors.AutoWrap(err)
+}
+
+// WriteJSON writes the report to w as an indented JSON object
+// with two fields:
+// "counts", the number of differences of each kind (see method Counts),
+// and "differences", the list of differences.
+func (r *Report) WriteJSON(w io.Writer) error {
+	report := struct {
+		Counts      map[Kind]int `json:"counts"`
+		Differences []Difference `json:"differences"`
+	}{
+		Counts:      r.Counts(),
+		Differences: []Difference{},
+	}
+	if r != nil && r.Differences != nil {
+		report.Differences = r.Differences
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return gogoerrors.AutoWrap(enc.Encode(report))
+}
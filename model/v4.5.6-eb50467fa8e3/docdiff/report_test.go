@@ -0,0 +1,172 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package docdiff_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/docdiff"
+)
+
+func TestReport_WriteText(t *testing.T) {
+	testCases := []struct {
+		name   string
+		report *docdiff.Report
+		want   string
+	}{
+		{"nil", nil, "no differences\n"},
+		{"empty", new(docdiff.Report), "no differences\n"},
+		{
+			"non-empty",
+			&docdiff.Report{Differences: []docdiff.Difference{
+				{Kind: docdiff.Tokenization, BeginChar: 8, EndChar: 12,
+					Text: "Tom.", Old: "Tom | .", New: "Tom."},
+				{Kind: docdiff.POS, BeginChar: 17, EndChar: 22,
+					Text: "likes", Old: "VBZ", New: "NNS"},
+				{Kind: docdiff.POS, BeginChar: 23, EndChar: 26,
+					Text: "him", Old: "PRP", New: ""},
+			}},
+			`tokenization [8,12) "Tom.": "Tom | ." -> "Tom."
+pos [17,22) "likes": "VBZ" -> "NNS"
+pos [23,26) "him": "PRP" -> ""
+3 difference(s): 1 tokenization, 2 pos
+`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := tc.report.WriteText(&b); err != nil {
+				t.Fatal(err)
+			}
+			if got := b.String(); got != tc.want {
+				t.Errorf("got %q; want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReport_WriteJSON(t *testing.T) {
+	oldDoc, newDoc := NewDocument(), NewDocument()
+	newDoc.Sentence[1].Token[1].Pos = nil
+	report, err := docdiff.Compare(oldDoc, newDoc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err = report.WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	const want = `{
+  "counts": {
+    "pos": 1
+  },
+  "differences": [
+    {
+      "kind": "pos",
+      "beginChar": 17,
+      "endChar": 22,
+      "text": "likes",
+      "old": "VBZ",
+      "new": ""
+    }
+  ]
+}
+`
+	if got := b.String(); got != want {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+
+	var decoded struct {
+		Counts      map[docdiff.Kind]int `json:"counts"`
+		Differences []docdiff.Difference `json:"differences"`
+	}
+	if err = json.Unmarshal([]byte(b.String()), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Counts[docdiff.POS] != 1 || len(decoded.Counts) != 1 {
+		t.Errorf("got counts %v", decoded.Counts)
+	}
+	if len(decoded.Differences) != 1 ||
+		decoded.Differences[0] != report.Differences[0] {
+		t.Errorf("got differences %v; want %v",
+			decoded.Differences, report.Differences)
+	}
+}
+
+func TestReport_WriteJSON_Empty(t *testing.T) {
+	var b strings.Builder
+	if err := new(docdiff.Report).WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	const want = "{\n  \"counts\": {},\n  \"differences\": []\n}\n"
+	if got := b.String(); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestKind_MarshalText(t *testing.T) {
+	for k := docdiff.Tokenization; k <= docdiff.Coref; k++ {
+		text, err := k.MarshalText()
+		if err != nil {
+			t.Errorf("%v - %v", k, err)
+			continue
+		}
+		if string(text) != k.String() {
+			t.Errorf("%v - got %q", k, text)
+		}
+		var got docdiff.Kind
+		if err = got.UnmarshalText(text); err != nil {
+			t.Errorf("%v - unmarshal - %v", k, err)
+		} else if got != k {
+			t.Errorf("%v - unmarshal - got %v", k, got)
+		}
+	}
+	if _, err := docdiff.Kind(-1).MarshalText(); err == nil {
+		t.Error("Kind(-1) - got nil error")
+	}
+	var k docdiff.Kind
+	if err := k.UnmarshalText([]byte("unknown")); err == nil {
+		t.Error("unknown - got nil error")
+	}
+}
+
+func TestReport_Count(t *testing.T) {
+	oldDoc, newDoc := NewDocument(), NewDocument()
+	newDoc.Sentence[0].Token[0].Ner = nil
+	newDoc.Sentence[0].Token[2].Ner = nil
+	newDoc.Sentence[1].Token[0].Lemma = nil
+	report, err := docdiff.Compare(oldDoc, newDoc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Empty() {
+		t.Fatal("got empty report")
+	}
+	for _, tc := range []struct {
+		kind docdiff.Kind
+		want int
+	}{{docdiff.NER, 2}, {docdiff.Lemma, 1}, {docdiff.POS, 0}} {
+		if got := report.Count(tc.kind); got != tc.want {
+			t.Errorf("%v - got %d; want %d", tc.kind, got, tc.want)
+		}
+	}
+}
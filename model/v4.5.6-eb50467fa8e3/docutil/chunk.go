@@ -0,0 +1,221 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package docutil
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+
+	"github.com/donyori/gocorenlp/client"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+// DefaultMaxChunkSize is the default maximum size of a chunk in bytes,
+// used by AnnotateChunked.
+const DefaultMaxChunkSize = 1 << 16
+
+// ChunkOptions are options for AnnotateChunked.
+type ChunkOptions struct {
+	// MaxChunkSize is the maximum size of a chunk in bytes.
+	// See SplitText for details.
+	//
+	// A non-positive value is treated as DefaultMaxChunkSize.
+	//
+	// Default: DefaultMaxChunkSize
+	MaxChunkSize int `json:"maxChunkSize,omitempty"`
+
+	// Parallelism is the maximum number of chunks annotated concurrently.
+	//
+	// A value less than 1 is treated as 1,
+	// that is, the chunks are annotated one by one.
+	//
+	// Default: 1
+	Parallelism int `json:"parallelism,omitempty"`
+
+	// onlyKeyedLiterals forces others to construct ChunkOptions
+	// only with the keyed literals, so future additions to it
+	// will not violate compatibility.
+	onlyKeyedLiterals struct{}
+}
+
+var _ = ChunkOptions{}.onlyKeyedLiterals // to suppress "field `onlyKeyedLiterals` is unused (unused)"
+
+// SplitText splits the specified text into chunks
+// of at most maxSize bytes each,
+// whose concatenation is exactly the text.
+//
+// It prefers to split at paragraph boundaries (blank lines),
+// then at sentence boundaries (a sentence-ending punctuation mark
+// followed by whitespace), then at whitespace,
+// and splits inside a word only if there is no whitespace in a chunk.
+// The whitespace at a boundary is kept at the end of the former chunk,
+// as far as the size limit allows.
+// A chunk is never split inside a UTF-8 encoded character,
+// unless maxSize is less than the size of the character,
+// in which case the chunk holds the whole character.
+//
+// It returns nil if text is empty.
+// It returns the whole text as one chunk if maxSize is non-positive.
+func SplitText(text string, maxSize int) []string {
+	if len(text) == 0 {
+		return nil
+	} else if maxSize <= 0 {
+		return []string{text}
+	}
+	var chunks []string
+	for len(text) > maxSize {
+		n := splitPoint(text, maxSize)
+		chunks = append(chunks, text[:n])
+		text = text[n:]
+	}
+	if len(text) > 0 {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
+
+// splitPoint returns the size of the first chunk of text,
+// which is longer than maxSize.
+func splitPoint(text string, maxSize int) int {
+	window := text[:maxSize]
+	// Do not cut a UTF-8 encoded character.
+	for len(window) > 0 && !utf8.RuneStart(text[len(window)]) {
+		window = window[:len(window)-1]
+	}
+	if len(window) == 0 {
+		_, size := utf8.DecodeRuneInString(text)
+		return size
+	}
+
+	var paragraph, sentence, space int
+	for i, r := range window {
+		if !unicode.IsSpace(r) {
+			continue
+		}
+		if i == 0 || unicode.IsSpace(lastRune(window[:i])) {
+			// Not the first whitespace of a run.
+			continue
+		}
+		end := skipSpace(window, i)
+		space = end
+		if isSentenceEnd(lastRune(window[:i])) {
+			sentence = end
+		}
+		if strings.Count(window[i:end], "\n") >= 2 {
+			paragraph = end
+		}
+	}
+	switch {
+	case paragraph > 0:
+		return paragraph
+	case sentence > 0:
+		return sentence
+	case space > 0:
+		return space
+	}
+	return len(window)
+}
+
+// skipSpace returns the index of the first non-whitespace character
+// in s at or after i, or len(s) if no such character.
+func skipSpace(s string, i int) int {
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// lastRune returns the last rune of s, or utf8.RuneError if s is empty.
+func lastRune(s string) rune {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return r
+}
+
+// isSentenceEnd reports whether r is a punctuation mark
+// that ends a sentence.
+func isSentenceEnd(r rune) bool {
+	switch r {
+	case '.', '!', '?', '。', '！', '？':
+		return true
+	}
+	return false
+}
+
+// AnnotateChunked splits the specified text into chunks by SplitText,
+// annotates each chunk with the specified annotators by c,
+// and merges the resulting documents into one by Merge.
+//
+// The merged document has the character, token, and sentence offsets
+// relative to the whole text, and reindexed coreference chains.
+// Note that annotations across chunks, such as a coreference chain
+// with mentions in different chunks, cannot be found.
+//
+// If opt is nil, it uses default options.
+//
+// It reports the first error encountered when annotating the chunks.
+func AnnotateChunked(
+	c client.Client,
+	text, annotators string,
+	opt *ChunkOptions,
+) (*pb.Document, error) {
+	if c == nil {
+		return nil, gogoerrors.AutoNew("client is nil")
+	}
+	maxSize, parallelism := DefaultMaxChunkSize, 1
+	if opt != nil {
+		if opt.MaxChunkSize > 0 {
+			maxSize = opt.MaxChunkSize
+		}
+		parallelism = max(opt.Parallelism, 1)
+	}
+	chunks := SplitText(text, maxSize)
+	docs := make([]*pb.Document, len(chunks))
+	errs := make([]error, len(chunks))
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i := range chunks {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			doc := new(pb.Document)
+			errs[i] = c.AnnotateString(chunks[i], annotators, doc)
+			docs[i] = doc
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, gogoerrors.AutoWrap(err)
+		}
+	}
+	doc, err := Merge(docs...)
+	return doc, gogoerrors.AutoWrap(err)
+}
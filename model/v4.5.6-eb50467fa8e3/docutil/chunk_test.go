@@ -0,0 +1,128 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package docutil_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/client"
+	"github.com/donyori/gocorenlp/client/corenlptest"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/docutil"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/internal/testdoc"
+)
+
+func TestSplitText(t *testing.T) {
+	testCases := []struct {
+		text    string
+		maxSize int
+		want    []string
+	}{
+		{"", 10, nil},
+		{"Roses are red.", 0, []string{"Roses are red."}},
+		{"Roses are red.", 20, []string{"Roses are red."}},
+		{
+			"Roses are red. Violets are blue.",
+			20,
+			[]string{"Roses are red. ", "Violets are blue."},
+		},
+		{
+			"Roses are red. Violets\n\nare blue.",
+			30,
+			[]string{"Roses are red. Violets\n\n", "are blue."},
+		},
+		{
+			"Roses are red and violets are blue.",
+			20,
+			[]string{"Roses are red and ", "violets are blue."},
+		},
+		{
+			"Roses are red.     Violets are blue.",
+			16,
+			[]string{"Roses are red.  ", "   Violets are ", "blue."},
+		},
+		{"Rosesarered", 4, []string{"Rose", "sare", "red"}},
+		{"Zoë🌹", 2, []string{"Zo", "ë", "🌹"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.text, func(t *testing.T) {
+			got := docutil.SplitText(tc.text, tc.maxSize)
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("got %q; want %q", got, tc.want)
+			}
+			if s := strings.Join(got, ""); s != tc.text {
+				t.Errorf("got concatenation %q; want %q", s, tc.text)
+			}
+		})
+	}
+}
+
+func TestAnnotateChunked(t *testing.T) {
+	const Annotators = "tokenize,ssplit"
+	text := strings.Repeat(
+		"Roses are red. Violets are blue.\n\nSugar is sweet, and so are you. ",
+		10)
+	opt := &docutil.ChunkOptions{MaxChunkSize: 100, Parallelism: 3}
+
+	srv, err := corenlptest.NewServer(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	chunks := docutil.SplitText(text, opt.MaxChunkSize)
+	if len(chunks) < 5 {
+		t.Fatalf("got %d chunks; want at least 5", len(chunks))
+	}
+	for _, chunk := range chunks {
+		err = srv.AddDocument(chunk, Annotators, testdoc.Tokenize(chunk))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	c, err := client.New(srv.ClientOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := docutil.AnnotateChunked(c, text, Annotators, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := testdoc.Tokenize(text); !proto.Equal(got, want) {
+		t.Error("the merged document differs from the document of the whole text")
+	}
+
+	// An error of any chunk fails the annotation.
+	_, err = docutil.AnnotateChunked(c, text+" Unregistered.", Annotators, opt)
+	if err == nil {
+		t.Error("got nil error with an unregistered chunk")
+	}
+
+	got, err = docutil.AnnotateChunked(c, "", Annotators, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.GetSentence()) != 0 {
+		t.Errorf("got %d sentences for empty text", len(got.GetSentence()))
+	}
+}
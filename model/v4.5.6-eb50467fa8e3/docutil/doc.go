@@ -0,0 +1,34 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package docutil slices, merges, and validates
+// Stanford CoreNLP 4.5.6 documents.
+//
+// The functions SliceSentences and SliceChars take sub-documents
+// of a document, and the function Merge concatenates documents
+// annotated separately into one document.
+// Both rewrite the cross-references between annotations,
+// such as the token and sentence offsets, the sentence indexes of
+// dependency graph nodes, the coreference chains, the entity mentions,
+// the quotes, and the sections, to keep them consistent.
+// The function Validate checks that consistency.
+//
+// The function AnnotateChunked builds on Merge to annotate a long text
+// in chunks split by the function SplitText,
+// for the servers that reject or time out on long inputs.
+package docutil
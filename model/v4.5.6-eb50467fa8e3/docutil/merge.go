@@ -0,0 +1,237 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package docutil
+
+import (
+	"fmt"
+	"strings"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/textoffset"
+)
+
+// Merge concatenates the specified documents into a new document,
+// whose text is the concatenation of the texts of the documents.
+//
+// The documents are not modified.
+// In the merged document:
+//   - The character offsets (such as beginChar, endChar,
+//     characterOffsetBegin, and characterOffsetEnd of sentences, quotes,
+//     and sections) and code point offsets of each document
+//     are shifted by the length of the texts before it.
+//   - The token offsets (such as tokenOffsetBegin, tokenOffsetEnd,
+//     tokenBeginIndex, and tokenEndIndex, and the token bounds of quotes)
+//     are shifted by the number of tokens before it.
+//   - The sentence indexes (such as sentenceIndex of sentences,
+//     dependency graph nodes, entity mentions, and coreference mentions,
+//     the sentence bounds of quotes, and sentenceIndexes of sections)
+//     are shifted by the number of sentences before it.
+//   - The indexes of entity mentions, coreference mentions (mentionsForCoref),
+//     quotes, and sections are shifted by the number of them before it,
+//     and the mappings between entity mentions and coreference mentions
+//     are concatenated accordingly.
+//     The entity mentions of a document without document-level ones
+//     are those recorded in its sentences.
+//   - The coreference chain IDs and mention IDs (and the corefClusterID
+//     of tokens) are shifted to be greater than those before it
+//     if they are not already.
+//   - The whitespace between two adjacent documents is recorded
+//     in both the field after of the last token of the former
+//     and the field before of the first token of the latter,
+//     as CoreNLP does within a document.
+//   - The document ID, date, and calendar are taken from
+//     the first document that has them.
+//
+// It reports an error if any document is nil.
+func Merge(docs ...*pb.Document) (*pb.Document, error) {
+	merged := new(pb.Document)
+	var text strings.Builder
+	var chars, codepoints, tokens, entityMentions uint32
+	var nextMentionID int32
+	var lastToken *pb.Token
+	for i, doc := range docs {
+		if doc == nil {
+			return nil, gogoerrors.AutoNew(fmt.Sprintf("document %d is nil", i))
+		}
+		doc = proto.Clone(doc).(*pb.Document)
+		docText := doc.GetText()
+		text.WriteString(docText)
+		mergeMeta(merged, doc)
+
+		var mentionID int32
+		if minID, ok := minMentionID(doc); ok && minID < nextMentionID {
+			mentionID = nextMentionID - minID
+		}
+		r := &reindexer{
+			sentence:          shiftMap(uint32(len(merged.Sentence))),
+			token:             shiftMap(tokens),
+			char:              shiftMap(chars),
+			codepoint:         shiftMap(codepoints),
+			mentionID:         mentionID,
+			entityMentionBase: entityMentions,
+			corefMentionBase:  uint32(len(merged.MentionsForCoref)),
+			quoteBase:         uint32(len(merged.Quote)),
+			sectionBase:       uint32(len(merged.Sections)),
+		}
+		r.document(doc)
+
+		for j, sentence := range doc.GetSentence() {
+			if j == 0 && lastToken != nil && len(sentence.GetToken()) > 0 {
+				joinWhitespace(lastToken, sentence.GetToken()[0])
+			}
+			if n := len(sentence.GetToken()); n > 0 {
+				lastToken = sentence.GetToken()[n-1]
+			}
+			tokens += uint32(len(sentence.GetToken()))
+		}
+		if maxID, ok := maxMentionID(doc); ok {
+			nextMentionID = max(nextMentionID, maxID+1)
+		}
+		entityMentions += uint32(numEntityMentions(doc))
+		appendDocument(merged, doc)
+
+		x := textoffset.NewIndex(docText)
+		chars += uint32(x.NumChars())
+		codepoints += uint32(x.NumCodepoints())
+	}
+	merged.Text = proto.String(text.String())
+	return merged, nil
+}
+
+// appendDocument appends the annotations of src,
+// which has been reindexed, to dst.
+func appendDocument(dst, src *pb.Document) {
+	dst.Sentence = append(dst.Sentence, src.GetSentence()...)
+	dst.CorefChain = append(dst.CorefChain, src.GetCorefChain()...)
+	dst.SentencelessToken = append(
+		dst.SentencelessToken, src.GetSentencelessToken()...)
+	dst.Character = append(dst.Character, src.GetCharacter()...)
+	dst.Quote = append(dst.Quote, src.GetQuote()...)
+	dst.Sections = append(dst.Sections, src.GetSections()...)
+	if len(src.GetCorefMentionToEntityMentionMappings()) > 0 ||
+		len(src.GetEntityMentionToCorefMentionMappings()) > 0 {
+		// Pad the mappings of the documents before src (if any)
+		// so that they stay aligned with the mentions.
+		dst.CorefMentionToEntityMentionMappings = padMappings(
+			dst.CorefMentionToEntityMentionMappings, len(dst.MentionsForCoref))
+		dst.EntityMentionToCorefMentionMappings = padMappings(
+			dst.EntityMentionToCorefMentionMappings, len(dst.Mentions))
+		dst.CorefMentionToEntityMentionMappings = append(
+			dst.CorefMentionToEntityMentionMappings,
+			padMappings(src.GetCorefMentionToEntityMentionMappings(),
+				len(src.GetMentionsForCoref()))...)
+		dst.EntityMentionToCorefMentionMappings = append(
+			dst.EntityMentionToCorefMentionMappings,
+			padMappings(src.GetEntityMentionToCorefMentionMappings(),
+				len(src.GetMentions()))...)
+	}
+	dst.Mentions = append(dst.Mentions, src.GetMentions()...)
+	dst.MentionsForCoref = append(dst.MentionsForCoref, src.GetMentionsForCoref()...)
+	if src.GetHasEntityMentionsAnnotation() {
+		dst.HasEntityMentionsAnnotation = proto.Bool(true)
+	}
+	if src.GetHasCorefMentionAnnotation() {
+		dst.HasCorefMentionAnnotation = proto.Bool(true)
+	}
+	if src.GetHasCorefAnnotation() {
+		dst.HasCorefAnnotation = proto.Bool(true)
+	}
+}
+
+// padMappings appends -1 to mappings until its length reaches n.
+func padMappings(mappings []int32, n int) []int32 {
+	for len(mappings) < n {
+		mappings = append(mappings, -1)
+	}
+	return mappings
+}
+
+// minMentionID returns the minimum coreference chain ID and mention ID
+// in the specified document.
+//
+// It reports false if there is no such ID.
+func minMentionID(doc *pb.Document) (id int32, ok bool) {
+	forEachMentionID(doc, func(x int32) {
+		if !ok || x < id {
+			id, ok = x, true
+		}
+	})
+	return
+}
+
+// maxMentionID returns the maximum coreference chain ID and mention ID
+// in the specified document.
+//
+// It reports false if there is no such ID.
+func maxMentionID(doc *pb.Document) (id int32, ok bool) {
+	forEachMentionID(doc, func(x int32) {
+		if !ok || x > id {
+			id, ok = x, true
+		}
+	})
+	return
+}
+
+// forEachMentionID calls f with each coreference chain ID and mention ID
+// in the specified document.
+func forEachMentionID(doc *pb.Document, f func(id int32)) {
+	for _, chain := range doc.GetCorefChain() {
+		f(chain.GetChainID())
+		for _, m := range chain.GetMention() {
+			if m.MentionID != nil {
+				f(m.GetMentionID())
+			}
+		}
+	}
+	for _, m := range doc.GetMentionsForCoref() {
+		if m.MentionID != nil {
+			f(m.GetMentionID())
+		}
+	}
+}
+
+// mergeMeta copies the document ID, date, and calendar of src to dst
+// if dst does not have them,
+// and sets the flags of dst if they are set in src.
+func mergeMeta(dst, src *pb.Document) {
+	if dst.DocID == nil {
+		dst.DocID = src.DocID
+	}
+	if dst.DocDate == nil {
+		dst.DocDate = src.DocDate
+	}
+	if dst.Calendar == nil {
+		dst.Calendar = src.Calendar
+	}
+	if src.GetXmlDoc() {
+		dst.XmlDoc = proto.Bool(true)
+	}
+}
+
+// joinWhitespace sets the field after of the token prev and
+// the field before of the token next to the whitespace between them,
+// that is, the concatenation of the original after of prev
+// and the original before of next.
+func joinWhitespace(prev, next *pb.Token) {
+	ws := prev.GetAfter() + next.GetBefore()
+	prev.After, next.Before = proto.String(ws), proto.String(ws)
+}
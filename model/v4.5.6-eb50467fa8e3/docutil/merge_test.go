@@ -0,0 +1,361 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package docutil_test
+
+import (
+	"slices"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/docutil"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/internal/testdoc"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/textoffset"
+)
+
+// RichText is the text of the document created by NewRichDocument.
+const RichText = `  Zoe met Tom in Paris. She said "hi" to him. They left.  Bye!`
+
+func TestMerge(t *testing.T) {
+	parts := []string{
+		"Roses are red 🌹.  ",
+		"Violets are blue.\n\n",
+		"",
+		"  Sugar is sweet, and so are you.",
+	}
+	var whole string
+	docs := make([]*pb.Document, len(parts))
+	for i, part := range parts {
+		whole += part
+		docs[i] = testdoc.Tokenize(part)
+	}
+	backup := proto.Clone(docs[1])
+	got, err := docutil.Merge(docs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := testdoc.Tokenize(whole); !proto.Equal(got, want) {
+		t.Errorf("got %v\nwant %v", got, want)
+	}
+	if err = textoffset.Verify(got); err != nil {
+		t.Error("verify -", err)
+	}
+	if !proto.Equal(docs[1], backup) {
+		t.Error("the input document is modified")
+	}
+}
+
+func TestMerge_Coref(t *testing.T) {
+	doc1 := testdoc.Tokenize("Zoe came. She left.")
+	doc1.CorefChain = []*pb.CorefChain{NewCorefChain(1, 0, 2, 1)}
+	doc1.Sentence[1].Token[0].CorefClusterID = testdoc.U32(1)
+	doc2 := testdoc.Tokenize(" Tom sang. He smiled.")
+	doc2.CorefChain = []*pb.CorefChain{NewCorefChain(0, 0, 1, 1)}
+	doc2.Sentence[0].Token[0].CorefClusterID = testdoc.U32(0)
+	doc2.Sentence[1].Token[0].CorefClusterID = testdoc.U32(0)
+
+	got, err := docutil.Merge(doc1, doc2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*pb.CorefChain{
+		NewCorefChain(1, 0, 2, 1),
+		NewCorefChain(3, 2, 4, 3),
+	}
+	if len(got.GetCorefChain()) != len(want) {
+		t.Fatalf("got %d chains; want %d", len(got.GetCorefChain()), len(want))
+	}
+	for i := range want {
+		if !proto.Equal(got.GetCorefChain()[i], want[i]) {
+			t.Errorf("chain %d - got %v; want %v",
+				i, got.GetCorefChain()[i], want[i])
+		}
+	}
+	for i, wantID := range []uint32{1, 3, 3} {
+		sentIdx := []int{1, 2, 3}[i]
+		token := got.GetSentence()[sentIdx].GetToken()[0]
+		if token.GetCorefClusterID() != wantID {
+			t.Errorf("sentence %d - got coref cluster ID %d; want %d",
+				sentIdx, token.GetCorefClusterID(), wantID)
+		}
+	}
+}
+
+func TestMerge_CrossReferences(t *testing.T) {
+	doc := NewRichDocument()
+	got, err := docutil.Merge(doc, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = docutil.Validate(got); err != nil {
+		t.Error("validate -", err)
+	}
+	if err = textoffset.Verify(got); err != nil {
+		t.Error("verify -", err)
+	}
+	n := len(doc.GetSentence())
+	for i := 0; i < n; i++ {
+		for j, m := range got.Sentence[n+i].GetMentions() {
+			want := doc.Sentence[i].Mentions[j].GetEntityMentionIndex() +
+				uint32(len(doc.GetMentions()))
+			if m.GetEntityMentionIndex() != want {
+				t.Errorf("sentence#%d, entity mention#%d - got index %d; want %d",
+					n+i, j, m.GetEntityMentionIndex(), want)
+			}
+		}
+	}
+	// The mention IDs of doc are in [0, 5], so those of its copy
+	// are shifted by 6.
+	if id := got.GetCorefChain()[3].GetChainID(); id != 10 {
+		t.Errorf("got chain ID %d; want 10", id)
+	}
+	if id := got.GetMentionsForCoref()[5].GetMentionID(); id != 6 {
+		t.Errorf("got coref mention ID %d; want 6", id)
+	}
+	wantMappings := []int32{0, 3, -1, 1, 4, 2, 5, 8, -1, 6, 9, 7}
+	if !slices.Equal(got.GetEntityMentionToCorefMentionMappings(), wantMappings) {
+		t.Errorf("got entity mention to coref mention mappings %v; want %v",
+			got.GetEntityMentionToCorefMentionMappings(), wantMappings)
+	}
+	if q := got.GetQuote()[1]; q.GetIndex() != 1 || q.GetSentenceBegin() != 5 {
+		t.Errorf("got quote index %d and sentence begin %d; want 1 and 5",
+			q.GetIndex(), q.GetSentenceBegin())
+	}
+	if i := got.Sentence[n+1].Token[2].GetQuotationIndex(); i != 1 {
+		t.Errorf("got quotation index %d; want 1", i)
+	}
+	if s := got.GetSections()[3].GetSentenceIndexes(); !slices.Equal(s, []uint32{6, 7}) {
+		t.Errorf("got section sentence indexes %v; want [6 7]", s)
+	}
+	if i := got.Sentence[n+2].GetSectionIndex(); i != 3 {
+		t.Errorf("got section index %d; want 3", i)
+	}
+}
+
+func TestMerge_SentenceEntityMentions(t *testing.T) {
+	doc := NewRichDocument()
+	doc.Mentions = nil // entity mentions are only in the sentences
+	doc.CorefMentionToEntityMentionMappings = nil
+	doc.EntityMentionToCorefMentionMappings = nil
+	got, err := docutil.Merge(doc, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = docutil.Validate(got); err != nil {
+		t.Error("validate -", err)
+	}
+	n := len(doc.GetSentence())
+	for k := 0; k < 2; k++ {
+		shift := uint32(k * 6) // doc has 6 entity mentions
+		for i, sentence := range doc.GetSentence() {
+			gotSentence := got.GetSentence()[k*n+i]
+			for j, m := range gotSentence.GetMentions() {
+				want := sentence.Mentions[j].GetEntityMentionIndex() + shift
+				if m.EntityMentionIndex == nil || m.GetEntityMentionIndex() != want {
+					t.Errorf("sentence#%d, entity mention#%d - got index %v; want %d",
+						k*n+i, j, m.EntityMentionIndex, want)
+				}
+			}
+			for j, token := range gotSentence.GetToken() {
+				if sentence.Token[j].EntityMentionIndex == nil {
+					continue
+				}
+				want := sentence.Token[j].GetEntityMentionIndex() + shift
+				if token.EntityMentionIndex == nil || token.GetEntityMentionIndex() != want {
+					t.Errorf("sentence#%d, token#%d - got entity mention index %v; want %d",
+						k*n+i, j, token.EntityMentionIndex, want)
+				}
+			}
+		}
+	}
+}
+
+func TestMerge_Nil(t *testing.T) {
+	if _, err := docutil.Merge(testdoc.Tokenize("Hi."), nil); err == nil {
+		t.Error("got nil error")
+	}
+	got, err := docutil.Merge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (&pb.Document{Text: proto.String("")}); !proto.Equal(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+// NewCorefChain creates a coreference chain with the specified chain ID,
+// consisting of two mentions: the first token of the sentence
+// with the specified index sentence1 (the representative),
+// and the first token of the sentence with the specified index sentence2.
+//
+// The IDs of the mentions are chainID and mentionID2.
+func NewCorefChain(
+	chainID int32,
+	sentence1 uint32,
+	mentionID2 int32,
+	sentence2 uint32,
+) *pb.CorefChain {
+	return &pb.CorefChain{
+		ChainID:        proto.Int32(chainID),
+		Representative: proto.Uint32(0),
+		Mention: []*pb.CorefChain_CorefMention{
+			{
+				MentionID:     proto.Int32(chainID),
+				BeginIndex:    proto.Uint32(0),
+				EndIndex:      proto.Uint32(1),
+				HeadIndex:     proto.Uint32(0),
+				SentenceIndex: proto.Uint32(sentence1),
+			},
+			{
+				MentionID:     proto.Int32(mentionID2),
+				BeginIndex:    proto.Uint32(0),
+				EndIndex:      proto.Uint32(1),
+				HeadIndex:     proto.Uint32(0),
+				SentenceIndex: proto.Uint32(sentence2),
+			},
+		},
+	}
+}
+
+// NewRichDocument returns the document of RichText
+// (tokenized by testdoc.Tokenize) with the cross-referencing annotations:
+// basic dependencies, entity mentions, coreference mentions and chains,
+// a quote, and two sections.
+func NewRichDocument() *pb.Document {
+	doc := testdoc.Tokenize(RichText)
+	for i, sentence := range doc.Sentence {
+		g := new(pb.DependencyGraph)
+		for j := range sentence.Token {
+			g.Node = append(g.Node, &pb.DependencyGraph_Node{
+				SentenceIndex: proto.Uint32(uint32(i)),
+				Index:         proto.Uint32(uint32(j + 1)),
+			})
+			if j > 0 {
+				g.Edge = append(g.Edge, &pb.DependencyGraph_Edge{
+					Source: proto.Uint32(1),
+					Target: proto.Uint32(uint32(j + 1)),
+					Dep:    proto.String("dep"),
+				})
+			}
+		}
+		g.Root = []uint32{1}
+		sentence.BasicDependencies = g
+	}
+
+	// Entity mentions (sentence, token, canonical):
+	// Zoe (0, 0), Tom (0, 2), Paris (0, 4), She (1, 0, Zoe),
+	// him (1, 4, Tom), They (2, 0).
+	for i, m := range [][3]int{
+		{0, 0, 0}, {0, 2, 1}, {0, 4, 2}, {1, 0, 0}, {1, 4, 1}, {2, 0, -1},
+	} {
+		nerMention := &pb.NERMention{
+			SentenceIndex:                 proto.Uint32(uint32(m[0])),
+			TokenStartInSentenceInclusive: proto.Uint32(uint32(m[1])),
+			TokenEndInSentenceExclusive:   proto.Uint32(uint32(m[1] + 1)),
+			Ner:                           proto.String("PERSON"),
+			EntityMentionIndex:            proto.Uint32(uint32(i)),
+		}
+		if m[2] >= 0 {
+			nerMention.CanonicalEntityMentionIndex = proto.Uint32(uint32(m[2]))
+		}
+		doc.Mentions = append(doc.Mentions, nerMention)
+		sentence := doc.Sentence[m[0]]
+		sentence.Mentions = append(sentence.Mentions,
+			proto.Clone(nerMention).(*pb.NERMention))
+		sentence.Token[m[1]].EntityMentionIndex = proto.Uint32(uint32(i))
+	}
+	doc.HasEntityMentionsAnnotation = proto.Bool(true)
+
+	// Coreference mentions (ID, sentence, token, entity mention):
+	// Zoe (0, 0, 0, 0), She (2, 1, 0, 3), They (3, 2, 0, 5),
+	// Tom (1, 0, 2, 1), him (5, 1, 4, 4).
+	for i, m := range [][4]int{
+		{0, 0, 0, 0}, {2, 1, 0, 3}, {3, 2, 0, 5}, {1, 0, 2, 1}, {5, 1, 4, 4},
+	} {
+		doc.MentionsForCoref = append(doc.MentionsForCoref, &pb.Mention{
+			MentionID:  proto.Int32(int32(m[0])),
+			SentNum:    proto.Int32(int32(m[1])),
+			StartIndex: proto.Uint32(uint32(m[2])),
+			EndIndex:   proto.Uint32(uint32(m[2] + 1)),
+			HeadIndexedWord: &pb.IndexedWord{
+				SentenceNum: proto.Int32(int32(m[1])),
+				TokenIndex:  proto.Int32(int32(m[2])),
+			},
+		})
+		token := doc.Sentence[m[1]].Token[m[2]]
+		token.CorefMentionIndex = []uint32{uint32(i)}
+		doc.CorefMentionToEntityMentionMappings = append(
+			doc.CorefMentionToEntityMentionMappings, int32(m[3]))
+	}
+	doc.EntityMentionToCorefMentionMappings = []int32{0, 3, -1, 1, 4, 2}
+	doc.HasCorefMentionAnnotation = proto.Bool(true)
+	doc.HasCorefAnnotation = proto.Bool(true)
+	doc.CorefChain = []*pb.CorefChain{
+		{
+			ChainID:        proto.Int32(1),
+			Representative: proto.Uint32(0),
+			Mention: []*pb.CorefChain_CorefMention{
+				testdoc.NewCorefMention(0, 0, 0),
+				testdoc.NewCorefMention(2, 1, 0),
+				testdoc.NewCorefMention(3, 2, 0),
+			},
+		},
+		{
+			ChainID:        proto.Int32(4),
+			Representative: proto.Uint32(0),
+			Mention: []*pb.CorefChain_CorefMention{
+				testdoc.NewCorefMention(1, 0, 2),
+				testdoc.NewCorefMention(5, 1, 4),
+			},
+		},
+	}
+	for _, chain := range doc.CorefChain {
+		for _, m := range chain.Mention {
+			token := doc.Sentence[m.GetSentenceIndex()].Token[m.GetBeginIndex()]
+			token.CorefClusterID = proto.Uint32(uint32(chain.GetChainID()))
+		}
+	}
+
+	hi := doc.Sentence[1].Token[2]
+	doc.Quote = []*pb.Quote{{
+		Text:          proto.String(`"hi"`),
+		Begin:         proto.Uint32(hi.GetBeginChar()),
+		End:           proto.Uint32(hi.GetEndChar()),
+		SentenceBegin: proto.Uint32(1),
+		SentenceEnd:   proto.Uint32(1),
+		TokenBegin:    proto.Uint32(hi.GetTokenBeginIndex()),
+		TokenEnd:      proto.Uint32(hi.GetTokenEndIndex()),
+		Index:         proto.Uint32(0),
+	}}
+	hi.QuotationIndex = proto.Int32(0)
+
+	for i := 0; i < 2; i++ {
+		first, last := doc.Sentence[2*i], doc.Sentence[2*i+1]
+		doc.Sections = append(doc.Sections, &pb.Section{
+			CharBegin:       proto.Uint32(first.GetCharacterOffsetBegin()),
+			CharEnd:         proto.Uint32(last.GetCharacterOffsetEnd()),
+			SentenceIndexes: []uint32{uint32(2 * i), uint32(2*i + 1)},
+			XmlTag:          &pb.Token{Word: proto.String("post")},
+		})
+		first.SectionIndex = proto.Uint32(uint32(i))
+		last.SectionIndex = proto.Uint32(uint32(i))
+	}
+	return doc
+}
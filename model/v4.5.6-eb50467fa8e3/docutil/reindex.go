@@ -0,0 +1,563 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package docutil
+
+import (
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+// indexMap maps an index (or offset) in the original document
+// to that in the new document.
+//
+// It reports false if the indexed item is not in the new document.
+// In that case, the returned index is clamped to the nearest one
+// in the new document.
+type indexMap func(i uint32) (uint32, bool)
+
+// shiftMap returns an indexMap adding delta to every index.
+func shiftMap(delta uint32) indexMap {
+	return func(i uint32) (uint32, bool) {
+		return i + delta, true
+	}
+}
+
+// rangeMap returns an indexMap keeping the indexes in [begin, end)
+// and subtracting begin from them.
+func rangeMap(begin, end uint32) indexMap {
+	return func(i uint32) (uint32, bool) {
+		switch {
+		case i < begin || end <= begin:
+			return 0, false
+		case i >= end:
+			return end - begin - 1, false
+		}
+		return i - begin, true
+	}
+}
+
+// reindexer rewrites the cross-references in a document
+// (a clone of the original one) for merging and slicing.
+//
+// The fields sentence, token, char, codepoint, mentionID,
+// and the bases must be set before calling the method document.
+// The other index maps are set by the method document.
+type reindexer struct {
+	sentence  indexMap // sentence maps the sentence indexes.
+	token     indexMap // token maps the document-level token offsets.
+	char      indexMap // char maps the character (UTF-16 code unit) offsets.
+	codepoint indexMap // codepoint maps the code point offsets.
+
+	mentionID int32 // mentionID is added to the coreference chain IDs and mention IDs.
+
+	entityMentionBase uint32 // entityMentionBase is the number of entity mentions before the document.
+	corefMentionBase  uint32 // corefMentionBase is the number of coreference mentions before the document.
+	quoteBase         uint32 // quoteBase is the number of quotes before the document.
+	sectionBase       uint32 // sectionBase is the number of sections before the document.
+
+	entityMention indexMap // entityMention maps the indexes of Document.mentions.
+	corefMention  indexMap // corefMention maps the indexes of Document.mentionsForCoref.
+	quote         indexMap // quote maps the quote indexes (Quote.index).
+	section       indexMap // section maps the indexes of Document.sections.
+}
+
+// document drops the annotations of doc that are not in the new document
+// and rewrites the cross-references of the remaining ones in place.
+func (r *reindexer) document(doc *pb.Document) {
+	// Decide which document-level annotations to keep
+	// before rewriting anything, as they refer to each other.
+	var keptEntity, keptCoref []int
+	doc.Mentions, keptEntity, r.entityMention = filter(
+		doc.GetMentions(), r.entityMentionBase,
+		func(i int, _ *pb.NERMention) uint32 { return uint32(i) },
+		func(m *pb.NERMention) bool {
+			_, ok := r.sentence(m.GetSentenceIndex())
+			return ok
+		},
+	)
+	if len(doc.GetMentions()) == 0 {
+		// The entity mentions are recorded only in the sentences.
+		r.entityMention = r.sentenceEntityMentions(doc)
+	}
+	doc.MentionsForCoref, keptCoref, r.corefMention = filter(
+		doc.GetMentionsForCoref(), r.corefMentionBase,
+		func(i int, _ *pb.Mention) uint32 { return uint32(i) },
+		func(m *pb.Mention) bool {
+			if m.GetSentNum() < 0 {
+				return true
+			}
+			_, ok := r.sentence(uint32(m.GetSentNum()))
+			return ok
+		},
+	)
+	doc.Quote, _, r.quote = filter(doc.GetQuote(), r.quoteBase,
+		func(i int, q *pb.Quote) uint32 {
+			if q.Index != nil {
+				return q.GetIndex()
+			}
+			return uint32(i)
+		},
+		r.keepQuote,
+	)
+	doc.Sections, _, r.section = filter(doc.GetSections(), r.sectionBase,
+		func(i int, _ *pb.Section) uint32 { return uint32(i) },
+		r.keepSection,
+	)
+	doc.CorefMentionToEntityMentionMappings = remapMappings(
+		doc.GetCorefMentionToEntityMentionMappings(), keptCoref, r.entityMention)
+	doc.EntityMentionToCorefMentionMappings = remapMappings(
+		doc.GetEntityMentionToCorefMentionMappings(), keptEntity, r.corefMention)
+
+	var sentences []*pb.Sentence
+	for i, sentence := range doc.GetSentence() {
+		if newIndex, ok := r.sentence(uint32(i)); ok {
+			r.sentenceContent(sentence, newIndex)
+			sentences = append(sentences, sentence)
+		}
+	}
+	doc.Sentence = sentences
+	for _, m := range doc.GetMentions() {
+		r.nerMention(m)
+	}
+	for _, m := range doc.GetMentionsForCoref() {
+		r.corefMentionContent(m)
+	}
+	for _, q := range doc.GetQuote() {
+		r.quoteContent(q)
+	}
+	for _, section := range doc.GetSections() {
+		r.sectionContent(section)
+	}
+	var chains []*pb.CorefChain
+	for _, chain := range doc.GetCorefChain() {
+		if r.corefChain(chain) {
+			chains = append(chains, chain)
+		}
+	}
+	doc.CorefChain = chains
+	doc.Character = r.tokens(doc.GetCharacter())
+	doc.SentencelessToken = r.tokens(doc.GetSentencelessToken())
+}
+
+// sentenceEntityMentions returns an indexMap for the entity mentions
+// recorded in the sentences of doc (Sentence.mentions), in order,
+// used if doc has no document-level entity mentions.
+//
+// The key of a mention is its entityMentionIndex if set,
+// and its position among all the mentions of the sentences otherwise.
+func (r *reindexer) sentenceEntityMentions(doc *pb.Document) indexMap {
+	newIndexes := make(map[uint32]uint32)
+	var i, n uint32
+	for sentIdx, sentence := range doc.GetSentence() {
+		_, keep := r.sentence(uint32(sentIdx))
+		for _, m := range sentence.GetMentions() {
+			key := i
+			if m.EntityMentionIndex != nil {
+				key = m.GetEntityMentionIndex()
+			}
+			if keep {
+				newIndexes[key] = r.entityMentionBase + n
+				n++
+			}
+			i++
+		}
+	}
+	return func(i uint32) (uint32, bool) {
+		v, ok := newIndexes[i]
+		return v, ok
+	}
+}
+
+// numEntityMentions returns the number of entity mentions of doc,
+// which is the number of document-level entity mentions, or,
+// if there is none, the number of entity mentions in the sentences.
+func numEntityMentions(doc *pb.Document) int {
+	if n := len(doc.GetMentions()); n > 0 {
+		return n
+	}
+	var n int
+	for _, sentence := range doc.GetSentence() {
+		n += len(sentence.GetMentions())
+	}
+	return n
+}
+
+// sentenceContent rewrites the cross-references of the specified sentence
+// and sets its sentence index to newIndex.
+func (r *reindexer) sentenceContent(sentence *pb.Sentence, newIndex uint32) {
+	sentence.SentenceIndex = proto.Uint32(newIndex)
+	sentence.TokenOffsetBegin = proto.Uint32(
+		clamp(sentence.GetTokenOffsetBegin(), r.token))
+	sentence.TokenOffsetEnd = proto.Uint32(
+		clamp(sentence.GetTokenOffsetEnd(), r.token))
+	remap(&sentence.CharacterOffsetBegin, r.char)
+	remap(&sentence.CharacterOffsetEnd, r.char)
+	remap(&sentence.SectionIndex, r.section)
+	for _, token := range sentence.GetToken() {
+		r.tokenContent(token)
+	}
+	for _, token := range sentence.GetCharacter() {
+		r.tokenContent(token)
+	}
+	for _, g := range []*pb.DependencyGraph{
+		sentence.GetBasicDependencies(),
+		sentence.GetCollapsedDependencies(),
+		sentence.GetCollapsedCCProcessedDependencies(),
+		sentence.GetAlternativeDependencies(),
+		sentence.GetEnhancedDependencies(),
+		sentence.GetEnhancedPlusPlusDependencies(),
+	} {
+		r.graph(g)
+	}
+	for _, triples := range [][]*pb.RelationTriple{
+		sentence.GetOpenieTriple(),
+		sentence.GetKbpTriple(),
+	} {
+		for _, triple := range triples {
+			r.relationTriple(triple)
+		}
+	}
+	for _, m := range sentence.GetMentions() {
+		r.nerMention(m)
+	}
+	for _, m := range sentence.GetMentionsForCoref() {
+		r.corefMentionContent(m)
+	}
+	if enhanced := sentence.GetEnhancedSentence(); enhanced != nil {
+		r.sentenceContent(enhanced, clamp(enhanced.GetSentenceIndex(), r.sentence))
+	}
+}
+
+// tokens rewrites the cross-references of the specified tokens
+// and returns those in the new document.
+func (r *reindexer) tokens(tokens []*pb.Token) []*pb.Token {
+	var kept []*pb.Token
+	for _, token := range tokens {
+		if _, ok := r.char(token.GetBeginChar()); token.BeginChar != nil && !ok {
+			continue
+		}
+		if _, ok := r.char(token.GetEndChar()); token.EndChar != nil && !ok {
+			continue
+		}
+		r.tokenContent(token)
+		kept = append(kept, token)
+	}
+	return kept
+}
+
+// tokenContent rewrites the cross-references of the specified token.
+func (r *reindexer) tokenContent(token *pb.Token) {
+	remap(&token.BeginChar, r.char)
+	remap(&token.EndChar, r.char)
+	remap(&token.CodepointOffsetBegin, r.codepoint)
+	remap(&token.CodepointOffsetEnd, r.codepoint)
+	remap(&token.TokenBeginIndex, r.token)
+	remap(&token.TokenEndIndex, r.token)
+	if token.CorefClusterID != nil {
+		token.CorefClusterID = proto.Uint32(
+			token.GetCorefClusterID() + uint32(r.mentionID))
+	}
+	token.CorefMentionIndex = remapAll(token.GetCorefMentionIndex(), r.corefMention)
+	remap(&token.EntityMentionIndex, r.entityMention)
+	remapInt32(&token.QuotationIndex, r.quote)
+}
+
+// graph rewrites the sentence indexes of the nodes
+// and the tokens of the specified dependency graph.
+func (r *reindexer) graph(g *pb.DependencyGraph) {
+	if g == nil {
+		return
+	}
+	for _, node := range g.GetNode() {
+		node.SentenceIndex = proto.Uint32(
+			clamp(node.GetSentenceIndex(), r.sentence))
+	}
+	for _, token := range g.GetToken() {
+		r.tokenContent(token)
+	}
+}
+
+// relationTriple rewrites the sentence indexes of the token locations
+// and the dependency graph of the specified relation triple.
+func (r *reindexer) relationTriple(triple *pb.RelationTriple) {
+	for _, locations := range [][]*pb.TokenLocation{
+		triple.GetSubjectTokens(),
+		triple.GetRelationTokens(),
+		triple.GetObjectTokens(),
+	} {
+		for _, loc := range locations {
+			remap(&loc.SentenceIndex, r.sentence)
+		}
+	}
+	r.graph(triple.GetTree())
+}
+
+// nerMention rewrites the cross-references of the specified entity mention.
+func (r *reindexer) nerMention(m *pb.NERMention) {
+	remap(&m.SentenceIndex, r.sentence)
+	remap(&m.EntityMentionIndex, r.entityMention)
+	remap(&m.CanonicalEntityMentionIndex, r.entityMention)
+}
+
+// corefMentionContent rewrites the cross-references of
+// the specified coreference mention (used by the coreference annotator).
+func (r *reindexer) corefMentionContent(m *pb.Mention) {
+	if m.MentionID != nil {
+		m.MentionID = proto.Int32(m.GetMentionID() + r.mentionID)
+	}
+	if m.CorefClusterID != nil && m.GetCorefClusterID() >= 0 {
+		m.CorefClusterID = proto.Int32(m.GetCorefClusterID() + r.mentionID)
+	}
+	remapInt32(&m.SentNum, r.sentence)
+	for _, words := range [][]*pb.IndexedWord{
+		{m.GetHeadIndexedWord(), m.GetDependingVerb(), m.GetHeadWord()},
+		m.GetSentenceWords(),
+		m.GetOriginalSpan(),
+	} {
+		for _, w := range words {
+			if w != nil {
+				remapInt32(&w.SentenceNum, r.sentence)
+			}
+		}
+	}
+	if info := m.GetSpeakerInfo(); info != nil {
+		shiftIDs(info.GetMentions(), r.mentionID)
+	}
+	for _, ids := range [][]int32{
+		m.GetAppositions(),
+		m.GetPredicateNominatives(),
+		m.GetRelativePronouns(),
+		m.GetListMembers(),
+		m.GetBelongToLists(),
+	} {
+		shiftIDs(ids, r.mentionID)
+	}
+}
+
+// corefChain drops the mentions of the specified coreference chain
+// that are not in the new document and rewrites the remaining ones.
+//
+// If the representative mention is dropped,
+// the first remaining mention becomes the representative.
+//
+// It reports false if no mention remains.
+func (r *reindexer) corefChain(chain *pb.CorefChain) bool {
+	var rep *pb.CorefChain_CorefMention
+	if i := chain.GetRepresentative(); i < uint32(len(chain.GetMention())) {
+		rep = chain.GetMention()[i]
+	}
+	chain.ChainID = proto.Int32(chain.GetChainID() + r.mentionID)
+	var mentions []*pb.CorefChain_CorefMention
+	var newRep uint32
+	for _, m := range chain.GetMention() {
+		if m.SentenceIndex != nil {
+			if _, ok := r.sentence(m.GetSentenceIndex()); !ok {
+				continue
+			}
+		}
+		if m == rep {
+			newRep = uint32(len(mentions))
+		}
+		if m.MentionID != nil {
+			m.MentionID = proto.Int32(m.GetMentionID() + r.mentionID)
+		}
+		remap(&m.SentenceIndex, r.sentence)
+		mentions = append(mentions, m)
+	}
+	chain.Mention, chain.Representative = mentions, proto.Uint32(newRep)
+	return len(mentions) > 0
+}
+
+// keepQuote reports whether the specified quote is in the new document.
+//
+// A quote is in the new document if all its sentences are.
+// For the quotes without sentence bounds,
+// its first token (or first character) is checked instead.
+func (r *reindexer) keepQuote(q *pb.Quote) bool {
+	switch {
+	case q.SentenceBegin != nil || q.SentenceEnd != nil:
+		_, ok1 := r.sentence(q.GetSentenceBegin())
+		_, ok2 := r.sentence(q.GetSentenceEnd())
+		return ok1 && ok2
+	case q.TokenBegin != nil:
+		_, ok := r.token(q.GetTokenBegin())
+		return ok
+	case q.Begin != nil:
+		_, ok := r.char(q.GetBegin())
+		return ok
+	}
+	return true
+}
+
+// quoteContent rewrites the cross-references of the specified quote.
+func (r *reindexer) quoteContent(q *pb.Quote) {
+	remap(&q.Begin, r.char)
+	remap(&q.End, r.char)
+	remap(&q.SentenceBegin, r.sentence)
+	remap(&q.SentenceEnd, r.sentence)
+	remap(&q.TokenBegin, r.token)
+	remap(&q.TokenEnd, r.token)
+	remap(&q.Index, r.quote)
+	remap(&q.MentionBegin, r.token)
+	remap(&q.MentionEnd, r.token)
+	remap(&q.CanonicalMentionBegin, r.token)
+	remap(&q.CanonicalMentionEnd, r.token)
+	r.graph(q.GetAttributionDependencyGraph())
+}
+
+// keepSection reports whether the specified section is in the new document.
+//
+// A section is in the new document if any of its sentences is,
+// or, for the sections without sentences, if its first character is.
+func (r *reindexer) keepSection(section *pb.Section) bool {
+	if len(section.GetSentenceIndexes()) == 0 {
+		_, ok := r.char(section.GetCharBegin())
+		return ok
+	}
+	for _, i := range section.GetSentenceIndexes() {
+		if _, ok := r.sentence(i); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// sectionContent rewrites the cross-references of the specified section.
+//
+// Its character range is clipped to the new document,
+// and its quotes that are not in the new document are dropped.
+func (r *reindexer) sectionContent(section *pb.Section) {
+	section.CharBegin = proto.Uint32(clamp(section.GetCharBegin(), r.char))
+	section.CharEnd = proto.Uint32(clamp(section.GetCharEnd(), r.char))
+	section.SentenceIndexes = remapAll(section.GetSentenceIndexes(), r.sentence)
+	remap(&section.AuthorCharBegin, r.char)
+	remap(&section.AuthorCharEnd, r.char)
+	var quotes []*pb.Quote
+	for _, q := range section.GetQuotes() {
+		if r.keepQuote(q) {
+			r.quoteContent(q)
+			quotes = append(quotes, q)
+		}
+	}
+	section.Quotes = quotes
+	if tag := section.GetXmlTag(); tag != nil {
+		r.tokenContent(tag)
+	}
+}
+
+// filter returns the items of s satisfying keep, in order,
+// the indexes of them in s,
+// and an indexMap from their keys to their new indexes
+// (base plus their indexes in the returned slice).
+func filter[T any](
+	s []T,
+	base uint32,
+	key func(i int, item T) uint32,
+	keep func(item T) bool,
+) (kept []T, indexes []int, m indexMap) {
+	newIndexes := make(map[uint32]uint32, len(s))
+	for i, item := range s {
+		if keep(item) {
+			newIndexes[key(i, item)] = base + uint32(len(kept))
+			kept = append(kept, item)
+			indexes = append(indexes, i)
+		}
+	}
+	m = func(i uint32) (uint32, bool) {
+		v, ok := newIndexes[i]
+		return v, ok
+	}
+	return
+}
+
+// remapMappings returns the mappings (such as
+// Document.corefMentionToEntityMentionMappings) of the kept items,
+// whose indexes in the original document are keptIndexes,
+// with their targets mapped by target.
+//
+// The targets not in the new document are set to -1.
+func remapMappings(mappings []int32, keptIndexes []int, target indexMap) []int32 {
+	if len(mappings) == 0 {
+		return nil
+	}
+	result := make([]int32, 0, len(keptIndexes))
+	for _, i := range keptIndexes {
+		v := int32(-1)
+		if i < len(mappings) && mappings[i] >= 0 {
+			if t, ok := target(uint32(mappings[i])); ok {
+				v = int32(t)
+			}
+		}
+		result = append(result, v)
+	}
+	return result
+}
+
+// remap maps *p by m if *p is not nil.
+// It sets *p to nil if the indexed item is not in the new document.
+func remap(p **uint32, m indexMap) {
+	if *p != nil {
+		if v, ok := m(**p); ok {
+			*p = proto.Uint32(v)
+		} else {
+			*p = nil
+		}
+	}
+}
+
+// remapInt32 is like remap but for int32 indexes.
+// Negative values (meaning "none") are left unchanged.
+func remapInt32(p **int32, m indexMap) {
+	if *p != nil && **p >= 0 {
+		if v, ok := m(uint32(**p)); ok {
+			*p = proto.Int32(int32(v))
+		} else {
+			*p = nil
+		}
+	}
+}
+
+// remapAll returns the indexes in s mapped by m,
+// dropping those not in the new document.
+func remapAll(s []uint32, m indexMap) []uint32 {
+	if len(s) == 0 {
+		return s
+	}
+	result := make([]uint32, 0, len(s))
+	for _, i := range s {
+		if v, ok := m(i); ok {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// clamp returns i mapped by m, clamped to the new document.
+func clamp(i uint32, m indexMap) uint32 {
+	v, _ := m(i)
+	return v
+}
+
+// shiftIDs adds delta to each ID in ids in place.
+func shiftIDs(ids []int32, delta int32) {
+	for i := range ids {
+		ids[i] += delta
+	}
+}
@@ -0,0 +1,209 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package docutil
+
+import (
+	"fmt"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/textoffset"
+)
+
+// SliceSentences returns a new document consisting of the sentences
+// of doc with indexes in [begin, end).
+//
+// The text of the new document runs from the beginning of
+// the sentence begin to the beginning of the sentence end,
+// where the beginning of the first sentence is taken as
+// the beginning of the text, and the beginning of the sentence
+// after the last one is taken as the end of the text.
+// Thus, the whitespace following a sentence belongs to the slice
+// of that sentence.
+// Therefore, merging the slices of consecutive ranges by Merge
+// restores the original text and offsets.
+//
+// The document doc is not modified.
+// In the new document, the character, code point, token,
+// and sentence offsets are rebased to the new text,
+// and the cross-references are rewritten accordingly:
+//   - The entity mentions, coreference mentions, and coreference chain
+//     mentions in other sentences are dropped.
+//     A coreference chain without remaining mentions is dropped.
+//     If the representative mention of a chain is dropped,
+//     its first remaining mention becomes the representative.
+//     The chain IDs and mention IDs are unchanged.
+//   - The quotes not entirely in the sentences are dropped.
+//   - The sections without any of the sentences are dropped,
+//     and the character ranges of the others are clipped to the new text.
+//   - The references to the dropped annotations, such as
+//     entityMentionIndex and quotationIndex of tokens,
+//     are cleared.
+//
+// The field before of the first token is set to empty
+// if it is not the first token of doc,
+// as the whitespace before it belongs to the previous slice.
+//
+// It reports an error if doc is nil,
+// or the range is invalid or out of range.
+func SliceSentences(doc *pb.Document, begin, end int) (*pb.Document, error) {
+	if doc == nil {
+		return nil, gogoerrors.AutoNew("document is nil")
+	}
+	n := len(doc.GetSentence())
+	if begin < 0 || end < begin || end > n {
+		return nil, gogoerrors.AutoNew(fmt.Sprintf(
+			"sentence range [%d, %d) is invalid or out of range [0, %d)",
+			begin, end, n))
+	}
+	doc = proto.Clone(doc).(*pb.Document)
+	x := textoffset.NewIndex(doc.GetText())
+	sentences := doc.GetSentence()
+	numChars := uint32(x.NumChars())
+	var numTokens uint32
+	if n > 0 {
+		numTokens = sentences[n-1].GetTokenOffsetEnd()
+	}
+	var charBegin, tokenBegin, charEnd, tokenEnd uint32
+	var err error
+	switch begin {
+	case 0:
+	case n:
+		charBegin, tokenBegin = numChars, numTokens
+	default:
+		charBegin, tokenBegin, err = sentenceStart(sentences, begin)
+		if err != nil {
+			return nil, gogoerrors.AutoWrap(err)
+		}
+	}
+	switch end {
+	case n:
+		charEnd, tokenEnd = numChars, numTokens
+	case 0:
+	default:
+		charEnd, tokenEnd, err = sentenceStart(sentences, end)
+		if err != nil {
+			return nil, gogoerrors.AutoWrap(err)
+		}
+	}
+	text, ok := x.SliceChars(int(charBegin), int(charEnd))
+	if !ok {
+		return nil, gogoerrors.AutoNew(fmt.Sprintf(
+			"character range [%d, %d) of sentences is invalid",
+			charBegin, charEnd))
+	}
+	cpBegin, _ := x.CharToCodepoint(int(charBegin))
+	cpEnd, _ := x.CharToCodepoint(int(charEnd))
+
+	r := &reindexer{
+		sentence: rangeMap(uint32(begin), uint32(end)),
+		// Offsets are positions, so the end is in range.
+		token:     rangeMap(tokenBegin, tokenEnd+1),
+		char:      rangeMap(charBegin, charEnd+1),
+		codepoint: rangeMap(uint32(cpBegin), uint32(cpEnd)+1),
+	}
+	r.document(doc)
+	doc.Text = proto.String(text)
+	if begin > 0 && begin < end {
+		if tokens := doc.GetSentence()[0].GetToken(); len(tokens) > 0 {
+			tokens[0].Before = proto.String("")
+		}
+	}
+	return doc, nil
+}
+
+// SliceChars returns a new document consisting of the sentences
+// of doc overlapping the character range [begin, end),
+// where the offsets are in UTF-16 code units, as CoreNLP does.
+//
+// If the range is empty, the sentence containing begin, if any,
+// is taken.
+//
+// Documents are sliced at sentence boundaries to keep
+// the sentence-level annotations (such as dependency graphs) consistent,
+// so the text of the new document may extend beyond the range.
+// See SliceSentences for details.
+//
+// It reports an error if doc is nil,
+// or the range is invalid or out of the text.
+func SliceChars(doc *pb.Document, begin, end int) (*pb.Document, error) {
+	if doc == nil {
+		return nil, gogoerrors.AutoNew("document is nil")
+	}
+	numChars := textoffset.NewIndex(doc.GetText()).NumChars()
+	if begin < 0 || end < begin || end > numChars {
+		return nil, gogoerrors.AutoNew(fmt.Sprintf(
+			"character range [%d, %d) is invalid or out of range [0, %d)",
+			begin, end, numChars))
+	}
+	sentences := doc.GetSentence()
+	first := len(sentences)
+	for i, sentence := range sentences {
+		if int(sentenceEndChar(sentence)) > begin {
+			first = i
+			break
+		}
+	}
+	last := first
+	for last < len(sentences) && int(sentenceBeginChar(sentences[last])) < end {
+		last++
+	}
+	if begin == end && first < len(sentences) &&
+		int(sentenceBeginChar(sentences[first])) <= begin {
+		last = first + 1
+	}
+	doc, err := SliceSentences(doc, first, last)
+	return doc, gogoerrors.AutoWrap(err)
+}
+
+// sentenceStart returns the character offset and token offset
+// at which the sentence with index i starts.
+func sentenceStart(
+	sentences []*pb.Sentence,
+	i int,
+) (char, token uint32, err error) {
+	sentence := sentences[i]
+	if tokens := sentence.GetToken(); sentence.CharacterOffsetBegin == nil &&
+		(len(tokens) == 0 || tokens[0].BeginChar == nil) {
+		return 0, 0, gogoerrors.AutoNew(fmt.Sprintf(
+			"sentence %d has no character offset", i))
+	}
+	return sentenceBeginChar(sentence), sentence.GetTokenOffsetBegin(), nil
+}
+
+// sentenceBeginChar returns the character offset
+// at which the specified sentence begins.
+func sentenceBeginChar(sentence *pb.Sentence) uint32 {
+	if sentence.CharacterOffsetBegin == nil && len(sentence.GetToken()) > 0 {
+		return sentence.GetToken()[0].GetBeginChar()
+	}
+	return sentence.GetCharacterOffsetBegin()
+}
+
+// sentenceEndChar returns the character offset
+// at which the specified sentence ends.
+func sentenceEndChar(sentence *pb.Sentence) uint32 {
+	tokens := sentence.GetToken()
+	if sentence.CharacterOffsetEnd == nil && len(tokens) > 0 {
+		return tokens[len(tokens)-1].GetEndChar()
+	}
+	return sentence.GetCharacterOffsetEnd()
+}
@@ -0,0 +1,324 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package docutil_test
+
+import (
+	"fmt"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/docutil"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/internal/testdoc"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/textoffset"
+)
+
+func TestSliceSentences_Tokens(t *testing.T) {
+	doc := testdoc.Tokenize(RichText)
+	x := textoffset.NewIndex(RichText)
+	n := len(doc.GetSentence())
+	for begin := 0; begin <= n; begin++ {
+		for end := begin; end <= n; end++ {
+			got, err := docutil.SliceSentences(doc, begin, end)
+			if err != nil {
+				t.Errorf("[%d, %d) - %v", begin, end, err)
+				continue
+			}
+			// boundary is where the text of the slice beginning
+			// or ending at sentence i is cut.
+			boundary := func(i int) int {
+				switch i {
+				case 0:
+					return 0
+				case n:
+					return x.NumChars()
+				}
+				return int(doc.Sentence[i].GetCharacterOffsetBegin())
+			}
+			charBegin, charEnd := boundary(begin), boundary(end)
+			text, _ := x.SliceChars(charBegin, charEnd)
+			if want := testdoc.Tokenize(text); !proto.Equal(got, want) {
+				t.Errorf("[%d, %d) - got %v\nwant %v", begin, end, got, want)
+			}
+		}
+	}
+}
+
+func TestSliceSentences_RoundTrip(t *testing.T) {
+	doc := testdoc.Tokenize(RichText)
+	n := len(doc.GetSentence())
+	for k := 0; k <= n; k++ {
+		former, err := docutil.SliceSentences(doc, 0, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		latter, err := docutil.SliceSentences(doc, k, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := docutil.Merge(former, latter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !proto.Equal(got, doc) {
+			t.Errorf("k=%d - got %v\nwant %v", k, got, doc)
+		}
+	}
+}
+
+func TestSliceSentences_CrossReferences(t *testing.T) {
+	doc := NewRichDocument()
+	backup := proto.Clone(doc)
+	got, err := docutil.SliceSentences(doc, 1, 3) // She said "hi" to him. They left.
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(doc, backup) {
+		t.Error("the input document is modified")
+	}
+	if err = docutil.Validate(got); err != nil {
+		t.Error("validate -", err)
+	}
+	if err = textoffset.Verify(got); err != nil {
+		t.Error("verify -", err)
+	}
+	const wantText = `She said "hi" to him. They left.  `
+	if got.GetText() != wantText {
+		t.Errorf("got text %q; want %q", got.GetText(), wantText)
+	}
+
+	// Zoe, She, They -> She, They (representative Zoe is dropped);
+	// Tom, him -> him.
+	wantChains := []*pb.CorefChain{
+		{
+			ChainID:        proto.Int32(1),
+			Representative: proto.Uint32(0),
+			Mention: []*pb.CorefChain_CorefMention{
+				testdoc.NewCorefMention(2, 0, 0),
+				testdoc.NewCorefMention(3, 1, 0),
+			},
+		},
+		{
+			ChainID:        proto.Int32(4),
+			Representative: proto.Uint32(0),
+			Mention: []*pb.CorefChain_CorefMention{
+				testdoc.NewCorefMention(5, 0, 4),
+			},
+		},
+	}
+	if len(got.GetCorefChain()) != len(wantChains) {
+		t.Fatalf("got %d chains; want %d",
+			len(got.GetCorefChain()), len(wantChains))
+	}
+	for i := range wantChains {
+		if !proto.Equal(got.GetCorefChain()[i], wantChains[i]) {
+			t.Errorf("chain#%d - got %v; want %v",
+				i, got.GetCorefChain()[i], wantChains[i])
+		}
+	}
+
+	// Zoe, Tom, Paris, She, him, They -> She, him, They.
+	mentions := got.GetMentions()
+	if len(mentions) != 3 {
+		t.Fatalf("got %d entity mentions; want 3", len(mentions))
+	}
+	for i, m := range mentions {
+		if m.GetEntityMentionIndex() != uint32(i) {
+			t.Errorf("entity mention#%d - got index %d",
+				i, m.GetEntityMentionIndex())
+		}
+		if m.CanonicalEntityMentionIndex != nil {
+			t.Errorf("entity mention#%d - got canonical index %d; want unset",
+				i, m.GetCanonicalEntityMentionIndex())
+		}
+	}
+	if s := mentions[2].GetSentenceIndex(); s != 1 {
+		t.Errorf("got sentence index %d of They; want 1", s)
+	}
+	if i := got.Sentence[1].Token[0].GetEntityMentionIndex(); i != 2 {
+		t.Errorf("got entity mention index %d of They; want 2", i)
+	}
+	if n := len(got.GetMentionsForCoref()); n != 3 {
+		t.Errorf("got %d coref mentions; want 3", n)
+	}
+	if i := got.Sentence[0].Token[4].GetCorefMentionIndex(); len(i) != 1 || i[0] != 2 {
+		t.Errorf("got coref mention indexes %v of him; want [2]", i)
+	}
+
+	for i, sentence := range got.GetSentence() {
+		for _, node := range sentence.GetBasicDependencies().GetNode() {
+			if node.GetSentenceIndex() != uint32(i) {
+				t.Errorf("sentence#%d - got node sentence index %d",
+					i, node.GetSentenceIndex())
+			}
+		}
+	}
+
+	wantQuote := &pb.Quote{
+		Text:          proto.String(`"hi"`),
+		Begin:         proto.Uint32(9),
+		End:           proto.Uint32(13),
+		SentenceBegin: proto.Uint32(0),
+		SentenceEnd:   proto.Uint32(0),
+		TokenBegin:    proto.Uint32(2),
+		TokenEnd:      proto.Uint32(3),
+		Index:         proto.Uint32(0),
+	}
+	if len(got.GetQuote()) != 1 || !proto.Equal(got.GetQuote()[0], wantQuote) {
+		t.Errorf("got quotes %v; want [%v]", got.GetQuote(), wantQuote)
+	}
+	if i := got.Sentence[0].Token[2].GetQuotationIndex(); i != 0 {
+		t.Errorf("got quotation index %d; want 0", i)
+	}
+
+	// The first section (sentences 0 and 1) is clipped,
+	// and the second one (sentences 2 and 3) is kept as it has sentence 2.
+	sections := got.GetSections()
+	if len(sections) != 2 {
+		t.Fatalf("got %d sections; want 2", len(sections))
+	}
+	for i, want := range [][3]uint32{{0, 21, 0}, {22, 34, 1}} {
+		s := sections[i]
+		if s.GetCharBegin() != want[0] || s.GetCharEnd() != want[1] ||
+			len(s.GetSentenceIndexes()) != 1 || s.GetSentenceIndexes()[0] != want[2] {
+			t.Errorf("section#%d - got [%d, %d) with sentences %v; want [%d, %d) with [%d]",
+				i, s.GetCharBegin(), s.GetCharEnd(), s.GetSentenceIndexes(),
+				want[0], want[1], want[2])
+		}
+	}
+}
+
+func TestSliceSentences_SentenceEntityMentions(t *testing.T) {
+	doc := NewRichDocument()
+	doc.Mentions = nil // entity mentions are only in the sentences
+	doc.CorefMentionToEntityMentionMappings = nil
+	doc.EntityMentionToCorefMentionMappings = nil
+	got, err := docutil.SliceSentences(doc, 1, 3) // She said "hi" to him. They left.
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = docutil.Validate(got); err != nil {
+		t.Error("validate -", err)
+	}
+	// Zoe, Tom, Paris, She, him, They -> She, him, They.
+	for _, tc := range []struct {
+		sentence, token int
+		want            uint32
+	}{
+		{0, 0, 0}, // She
+		{0, 4, 1}, // him
+		{1, 0, 2}, // They
+	} {
+		token := got.Sentence[tc.sentence].Token[tc.token]
+		if token.EntityMentionIndex == nil || token.GetEntityMentionIndex() != tc.want {
+			t.Errorf("sentence#%d, token#%d - got entity mention index %v; want %d",
+				tc.sentence, tc.token, token.EntityMentionIndex, tc.want)
+		}
+	}
+	if m := got.Sentence[1].GetMentions(); len(m) != 1 || m[0].GetEntityMentionIndex() != 2 {
+		t.Errorf("got entity mentions %v of sentence#1; want index 2", m)
+	}
+}
+
+func TestSliceSentences_MergeRich(t *testing.T) {
+	doc := NewRichDocument()
+	n := len(doc.GetSentence())
+	for k := 0; k <= n; k++ {
+		former, err := docutil.SliceSentences(doc, 0, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		latter, err := docutil.SliceSentences(doc, k, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, part := range []*pb.Document{former, latter} {
+			if err = docutil.Validate(part); err != nil {
+				t.Errorf("k=%d - validate slice - %v", k, err)
+			}
+		}
+		merged, err := docutil.Merge(former, latter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = docutil.Validate(merged); err != nil {
+			t.Errorf("k=%d - validate merged - %v", k, err)
+		}
+		if err = textoffset.Verify(merged); err != nil {
+			t.Errorf("k=%d - verify merged - %v", k, err)
+		}
+		if k == 0 || k == n {
+			// Nothing is split, so the original document is restored.
+			if !proto.Equal(merged, doc) {
+				t.Errorf("k=%d - got %v\nwant %v", k, merged, doc)
+			}
+		}
+	}
+}
+
+func TestSliceSentences_Invalid(t *testing.T) {
+	doc := testdoc.Tokenize(RichText)
+	for _, r := range [][2]int{{-1, 1}, {2, 1}, {0, 5}} {
+		if _, err := docutil.SliceSentences(doc, r[0], r[1]); err == nil {
+			t.Errorf("[%d, %d) - got nil error", r[0], r[1])
+		}
+	}
+	if _, err := docutil.SliceSentences(nil, 0, 0); err == nil {
+		t.Error("nil document - got nil error")
+	}
+}
+
+func TestSliceChars(t *testing.T) {
+	doc := testdoc.Tokenize(RichText)
+	testCases := []struct {
+		begin, end int
+		want       [2]int // want is the sentence range.
+	}{
+		{0, 0, [2]int{0, 0}},
+		{0, 3, [2]int{0, 1}},
+		{2, 2, [2]int{0, 1}},
+		{10, 30, [2]int{0, 2}},
+		{23, 24, [2]int{1, 1}},
+		{24, 24, [2]int{1, 2}},
+		{24, 25, [2]int{1, 2}},
+		{56, 57, [2]int{3, 3}},
+		{57, 58, [2]int{3, 3}},
+		{0, len(RichText), [2]int{0, 4}},
+		{len(RichText), len(RichText), [2]int{4, 4}},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("[%d, %d)", tc.begin, tc.end), func(t *testing.T) {
+			got, err := docutil.SliceChars(doc, tc.begin, tc.end)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := docutil.SliceSentences(doc, tc.want[0], tc.want[1])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(got, want) {
+				t.Errorf("got %v\nwant %v", got, want)
+			}
+		})
+	}
+	if _, err := docutil.SliceChars(doc, 0, len(RichText)+1); err == nil {
+		t.Error("got nil error for an out-of-range end")
+	}
+}
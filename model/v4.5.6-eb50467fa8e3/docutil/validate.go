@@ -0,0 +1,356 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package docutil
+
+import (
+	"fmt"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/textoffset"
+)
+
+// Validate checks the consistency of the cross-references
+// in the specified document, including:
+//   - the sentence indexes and token offsets of sentences;
+//   - the token indexes and character offsets of tokens;
+//   - the sentence indexes of dependency graph nodes;
+//   - the sentence indexes and token bounds of coreference chain mentions,
+//     and the representatives of coreference chains;
+//   - the sentence indexes, token bounds, and indexes of entity mentions,
+//     and the entity mention indexes of tokens;
+//   - the sentence numbers of coreference mentions (mentionsForCoref),
+//     the coreference mention indexes of tokens, and the mappings
+//     between entity mentions and coreference mentions;
+//   - the character, token, and sentence bounds of quotes;
+//   - the character ranges and sentence indexes of sections.
+//
+// Optional fields that are not set are not checked.
+//
+// It returns nil if the document is consistent,
+// and otherwise an error describing the first inconsistency found.
+func Validate(doc *pb.Document) error {
+	if doc == nil {
+		return gogoerrors.AutoNew("document is nil")
+	}
+	v := &validator{
+		doc:          doc,
+		numChars:     uint32(textoffset.NewIndex(doc.GetText()).NumChars()),
+		numSentences: uint32(len(doc.GetSentence())),
+		numEntity:    uint32(numEntityMentions(doc)),
+		numCoref:     uint32(len(doc.GetMentionsForCoref())),
+	}
+	for i, sentence := range doc.GetSentence() {
+		if where, problem := v.sentence(sentence, uint32(i)); problem != "" {
+			return gogoerrors.AutoNew(where + ": " + problem)
+		}
+	}
+	for i, chain := range doc.GetCorefChain() {
+		if problem := v.corefChain(chain); problem != "" {
+			return gogoerrors.AutoNew(fmt.Sprintf(
+				"coref chain#%d: %s", i, problem))
+		}
+	}
+	for i, m := range doc.GetMentions() {
+		problem := v.nerMention(m)
+		if problem == "" && m.EntityMentionIndex != nil &&
+			m.GetEntityMentionIndex() != uint32(i) {
+			problem = fmt.Sprintf(
+				"got entity mention index %d", m.GetEntityMentionIndex())
+		}
+		if problem != "" {
+			return gogoerrors.AutoNew(fmt.Sprintf(
+				"entity mention#%d: %s", i, problem))
+		}
+	}
+	for i, m := range doc.GetMentionsForCoref() {
+		if m.SentNum != nil && (m.GetSentNum() < 0 ||
+			uint32(m.GetSentNum()) >= v.numSentences) {
+			return gogoerrors.AutoNew(fmt.Sprintf(
+				"coref mention#%d: sentence number %d out of range [0, %d)",
+				i, m.GetSentNum(), v.numSentences))
+		}
+	}
+	if problem := checkMappings(doc.GetCorefMentionToEntityMentionMappings(),
+		v.numCoref, v.numEntity); problem != "" {
+		return gogoerrors.AutoNew(
+			"coref mention to entity mention mappings: " + problem)
+	}
+	if problem := checkMappings(doc.GetEntityMentionToCorefMentionMappings(),
+		v.numEntity, v.numCoref); problem != "" {
+		return gogoerrors.AutoNew(
+			"entity mention to coref mention mappings: " + problem)
+	}
+	for i, q := range doc.GetQuote() {
+		if problem := v.quote(q); problem != "" {
+			return gogoerrors.AutoNew(fmt.Sprintf("quote#%d: %s", i, problem))
+		}
+	}
+	for i, section := range doc.GetSections() {
+		if problem := v.section(section); problem != "" {
+			return gogoerrors.AutoNew(fmt.Sprintf("section#%d: %s", i, problem))
+		}
+	}
+	return nil
+}
+
+// validator checks the parts of a document for Validate.
+//
+// Its methods return a description of the first inconsistency found,
+// or an empty string if there is none.
+type validator struct {
+	doc          *pb.Document
+	numChars     uint32 // numChars is the length of the text in UTF-16 code units.
+	numSentences uint32 // numSentences is the number of sentences.
+	numTokens    uint32 // numTokens is the number of tokens in the sentences checked so far.
+	numEntity    uint32 // numEntity is the number of entity mentions.
+	numCoref     uint32 // numCoref is the number of coreference mentions.
+}
+
+// sentence checks the specified sentence, whose index is i,
+// and the tokens, dependency graphs, and entity mentions in it.
+//
+// It also returns where the inconsistency is found.
+func (v *validator) sentence(sentence *pb.Sentence, i uint32) (where, problem string) {
+	where = fmt.Sprintf("sentence#%d", i)
+	if sentence.SentenceIndex != nil && sentence.GetSentenceIndex() != i {
+		return where, fmt.Sprintf(
+			"got sentence index %d", sentence.GetSentenceIndex())
+	}
+	tokens := sentence.GetToken()
+	begin, end := sentence.GetTokenOffsetBegin(), sentence.GetTokenOffsetEnd()
+	if begin != v.numTokens || end-begin != uint32(len(tokens)) {
+		return where, fmt.Sprintf("got token offsets [%d, %d); want [%d, %d)",
+			begin, end, v.numTokens, v.numTokens+uint32(len(tokens)))
+	}
+	problem = v.charRange(
+		sentence.CharacterOffsetBegin, sentence.CharacterOffsetEnd)
+	if problem != "" {
+		return
+	}
+	for j, token := range tokens {
+		if problem = v.token(token, begin+uint32(j)); problem != "" {
+			return fmt.Sprintf("token#%d in %s", j, where), problem
+		}
+	}
+	v.numTokens = end
+	for _, g := range []*pb.DependencyGraph{
+		sentence.GetBasicDependencies(),
+		sentence.GetCollapsedDependencies(),
+		sentence.GetCollapsedCCProcessedDependencies(),
+		sentence.GetAlternativeDependencies(),
+		sentence.GetEnhancedDependencies(),
+		sentence.GetEnhancedPlusPlusDependencies(),
+	} {
+		for _, node := range g.GetNode() {
+			if node.GetSentenceIndex() != i {
+				return where, fmt.Sprintf(
+					"dependency graph node %d has sentence index %d",
+					node.GetIndex(), node.GetSentenceIndex())
+			}
+			if node.GetIndex() > uint32(len(tokens)) {
+				return where, fmt.Sprintf(
+					"dependency graph node index %d out of range [1, %d]",
+					node.GetIndex(), len(tokens))
+			}
+		}
+	}
+	for j, m := range sentence.GetMentions() {
+		if m.SentenceIndex != nil && m.GetSentenceIndex() != i {
+			return where, fmt.Sprintf(
+				"entity mention#%d has sentence index %d",
+				j, m.GetSentenceIndex())
+		}
+		if m.EntityMentionIndex != nil && m.GetEntityMentionIndex() >= v.numEntity {
+			return where, fmt.Sprintf(
+				"entity mention#%d has index %d out of range [0, %d)",
+				j, m.GetEntityMentionIndex(), v.numEntity)
+		}
+	}
+	return where, ""
+}
+
+// token checks the specified token, whose document-level index is i.
+func (v *validator) token(token *pb.Token, i uint32) string {
+	if token.TokenBeginIndex != nil && token.GetTokenBeginIndex() != i {
+		return fmt.Sprintf(
+			"got token begin index %d; want %d", token.GetTokenBeginIndex(), i)
+	}
+	if token.TokenEndIndex != nil && token.GetTokenEndIndex() <= i {
+		return fmt.Sprintf("token end index %d is not greater than %d",
+			token.GetTokenEndIndex(), i)
+	}
+	if problem := v.charRange(token.BeginChar, token.EndChar); problem != "" {
+		return problem
+	}
+	if token.EntityMentionIndex != nil && token.GetEntityMentionIndex() >= v.numEntity {
+		return fmt.Sprintf("entity mention index %d out of range [0, %d)",
+			token.GetEntityMentionIndex(), v.numEntity)
+	}
+	for _, j := range token.GetCorefMentionIndex() {
+		if j >= v.numCoref {
+			return fmt.Sprintf(
+				"coref mention index %d out of range [0, %d)", j, v.numCoref)
+		}
+	}
+	return ""
+}
+
+// corefChain checks the specified coreference chain.
+func (v *validator) corefChain(chain *pb.CorefChain) string {
+	mentions := chain.GetMention()
+	if len(mentions) == 0 {
+		return "no mention"
+	}
+	if chain.GetRepresentative() >= uint32(len(mentions)) {
+		return fmt.Sprintf("representative %d out of range [0, %d)",
+			chain.GetRepresentative(), len(mentions))
+	}
+	for i, m := range mentions {
+		problem := v.sentenceSpan(
+			m.SentenceIndex, m.GetBeginIndex(), m.GetEndIndex())
+		if problem != "" {
+			return fmt.Sprintf("mention#%d: %s", i, problem)
+		}
+	}
+	return ""
+}
+
+// nerMention checks the sentence index, token bounds,
+// and canonical entity mention index of the specified entity mention.
+func (v *validator) nerMention(m *pb.NERMention) string {
+	problem := v.sentenceSpan(m.SentenceIndex,
+		m.GetTokenStartInSentenceInclusive(), m.GetTokenEndInSentenceExclusive())
+	if problem != "" {
+		return problem
+	}
+	if m.CanonicalEntityMentionIndex != nil &&
+		m.GetCanonicalEntityMentionIndex() >= v.numEntity {
+		return fmt.Sprintf(
+			"canonical entity mention index %d out of range [0, %d)",
+			m.GetCanonicalEntityMentionIndex(), v.numEntity)
+	}
+	return ""
+}
+
+// quote checks the character, token, and sentence bounds
+// of the specified quote.
+func (v *validator) quote(q *pb.Quote) string {
+	if problem := v.charRange(q.Begin, q.End); problem != "" {
+		return problem
+	}
+	if q.SentenceBegin != nil || q.SentenceEnd != nil {
+		// The sentence end of a quote is inclusive.
+		b, e := q.GetSentenceBegin(), q.GetSentenceEnd()
+		if b > e || e >= v.numSentences {
+			return fmt.Sprintf(
+				"sentence bounds [%d, %d] invalid or out of range [0, %d)",
+				b, e, v.numSentences)
+		}
+	}
+	if q.TokenBegin != nil || q.TokenEnd != nil {
+		b, e := q.GetTokenBegin(), q.GetTokenEnd()
+		if b > e || e > v.numTokens {
+			return fmt.Sprintf(
+				"token bounds [%d, %d] invalid or out of range [0, %d]",
+				b, e, v.numTokens)
+		}
+	}
+	return ""
+}
+
+// section checks the character range, sentence indexes,
+// and quotes of the specified section.
+func (v *validator) section(section *pb.Section) string {
+	if problem := v.charRange(section.CharBegin, section.CharEnd); problem != "" {
+		return problem
+	}
+	for _, i := range section.GetSentenceIndexes() {
+		if i >= v.numSentences {
+			return fmt.Sprintf(
+				"sentence index %d out of range [0, %d)", i, v.numSentences)
+		}
+	}
+	for i, q := range section.GetQuotes() {
+		if problem := v.quote(q); problem != "" {
+			return fmt.Sprintf("quote#%d: %s", i, problem)
+		}
+	}
+	return ""
+}
+
+// charRange checks that the character range [*begin, *end)
+// is valid and in the text.
+// Either of begin and end may be nil.
+func (v *validator) charRange(begin, end *uint32) string {
+	switch {
+	case begin != nil && *begin > v.numChars:
+		return fmt.Sprintf(
+			"character offset %d out of range [0, %d]", *begin, v.numChars)
+	case end != nil && *end > v.numChars:
+		return fmt.Sprintf(
+			"character offset %d out of range [0, %d]", *end, v.numChars)
+	case begin != nil && end != nil && *begin > *end:
+		return fmt.Sprintf("character range [%d, %d) is invalid", *begin, *end)
+	}
+	return ""
+}
+
+// sentenceSpan checks that the sentence index is in range,
+// and the sentence-level token span [begin, end) is valid and
+// in the sentence.
+// If sentenceIndex is nil, only the span is checked.
+func (v *validator) sentenceSpan(sentenceIndex *uint32, begin, end uint32) string {
+	if begin > end {
+		return fmt.Sprintf("token span [%d, %d) is invalid", begin, end)
+	}
+	if sentenceIndex == nil {
+		return ""
+	}
+	if *sentenceIndex >= v.numSentences {
+		return fmt.Sprintf("sentence index %d out of range [0, %d)",
+			*sentenceIndex, v.numSentences)
+	}
+	n := len(v.doc.GetSentence()[*sentenceIndex].GetToken())
+	if end > uint32(n) {
+		return fmt.Sprintf("token span [%d, %d) out of sentence#%d with %d tokens",
+			begin, end, *sentenceIndex, n)
+	}
+	return ""
+}
+
+// checkMappings checks that mappings (such as
+// Document.corefMentionToEntityMentionMappings) has n elements
+// (or is empty), and each element is -1 or in [0, numTargets).
+func checkMappings(mappings []int32, n, numTargets uint32) string {
+	if len(mappings) == 0 {
+		return ""
+	}
+	if uint32(len(mappings)) != n {
+		return fmt.Sprintf("got %d mappings; want %d", len(mappings), n)
+	}
+	for i, t := range mappings {
+		if t < -1 || t >= int32(numTargets) {
+			return fmt.Sprintf(
+				"mapping#%d: target %d out of range [0, %d)", i, t, numTargets)
+		}
+	}
+	return ""
+}
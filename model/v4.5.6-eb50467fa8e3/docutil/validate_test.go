@@ -0,0 +1,109 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package docutil_test
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/docutil"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+func TestValidate(t *testing.T) {
+	if err := docutil.Validate(NewRichDocument()); err != nil {
+		t.Error("rich document -", err)
+	}
+	if err := docutil.Validate(nil); err == nil {
+		t.Error("nil document - got nil error")
+	}
+
+	testCases := []struct {
+		name    string
+		corrupt func(doc *pb.Document)
+	}{
+		{"sentence index", func(doc *pb.Document) {
+			doc.Sentence[1].SentenceIndex = proto.Uint32(2)
+		}},
+		{"token offset", func(doc *pb.Document) {
+			doc.Sentence[1].TokenOffsetBegin = proto.Uint32(5)
+		}},
+		{"token index", func(doc *pb.Document) {
+			doc.Sentence[2].Token[1].TokenBeginIndex = proto.Uint32(0)
+		}},
+		{"character offset", func(doc *pb.Document) {
+			doc.Sentence[3].Token[1].EndChar = proto.Uint32(100)
+		}},
+		{"dependency graph node", func(doc *pb.Document) {
+			doc.Sentence[1].BasicDependencies.Node[0].SentenceIndex = proto.Uint32(0)
+		}},
+		{"coref chain mention sentence", func(doc *pb.Document) {
+			doc.CorefChain[0].Mention[1].SentenceIndex = proto.Uint32(4)
+		}},
+		{"coref chain mention span", func(doc *pb.Document) {
+			doc.CorefChain[0].Mention[2].EndIndex = proto.Uint32(4)
+		}},
+		{"coref chain representative", func(doc *pb.Document) {
+			doc.CorefChain[1].Representative = proto.Uint32(2)
+		}},
+		{"entity mention index", func(doc *pb.Document) {
+			doc.Mentions[2].EntityMentionIndex = proto.Uint32(3)
+		}},
+		{"canonical entity mention index", func(doc *pb.Document) {
+			doc.Mentions[3].CanonicalEntityMentionIndex = proto.Uint32(6)
+		}},
+		{"token entity mention index", func(doc *pb.Document) {
+			doc.Sentence[3].Token[0].EntityMentionIndex = proto.Uint32(6)
+		}},
+		{"token coref mention index", func(doc *pb.Document) {
+			doc.Sentence[3].Token[0].CorefMentionIndex = []uint32{5}
+		}},
+		{"coref mention sentence", func(doc *pb.Document) {
+			doc.MentionsForCoref[0].SentNum = proto.Int32(-1)
+		}},
+		{"mappings length", func(doc *pb.Document) {
+			doc.CorefMentionToEntityMentionMappings = []int32{0}
+		}},
+		{"mappings target", func(doc *pb.Document) {
+			doc.EntityMentionToCorefMentionMappings[2] = 5
+		}},
+		{"quote sentence", func(doc *pb.Document) {
+			doc.Quote[0].SentenceEnd = proto.Uint32(0)
+		}},
+		{"quote token", func(doc *pb.Document) {
+			doc.Quote[0].TokenEnd = proto.Uint32(18)
+		}},
+		{"section sentence", func(doc *pb.Document) {
+			doc.Sections[1].SentenceIndexes = []uint32{2, 3, 4}
+		}},
+		{"section character", func(doc *pb.Document) {
+			doc.Sections[0].CharBegin = proto.Uint32(50)
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc := NewRichDocument()
+			tc.corrupt(doc)
+			if err := docutil.Validate(doc); err == nil {
+				t.Error("got nil error")
+			}
+		})
+	}
+}
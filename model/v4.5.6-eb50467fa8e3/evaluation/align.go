@@ -0,0 +1,124 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package evaluation
+
+import (
+	gogoerrors "github.com/donyori/gogo/errors"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/textoffset"
+)
+
+// charSpan is a span of the text in characters (UTF-16 code units),
+// including begin and excluding end.
+type charSpan struct {
+	begin, end uint32
+}
+
+// tokenSpan returns the span of the token.
+func tokenSpan(token *pb.Token) charSpan {
+	return charSpan{begin: token.GetBeginChar(), end: token.GetEndChar()}
+}
+
+// tokenKey identifies a token of a document by its span and,
+// as the pieces of a multi-word token share their span,
+// its 0-based position among the tokens with the same span.
+type tokenKey struct {
+	span  charSpan
+	piece int
+}
+
+// tokenKeys returns the keys of the tokens aligned by a,
+// indexed by their positions in the document.
+func tokenKeys(a *textoffset.Aligner) []tokenKey {
+	keys := make([]tokenKey, a.NumTokens())
+	for i := range keys {
+		keys[i].span = tokenSpan(a.Token(i))
+		if i > 0 && keys[i-1].span == keys[i].span {
+			keys[i].piece = keys[i-1].piece + 1
+		}
+	}
+	return keys
+}
+
+// alignment pairs the tokens of a gold document and a predicted document
+// with the same keys (see tokenKey),
+// i.e., the same spans and, for the pieces of multi-word tokens,
+// the same positions among the pieces.
+type alignment struct {
+	gold, predicted *textoffset.Aligner
+
+	// goldKeys and predictedKeys are the keys of
+	// the gold and predicted tokens, respectively.
+	goldKeys, predictedKeys []tokenKey
+
+	// predictedOf[i] is the position of the predicted token with
+	// the same key as the i-th gold token, or -1 if there is none.
+	predictedOf []int
+
+	// matched[j] reports whether the j-th predicted token
+	// has a gold token with the same key.
+	matched []bool
+}
+
+// align creates an alignment of the tokens of gold and predicted.
+//
+// It reports an error if either document is nil or invalid
+// (see textoffset.NewAligner), or the two documents have different texts.
+func align(gold, predicted *pb.Document) (*alignment, error) {
+	if gold == nil {
+		return nil, gogoerrors.AutoNew("gold document is nil")
+	} else if predicted == nil {
+		return nil, gogoerrors.AutoNew("predicted document is nil")
+	}
+	goldAligner, err := textoffset.NewAligner(gold)
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	predAligner, err := textoffset.NewAligner(predicted)
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	if goldAligner.Text() != predAligner.Text() {
+		return nil, gogoerrors.AutoNew(
+			"the gold and predicted documents have different texts")
+	}
+	a := &alignment{
+		gold:          goldAligner,
+		predicted:     predAligner,
+		goldKeys:      tokenKeys(goldAligner),
+		predictedKeys: tokenKeys(predAligner),
+		predictedOf:   make([]int, goldAligner.NumTokens()),
+		matched:       make([]bool, predAligner.NumTokens()),
+	}
+	positions := make(map[tokenKey]int, len(a.predictedKeys))
+	for j, key := range a.predictedKeys {
+		positions[key] = j
+	}
+	for i, key := range a.goldKeys {
+		j, ok := positions[key]
+		if !ok {
+			j = -1
+		} else {
+			a.matched[j] = true
+		}
+		a.predictedOf[i] = j
+	}
+	return a, nil
+}
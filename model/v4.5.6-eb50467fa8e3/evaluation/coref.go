@@ -0,0 +1,334 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package evaluation
+
+import (
+	"math"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/coref"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+// CorefMetric is the result of a coreference evaluation metric,
+// with fractional numerators and denominators of
+// the recall and the precision, as reported by
+// the CoNLL-2012 reference scorer.
+type CorefMetric struct {
+	RecallNumerator      float64 `json:"recallNumerator"`
+	RecallDenominator    float64 `json:"recallDenominator"`
+	PrecisionNumerator   float64 `json:"precisionNumerator"`
+	PrecisionDenominator float64 `json:"precisionDenominator"`
+}
+
+// Add adds the numerators and denominators of other to m.
+func (m *CorefMetric) Add(other CorefMetric) {
+	m.RecallNumerator += other.RecallNumerator
+	m.RecallDenominator += other.RecallDenominator
+	m.PrecisionNumerator += other.PrecisionNumerator
+	m.PrecisionDenominator += other.PrecisionDenominator
+}
+
+// Recall returns the recall, in [0, 1].
+//
+// It returns 0 if the recall denominator is 0.
+func (m CorefMetric) Recall() float64 {
+	if m.RecallDenominator == 0 {
+		return 0
+	}
+	return m.RecallNumerator / m.RecallDenominator
+}
+
+// Precision returns the precision, in [0, 1].
+//
+// It returns 0 if the precision denominator is 0.
+func (m CorefMetric) Precision() float64 {
+	if m.PrecisionDenominator == 0 {
+		return 0
+	}
+	return m.PrecisionNumerator / m.PrecisionDenominator
+}
+
+// F1 returns the F1 score, the harmonic mean of
+// the recall and the precision, in [0, 1].
+//
+// It returns 0 if both the recall and the precision are 0.
+func (m CorefMetric) F1() float64 {
+	r, p := m.Recall(), m.Precision()
+	if r+p == 0 {
+		return 0
+	}
+	return 2 * r * p / (r + p)
+}
+
+// CorefScore is the result of a coreference resolution evaluation.
+type CorefScore struct {
+	MUC    CorefMetric `json:"muc"`    // MUC is the link-based MUC metric.
+	BCubed CorefMetric `json:"bCubed"` // BCubed is the mention-based B-cubed metric.
+	CEAFm  CorefMetric `json:"ceafm"`  // CEAFm is the mention-based CEAF metric.
+	CEAFe  CorefMetric `json:"ceafe"`  // CEAFe is the entity-based CEAF metric.
+}
+
+// Add adds the numerators and denominators of other to s.
+func (s *CorefScore) Add(other CorefScore) {
+	s.MUC.Add(other.MUC)
+	s.BCubed.Add(other.BCubed)
+	s.CEAFm.Add(other.CEAFm)
+	s.CEAFe.Add(other.CEAFe)
+}
+
+// CoNLLF1 returns the CoNLL-2012 score,
+// the average F1 score of MUC, B-cubed, and CEAFe.
+func (s CorefScore) CoNLLF1() float64 {
+	return (s.MUC.F1() + s.BCubed.F1() + s.CEAFe.F1()) / 3
+}
+
+// Coref evaluates the coreference chains of the predicted document
+// against the gold document.
+//
+// The mentions are identified by their character offsets,
+// so a predicted mention matches a gold mention only if
+// they have the same span.
+// All chains are evaluated, including those with only one mention
+// (singletons).
+//
+// It reports an error if either document is nil or invalid
+// (see textoffset.NewAligner and coref.Chains),
+// or the two documents have different texts.
+func Coref(gold, predicted *pb.Document) (CorefScore, error) {
+	if _, err := align(gold, predicted); err != nil {
+		return CorefScore{}, gogoerrors.AutoWrap(err)
+	}
+	key, err := corefClusters(gold)
+	if err != nil {
+		return CorefScore{}, gogoerrors.AutoWrap(err)
+	}
+	response, err := corefClusters(predicted)
+	if err != nil {
+		return CorefScore{}, gogoerrors.AutoWrap(err)
+	}
+	var s CorefScore
+	s.MUC.RecallNumerator, s.MUC.RecallDenominator = muc(key, response)
+	s.MUC.PrecisionNumerator, s.MUC.PrecisionDenominator = muc(response, key)
+	s.BCubed.RecallNumerator, s.BCubed.RecallDenominator = bCubed(key, response)
+	s.BCubed.PrecisionNumerator, s.BCubed.PrecisionDenominator = bCubed(response, key)
+	s.CEAFm = ceaf(key, response, func(k, r cluster) float64 {
+		return float64(k.overlap(r))
+	}, func(c cluster) float64 {
+		return float64(len(c))
+	})
+	s.CEAFe = ceaf(key, response, func(k, r cluster) float64 {
+		return 2 * float64(k.overlap(r)) / float64(len(k)+len(r))
+	}, func(cluster) float64 {
+		return 1
+	})
+	return s, nil
+}
+
+// cluster is a coreference cluster as a set of mention spans.
+type cluster map[charSpan]struct{}
+
+// overlap returns the number of mentions in both c and other.
+func (c cluster) overlap(other cluster) int {
+	if len(c) > len(other) {
+		c, other = other, c
+	}
+	var n int
+	for m := range c {
+		if _, ok := other[m]; ok {
+			n++
+		}
+	}
+	return n
+}
+
+// corefClusters returns the non-empty coreference clusters of the document.
+func corefClusters(doc *pb.Document) ([]cluster, error) {
+	chains, err := coref.Chains(doc)
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	clusters := make([]cluster, 0, len(chains))
+	for _, chain := range chains {
+		if len(chain.Mentions) == 0 {
+			continue
+		}
+		c := make(cluster, len(chain.Mentions))
+		for _, m := range chain.Mentions {
+			c[charSpan{begin: m.BeginChar, end: m.EndChar}] = struct{}{}
+		}
+		clusters = append(clusters, c)
+	}
+	return clusters, nil
+}
+
+// muc returns the numerator and denominator of the MUC recall
+// of the clusters response against the clusters key.
+// Swapping key and response gives the MUC precision.
+//
+// For each key cluster K, the numerator adds |K| - |p(K)|,
+// where p(K) is the partition of K by the response clusters
+// (mentions in no response cluster form their own parts),
+// and the denominator adds |K| - 1.
+func muc(key, response []cluster) (numerator, denominator float64) {
+	clusterOf := make(map[charSpan]int)
+	for i, r := range response {
+		for m := range r {
+			clusterOf[m] = i
+		}
+	}
+	for _, k := range key {
+		parts := make(map[int]struct{}, len(k))
+		var unresolved int
+		for m := range k {
+			if i, ok := clusterOf[m]; ok {
+				parts[i] = struct{}{}
+			} else {
+				unresolved++
+			}
+		}
+		numerator += float64(len(k) - len(parts) - unresolved)
+		denominator += float64(len(k) - 1)
+	}
+	return
+}
+
+// bCubed returns the numerator and denominator of the B-cubed recall
+// of the clusters response against the clusters key.
+// Swapping key and response gives the B-cubed precision.
+//
+// For each key cluster K, the numerator adds the sum of
+// |K ∩ R|^2 / |K| over all response clusters R,
+// and the denominator adds |K|.
+func bCubed(key, response []cluster) (numerator, denominator float64) {
+	for _, k := range key {
+		var sum int
+		for _, r := range response {
+			n := k.overlap(r)
+			sum += n * n
+		}
+		numerator += float64(sum) / float64(len(k))
+		denominator += float64(len(k))
+	}
+	return
+}
+
+// ceaf returns the CEAF metric of the clusters response
+// against the clusters key with the specified similarity function phi.
+//
+// The numerators of the recall and precision are both the maximum total
+// similarity of a one-to-one alignment between the key and
+// response clusters.
+// The recall (precision) denominator is the sum of size
+// over the key (response) clusters.
+func ceaf(
+	key, response []cluster,
+	phi func(k, r cluster) float64,
+	size func(c cluster) float64,
+) CorefMetric {
+	weights := make([][]float64, len(key))
+	for i, k := range key {
+		weights[i] = make([]float64, len(response))
+		for j, r := range response {
+			weights[i][j] = phi(k, r)
+		}
+	}
+	best := maxWeightMatching(weights, len(response))
+	m := CorefMetric{RecallNumerator: best, PrecisionNumerator: best}
+	for _, k := range key {
+		m.RecallDenominator += size(k)
+	}
+	for _, r := range response {
+		m.PrecisionDenominator += size(r)
+	}
+	return m
+}
+
+// maxWeightMatching returns the maximum total weight of
+// a one-to-one matching between the rows and the columns
+// of the non-negative weight matrix w, which has m columns.
+//
+// It uses the Hungarian algorithm in O(n^2 m) time,
+// where n <= m is the smaller dimension.
+func maxWeightMatching(w [][]float64, m int) float64 {
+	n := len(w)
+	if n == 0 || m == 0 {
+		return 0
+	}
+	weight := func(i, j int) float64 { return w[i][j] }
+	if n > m {
+		n, m = m, n
+		weight = func(i, j int) float64 { return w[j][i] }
+	}
+	// Minimize the total cost -weight, with 1-based rows and columns.
+	// p[j] is the row assigned to the column j, or 0 if none.
+	u := make([]float64, n+1)
+	v := make([]float64, m+1)
+	p := make([]int, m+1)
+	way := make([]int, m+1)
+	minV := make([]float64, m+1)
+	used := make([]bool, m+1)
+	for i := 1; i <= n; i++ {
+		p[0] = i
+		var j0 int
+		for j := range minV {
+			minV[j], used[j] = math.Inf(1), false
+		}
+		for {
+			used[j0] = true
+			i0, delta, j1 := p[j0], math.Inf(1), 0
+			for j := 1; j <= m; j++ {
+				if used[j] {
+					continue
+				}
+				if cur := -weight(i0-1, j-1) - u[i0] - v[j]; cur < minV[j] {
+					minV[j], way[j] = cur, j0
+				}
+				if minV[j] < delta {
+					delta, j1 = minV[j], j
+				}
+			}
+			for j := 0; j <= m; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minV[j] -= delta
+				}
+			}
+			j0 = j1
+			if p[j0] == 0 {
+				break
+			}
+		}
+		for j0 != 0 {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+		}
+	}
+	var total float64
+	for j := 1; j <= m; j++ {
+		if p[j] != 0 {
+			total += weight(p[j]-1, j-1)
+		}
+	}
+	return total
+}
@@ -0,0 +1,186 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package evaluation_test
+
+import (
+	"math"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/evaluation"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/internal/testdoc"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+// Mentions in the coreference tests, as (sentence, token) of Text.
+var (
+	mZoe   = [2]uint32{0, 0}
+	mTom   = [2]uint32{0, 2}
+	mParis = [2]uint32{0, 4}
+	mShe   = [2]uint32{1, 0}
+	mHim   = [2]uint32{1, 2}
+)
+
+func TestCoref(t *testing.T) {
+	type recallPrecision [2]float64
+	testCases := []struct {
+		name          string
+		key, response [][][2]uint32
+		muc, bCubed   recallPrecision
+		ceafm, ceafe  recallPrecision
+	}{
+		{
+			name:     "identical",
+			key:      [][][2]uint32{{mZoe, mShe}, {mTom, mHim}},
+			response: [][][2]uint32{{mZoe, mShe}, {mTom, mHim}},
+			muc:      recallPrecision{1, 1},
+			bCubed:   recallPrecision{1, 1},
+			ceafm:    recallPrecision{1, 1},
+			ceafe:    recallPrecision{1, 1},
+		},
+		{
+			// K1 = {Zoe, She, Tom}, K2 = {him, Paris};
+			// R1 = {Zoe, She}, R2 = {Tom, him, Paris}.
+			name:     "moved mention",
+			key:      [][][2]uint32{{mZoe, mShe, mTom}, {mHim, mParis}},
+			response: [][][2]uint32{{mZoe, mShe}, {mTom, mHim, mParis}},
+			muc:      recallPrecision{2.0 / 3, 2.0 / 3},
+			bCubed:   recallPrecision{11.0 / 15, 11.0 / 15},
+			ceafm:    recallPrecision{0.8, 0.8},
+			ceafe:    recallPrecision{0.8, 0.8},
+		},
+		{
+			// K1 = {Zoe, She, Tom}, K2 = {him, Paris};
+			// R1 = {Zoe}, R2 = {She, Tom}, R3 = {him, Paris}.
+			name:     "split cluster",
+			key:      [][][2]uint32{{mZoe, mShe, mTom}, {mHim, mParis}},
+			response: [][][2]uint32{{mZoe}, {mShe, mTom}, {mHim, mParis}},
+			muc:      recallPrecision{2.0 / 3, 1},
+			bCubed:   recallPrecision{(5.0/3 + 2) / 5, 1},
+			ceafm:    recallPrecision{0.8, 0.8},
+			ceafe:    recallPrecision{0.9, 0.6},
+		},
+		{
+			// K = {Zoe, She}; R = {Zoe, She, him}.
+			name:     "extra mention",
+			key:      [][][2]uint32{{mZoe, mShe}},
+			response: [][][2]uint32{{mZoe, mShe, mHim}},
+			muc:      recallPrecision{1, 0.5},
+			bCubed:   recallPrecision{1, 4.0 / 9},
+			ceafm:    recallPrecision{1, 2.0 / 3},
+			ceafe:    recallPrecision{0.8, 0.8},
+		},
+		{
+			name:     "empty response",
+			key:      [][][2]uint32{{mZoe, mShe}},
+			response: nil,
+			muc:      recallPrecision{0, 0},
+			bCubed:   recallPrecision{0, 0},
+			ceafm:    recallPrecision{0, 0},
+			ceafe:    recallPrecision{0, 0},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			score, err := evaluation.Coref(
+				newCorefDocument(tc.key), newCorefDocument(tc.response))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, metric := range []struct {
+				name string
+				got  evaluation.CorefMetric
+				want recallPrecision
+			}{
+				{"MUC", score.MUC, tc.muc},
+				{"B-cubed", score.BCubed, tc.bCubed},
+				{"CEAFm", score.CEAFm, tc.ceafm},
+				{"CEAFe", score.CEAFe, tc.ceafe},
+			} {
+				r, p := metric.got.Recall(), metric.got.Precision()
+				if !almostEqual(r, metric.want[0]) ||
+					!almostEqual(p, metric.want[1]) {
+					t.Errorf("%s - got recall %v, precision %v; want %v, %v",
+						metric.name, r, p, metric.want[0], metric.want[1])
+				}
+			}
+		})
+	}
+}
+
+func TestCorefScore_Add(t *testing.T) {
+	key := newCorefDocument([][][2]uint32{{mZoe, mShe}, {mTom, mHim}})
+	response := newCorefDocument([][][2]uint32{{mZoe, mShe, mHim}})
+	var total evaluation.CorefScore
+	for _, docs := range [][2]*pb.Document{{key, key}, {key, response}} {
+		score, err := evaluation.Coref(docs[0], docs[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		total.Add(score)
+	}
+	// MUC: recall (2 + 1) / (2 + 2); precision (2 + 1) / (2 + 2).
+	want := evaluation.CorefMetric{
+		RecallNumerator:      3,
+		RecallDenominator:    4,
+		PrecisionNumerator:   3,
+		PrecisionDenominator: 4,
+	}
+	if total.MUC != want {
+		t.Errorf("got MUC %+v; want %+v", total.MUC, want)
+	}
+	if got := total.MUC.F1(); !almostEqual(got, 0.75) {
+		t.Errorf("got MUC F1 %v; want 0.75", got)
+	}
+	conll := (total.MUC.F1() + total.BCubed.F1() + total.CEAFe.F1()) / 3
+	if got := total.CoNLLF1(); !almostEqual(got, conll) {
+		t.Errorf("got CoNLL F1 %v; want %v", got, conll)
+	}
+}
+
+// newCorefDocument creates a document of Text
+// with the specified coreference clusters of single-token mentions.
+func newCorefDocument(clusters [][][2]uint32) *pb.Document {
+	doc := testdoc.Tokenize(Text)
+	var id int32
+	for i, c := range clusters {
+		chain := &pb.CorefChain{
+			ChainID:        proto.Int32(int32(i)),
+			Representative: proto.Uint32(0),
+		}
+		for _, m := range c {
+			chain.Mention = append(chain.Mention, &pb.CorefChain_CorefMention{
+				MentionID:     proto.Int32(id),
+				BeginIndex:    proto.Uint32(m[1]),
+				EndIndex:      proto.Uint32(m[1] + 1),
+				HeadIndex:     proto.Uint32(m[1]),
+				SentenceIndex: proto.Uint32(m[0]),
+			})
+			id++
+		}
+		doc.CorefChain = append(doc.CorefChain, chain)
+	}
+	return doc
+}
+
+// almostEqual reports whether a and b are equal within a small tolerance.
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
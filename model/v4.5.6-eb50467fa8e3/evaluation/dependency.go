@@ -0,0 +1,199 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package evaluation
+
+import (
+	gogoerrors "github.com/donyori/gogo/errors"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/depgraph"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/textoffset"
+)
+
+// RootDep is the relation name of the roots of dependency graphs,
+// used in DependencyScore.ByLabel.
+const RootDep = "root"
+
+// DependencyScore is the result of a dependency parsing evaluation.
+type DependencyScore struct {
+	// Unlabeled counts the gold tokens attached to the correct heads.
+	Unlabeled Accuracy `json:"unlabeled"`
+
+	// Labeled counts the gold tokens attached to the correct heads
+	// with the correct relations.
+	Labeled Accuracy `json:"labeled"`
+
+	// ByLabel are the counts of each relation:
+	// Gold is the number of gold tokens with the relation,
+	// Predicted is the number of predicted tokens with the relation,
+	// and Matched is the number of gold tokens with the relation
+	// attached correctly with the correct relation.
+	//
+	// The roots are counted with the relation RootDep.
+	ByLabel LabelScores `json:"byLabel"`
+}
+
+// Add adds the counts of other to s.
+func (s *DependencyScore) Add(other DependencyScore) {
+	s.Unlabeled.Add(other.Unlabeled)
+	s.Labeled.Add(other.Labeled)
+	addLabelScores(&s.ByLabel, other.ByLabel)
+}
+
+// UAS returns the unlabeled attachment score, in [0, 1].
+func (s DependencyScore) UAS() float64 {
+	return s.Unlabeled.Value()
+}
+
+// LAS returns the labeled attachment score, in [0, 1].
+func (s DependencyScore) LAS() float64 {
+	return s.Labeled.Value()
+}
+
+// Dependencies evaluates the basic dependencies
+// (the field basicDependencies of Sentence)
+// of the predicted document against the gold document.
+//
+// The heads are compared by the spans of their tokens,
+// so a head is correct only if it is a token
+// with the same character offsets as the gold head
+// (and, for a piece of a multi-word token, the same piece).
+// Gold tokens without a head, including the tokens of the sentences
+// without basic dependencies, are not evaluated.
+//
+// It reports an error if either document is nil or invalid
+// (see textoffset.NewAligner and depgraph.New),
+// or the two documents have different texts.
+func Dependencies(gold, predicted *pb.Document) (DependencyScore, error) {
+	a, err := align(gold, predicted)
+	if err != nil {
+		return DependencyScore{}, gogoerrors.AutoWrap(err)
+	}
+	goldAttachments, err := attachments(gold, a.gold, a.goldKeys)
+	if err != nil {
+		return DependencyScore{}, gogoerrors.AutoWrap(err)
+	}
+	predAttachments, err := attachments(predicted, a.predicted, a.predictedKeys)
+	if err != nil {
+		return DependencyScore{}, gogoerrors.AutoWrap(err)
+	}
+	s := DependencyScore{ByLabel: make(LabelScores)}
+	for i, j := range a.predictedOf {
+		goldAtt, ok := goldAttachments[i]
+		if !ok {
+			continue
+		}
+		s.Unlabeled.Total++
+		s.Labeled.Total++
+		s.ByLabel.count(goldAtt.dep, 0, 1, 0)
+		if j < 0 {
+			continue
+		}
+		predAtt, ok := predAttachments[j]
+		if !ok {
+			continue
+		}
+		s.ByLabel.count(predAtt.dep, 0, 0, 1)
+		if predAtt.head == goldAtt.head {
+			s.Unlabeled.Correct++
+			if predAtt.dep == goldAtt.dep {
+				s.Labeled.Correct++
+				s.ByLabel.count(goldAtt.dep, 1, 0, 0)
+			}
+		}
+	}
+	for j, ok := range a.matched {
+		if predAtt, ok2 := predAttachments[j]; !ok && ok2 {
+			s.ByLabel.count(predAtt.dep, 0, 0, 1)
+		}
+	}
+	return s, nil
+}
+
+// attachment is the head and the relation of a token.
+type attachment struct {
+	head tokenKey // the key of the head token, or rootHead for the roots
+	dep  string
+}
+
+// rootHead is the head of the roots in attachment.
+var rootHead = tokenKey{span: charSpan{begin: ^uint32(0), end: ^uint32(0)}}
+
+// attachments returns the attachments of the tokens in
+// the basic dependencies of the document, indexed by the token positions
+// in the document (see textoffset.Aligner).
+//
+// keys are the keys of the tokens (see tokenKeys),
+// used to identify the heads.
+//
+// Edges to copy nodes or empty nodes, and edges from the heads
+// without tokens are ignored.
+// If a token has more than one head, the last one is used.
+func attachments(
+	doc *pb.Document,
+	a *textoffset.Aligner,
+	keys []tokenKey,
+) (map[int]attachment, error) {
+	positions := make(map[textoffset.Location]int, a.NumTokens())
+	for i := 0; i < a.NumTokens(); i++ {
+		positions[a.Location(i)] = i
+	}
+	result := make(map[int]attachment, a.NumTokens())
+	for sentIdx, sentence := range doc.GetSentence() {
+		if depgraph.Basic.Of(sentence) == nil {
+			continue
+		}
+		g, err := depgraph.FromSentence(sentence, depgraph.Basic)
+		if err != nil {
+			return nil, gogoerrors.AutoWrap(err)
+		}
+		position := func(id depgraph.NodeID) (int, bool) {
+			if id.Copy != 0 || id.Empty != 0 || id.Index == 0 {
+				return -1, false
+			}
+			i, ok := positions[textoffset.Location{
+				Sentence: sentIdx,
+				Token:    int(id.Index) - 1,
+			}]
+			return i, ok
+		}
+		add := func(target depgraph.NodeID, att attachment) {
+			if i, ok := position(target); ok {
+				result[i] = att
+			}
+		}
+		for _, root := range g.Roots() {
+			add(root, attachment{head: rootHead, dep: RootDep})
+		}
+		for _, e := range g.Edges() {
+			if e.Source.Empty != 0 {
+				continue
+			}
+			if g.Token(e.Source) == nil {
+				continue
+			}
+			source := e.Source
+			source.Copy = 0 // a copy node is headed by its token
+			if i, ok := position(source); ok {
+				add(e.Target, attachment{head: keys[i], dep: e.Dep})
+			}
+		}
+	}
+	return result, nil
+}
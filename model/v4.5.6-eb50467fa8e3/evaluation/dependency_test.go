@@ -0,0 +1,181 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package evaluation_test
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/evaluation"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/internal/testdoc"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+func TestDependencies(t *testing.T) {
+	gold, predicted := newParsedDocument(), newParsedDocument()
+	g := predicted.Sentence[1].BasicDependencies
+	g.Edge[1].Source = proto.Uint32(1)  // obj(likes, him) -> obj(She, him)
+	g.Edge[2].Dep = proto.String("dep") // punct(likes, .) -> dep(likes, .)
+	score, err := evaluation.Dependencies(gold, predicted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (evaluation.Accuracy{Correct: 7, Total: 8}); score.Unlabeled != want {
+		t.Errorf("got unlabeled %+v; want %+v", score.Unlabeled, want)
+	}
+	if want := (evaluation.Accuracy{Correct: 6, Total: 8}); score.Labeled != want {
+		t.Errorf("got labeled %+v; want %+v", score.Labeled, want)
+	}
+	if uas, las := score.UAS(), score.LAS(); uas != 0.875 || las != 0.75 {
+		t.Errorf("got UAS %v, LAS %v; want 0.875, 0.75", uas, las)
+	}
+	checkLabelScores(t, score.ByLabel, evaluation.LabelScores{
+		evaluation.RootDep: {Matched: 2, Gold: 2, Predicted: 2},
+		"nsubj":            {Matched: 2, Gold: 2, Predicted: 2},
+		"obj":              {Matched: 1, Gold: 2, Predicted: 2},
+		"punct":            {Matched: 1, Gold: 2, Predicted: 1},
+		"dep":              {Matched: 0, Gold: 0, Predicted: 1},
+	})
+}
+
+func TestDependencies_MissingGraphs(t *testing.T) {
+	gold, predicted := newParsedDocument(), newParsedDocument()
+	gold.Sentence[0].BasicDependencies = nil      // not evaluated
+	predicted.Sentence[1].BasicDependencies = nil // all wrong
+	score, err := evaluation.Dependencies(gold, predicted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (evaluation.Accuracy{Correct: 0, Total: 4}); score.Unlabeled != want ||
+		score.Labeled != want {
+		t.Errorf("got unlabeled %+v, labeled %+v; want %+v",
+			score.Unlabeled, score.Labeled, want)
+	}
+	if got, want := score.ByLabel["nsubj"],
+		(evaluation.PRF{Matched: 0, Gold: 1, Predicted: 0}); got != want {
+		t.Errorf("nsubj - got %+v; want %+v", got, want)
+	}
+}
+
+func TestDependencies_Tokenization(t *testing.T) {
+	gold, predicted := newParsedDocument(), newParsedDocument()
+	// Join "She likes" and make it the root of the second sentence.
+	s := predicted.Sentence[1]
+	testdoc.JoinTokens(s, 0)
+	s.BasicDependencies = newDependencyGraph(1, 1, []dependencyEdge{
+		{1, 2, "obj"}, {1, 3, "punct"},
+	})
+	var total evaluation.DependencyScore
+	score, err := evaluation.Dependencies(gold, predicted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total.Add(score)
+	if want := (evaluation.Accuracy{Correct: 4, Total: 8}); total.Unlabeled != want {
+		t.Errorf("got unlabeled %+v; want %+v", total.Unlabeled, want)
+	}
+	if want := (evaluation.Accuracy{Correct: 4, Total: 8}); total.Labeled != want {
+		t.Errorf("got labeled %+v; want %+v", total.Labeled, want)
+	}
+	if got, want := total.ByLabel[evaluation.RootDep],
+		(evaluation.PRF{Matched: 1, Gold: 2, Predicted: 2}); got != want {
+		t.Errorf("root - got %+v; want %+v", got, want)
+	}
+}
+
+func TestDependencies_MultiWordToken(t *testing.T) {
+	// Voy de el mercado .
+	edges := []dependencyEdge{
+		{4, 2, "case"}, {4, 3, "det"}, {1, 4, "obl"}, {1, 5, "punct"},
+	}
+	gold, predicted := newMWTDocument(), newMWTDocument()
+	gold.Sentence[0].BasicDependencies = newDependencyGraph(0, 1, edges)
+	predicted.Sentence[0].BasicDependencies = newDependencyGraph(0, 1, edges)
+	score, err := evaluation.Dependencies(gold, predicted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (evaluation.Accuracy{Correct: 5, Total: 5}); score.Unlabeled != want ||
+		score.Labeled != want {
+		t.Errorf("got unlabeled %+v, labeled %+v; want %+v",
+			score.Unlabeled, score.Labeled, want)
+	}
+
+	// Attach el to de instead of mercado.
+	predicted.Sentence[0].BasicDependencies = newDependencyGraph(0, 1, []dependencyEdge{
+		{4, 2, "case"}, {2, 3, "det"}, {1, 4, "obl"}, {1, 5, "punct"},
+	})
+	score, err = evaluation.Dependencies(gold, predicted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (evaluation.Accuracy{Correct: 4, Total: 5}); score.Unlabeled != want {
+		t.Errorf("got unlabeled %+v; want %+v", score.Unlabeled, want)
+	}
+}
+
+// dependencyEdge is an edge from the 1-based token index source
+// to the 1-based token index target.
+type dependencyEdge struct {
+	source, target uint32
+	dep            string
+}
+
+// newParsedDocument creates a document of "Zoe met Tom. She likes him."
+// whose sentences have the same basic dependencies:
+// nsubj(2, 1), obj(2, 3), punct(2, 4), and root 2.
+func newParsedDocument() *pb.Document {
+	doc := testdoc.Tokenize("Zoe met Tom. She likes him.")
+	for i, sentence := range doc.Sentence {
+		sentence.BasicDependencies = newDependencyGraph(
+			uint32(i), 2, []dependencyEdge{
+				{2, 1, "nsubj"}, {2, 3, "obj"}, {2, 4, "punct"},
+			})
+	}
+	return doc
+}
+
+// newDependencyGraph creates a dependency graph of the sentence
+// with the specified root and edges,
+// whose nodes are the tokens referred to by the edges and the root.
+func newDependencyGraph(
+	sentenceIndex, root uint32,
+	edges []dependencyEdge,
+) *pb.DependencyGraph {
+	n := root
+	for _, e := range edges {
+		n = max(n, e.source, e.target)
+	}
+	g := &pb.DependencyGraph{Root: []uint32{root}}
+	for i := uint32(1); i <= n; i++ {
+		g.Node = append(g.Node, &pb.DependencyGraph_Node{
+			SentenceIndex: proto.Uint32(sentenceIndex),
+			Index:         proto.Uint32(i),
+		})
+	}
+	for _, e := range edges {
+		g.Edge = append(g.Edge, &pb.DependencyGraph_Edge{
+			Source: proto.Uint32(e.source),
+			Target: proto.Uint32(e.target),
+			Dep:    proto.String(e.dep),
+		})
+	}
+	return g
+}
@@ -0,0 +1,42 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package evaluation scores Stanford CoreNLP 4.5.6 annotations
+// against gold annotations.
+//
+// Each scoring function compares a predicted document with
+// a gold document over the same text:
+//   - POS and UPOS: accuracy of part-of-speech tags
+//     (the fields pos and coarseTag of Token);
+//   - NER: span-level precision, recall, and F1 score of named entities,
+//     taken from the entity mentions or from the BIO tags of the tokens;
+//   - Dependencies: unlabeled and labeled attachment scores (UAS and LAS)
+//     of the basic dependencies;
+//   - Coref: MUC, B-cubed, and CEAF scores of the coreference chains.
+//
+// The tokens of the two documents are aligned by their character offsets,
+// so the predicted document may be tokenized differently from the gold one.
+// The pieces of a multi-word token, which share their character offsets,
+// are aligned in order.
+// A gold token without a predicted token of the same span
+// counts as a wrong prediction.
+//
+// The scores of several documents can be accumulated with their method Add,
+// which gives the micro-averaged (corpus-level) scores.
+// All scores have per-label breakdowns except for the coreference scores.
+package evaluation
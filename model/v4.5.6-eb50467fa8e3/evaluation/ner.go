@@ -0,0 +1,195 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package evaluation
+
+import (
+	"fmt"
+	"strconv"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/mention"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+// NERSource specifies where the named entities are taken from.
+type NERSource int8
+
+const (
+	// NERMentions takes the named entities from the entity mentions
+	// (see github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/mention.Spans),
+	// with their types taken from the field ner of NERMention.
+	NERMentions NERSource = iota
+
+	// NERBIOTags takes the named entities from the BIO tags of the tokens
+	// (the field ner of Token) within each sentence.
+	//
+	// A tag "B-X" begins an entity of type X,
+	// a tag "I-X" continues an entity of type X,
+	// and a tag "O" or an empty tag is outside any entity.
+	// A tag "I-X" that does not follow a tag "B-X" or "I-X"
+	// begins an entity of type X, as conlleval does.
+	// A tag without a prefix, such as "PERSON" in the output of CoreNLP,
+	// is treated as "I-PERSON", so that consecutive tokens
+	// with the same tag form one entity.
+	NERBIOTags
+)
+
+// String returns the name of the source,
+// "NERMentions" or "NERBIOTags".
+func (s NERSource) String() string {
+	switch s {
+	case NERMentions:
+		return "NERMentions"
+	case NERBIOTags:
+		return "NERBIOTags"
+	}
+	return "NERSource(" + strconv.Itoa(int(s)) + ")"
+}
+
+// SpanScore is the result of a span-level evaluation,
+// such as named entity recognition.
+type SpanScore struct {
+	// Total are the counts of all spans.
+	// A predicted span matches a gold span if they have
+	// the same character offsets and the same label.
+	Total PRF `json:"total"`
+
+	// ByLabel are the counts of the spans of each label.
+	ByLabel LabelScores `json:"byLabel"`
+}
+
+// Add adds the counts of other to s.
+func (s *SpanScore) Add(other SpanScore) {
+	s.Total.Add(other.Total)
+	addLabelScores(&s.ByLabel, other.ByLabel)
+}
+
+// NER evaluates the named entities of the predicted document
+// against the gold document, taken from the specified source.
+//
+// It reports an error if either document is nil or invalid
+// (see textoffset.NewAligner and mention.Spans),
+// the two documents have different texts, or source is unknown.
+func NER(gold, predicted *pb.Document, source NERSource) (SpanScore, error) {
+	if source != NERMentions && source != NERBIOTags {
+		return SpanScore{}, gogoerrors.AutoNew(fmt.Sprintf(
+			"unknown NER source %v", source))
+	}
+	if _, err := align(gold, predicted); err != nil {
+		return SpanScore{}, gogoerrors.AutoWrap(err)
+	}
+	goldSpans, err := entities(gold, source)
+	if err != nil {
+		return SpanScore{}, gogoerrors.AutoWrap(err)
+	}
+	predSpans, err := entities(predicted, source)
+	if err != nil {
+		return SpanScore{}, gogoerrors.AutoWrap(err)
+	}
+	s := SpanScore{ByLabel: make(LabelScores)}
+	counts := make(map[labeledSpan]int, len(goldSpans))
+	for _, span := range goldSpans {
+		counts[span]++
+		s.Total.Gold++
+		s.ByLabel.count(span.label, 0, 1, 0)
+	}
+	for _, span := range predSpans {
+		s.Total.Predicted++
+		s.ByLabel.count(span.label, 0, 0, 1)
+		if counts[span] > 0 {
+			counts[span]--
+			s.Total.Matched++
+			s.ByLabel.count(span.label, 1, 0, 0)
+		}
+	}
+	return s, nil
+}
+
+// labeledSpan is a span of the text with a label.
+type labeledSpan struct {
+	charSpan
+	label string
+}
+
+// entities returns the named entities of the document
+// taken from the specified source.
+func entities(doc *pb.Document, source NERSource) ([]labeledSpan, error) {
+	if source == NERBIOTags {
+		return bioEntities(doc), nil
+	}
+	spans, err := mention.Spans(doc)
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	result := make([]labeledSpan, len(spans))
+	for i := range spans {
+		result[i] = labeledSpan{
+			charSpan: charSpan{begin: spans[i].BeginChar, end: spans[i].EndChar},
+			label:    spans[i].NER,
+		}
+	}
+	return result, nil
+}
+
+// bioEntities returns the named entities decoded from
+// the BIO tags of the tokens of the document.
+//
+// See NERBIOTags for details.
+func bioEntities(doc *pb.Document) []labeledSpan {
+	var result []labeledSpan
+	for _, sentence := range doc.GetSentence() {
+		var current *labeledSpan
+		for _, token := range sentence.GetToken() {
+			prefix, label := splitBIOTag(token.GetNer())
+			if current != nil &&
+				(prefix != "I" || label != current.label) {
+				result = append(result, *current)
+				current = nil
+			}
+			if label == "" {
+				continue
+			}
+			if current == nil {
+				current = &labeledSpan{charSpan: tokenSpan(token), label: label}
+			} else {
+				current.end = token.GetEndChar()
+			}
+		}
+		if current != nil {
+			result = append(result, *current)
+		}
+	}
+	return result
+}
+
+// splitBIOTag splits a BIO tag into its prefix ("B" or "I")
+// and its label.
+//
+// It returns an empty label for the tag "O" or an empty tag.
+// A tag without a prefix is treated as an "I" tag.
+func splitBIOTag(tag string) (prefix, label string) {
+	if tag == "" || tag == "O" {
+		return "", ""
+	}
+	if len(tag) > 2 && tag[1] == '-' && (tag[0] == 'B' || tag[0] == 'I') {
+		return tag[:1], tag[2:]
+	}
+	return "I", tag
+}
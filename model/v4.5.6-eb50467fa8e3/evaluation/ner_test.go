@@ -0,0 +1,149 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package evaluation_test
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/evaluation"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/internal/testdoc"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+func TestNER_Mentions(t *testing.T) {
+	// Mentions (sentence, token, ner).
+	gold := newMentionDocument(
+		mentionSpec{0, 0, "PERSON"},   // Zoe
+		mentionSpec{0, 2, "PERSON"},   // Tom
+		mentionSpec{0, 4, "LOCATION"}, // Paris
+	)
+	predicted := newMentionDocument(
+		mentionSpec{0, 0, "PERSON"},   // Zoe
+		mentionSpec{0, 2, "LOCATION"}, // Tom
+		mentionSpec{0, 4, "LOCATION"}, // Paris
+		mentionSpec{1, 0, "PERSON"},   // She
+	)
+	score, err := evaluation.NER(gold, predicted, evaluation.NERMentions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (evaluation.PRF{Matched: 2, Gold: 3, Predicted: 4}); score.Total != want {
+		t.Errorf("got total %+v; want %+v", score.Total, want)
+	}
+	if p, r := score.Total.Precision(), score.Total.Recall(); p != 0.5 || r != 2.0/3 {
+		t.Errorf("got precision %v, recall %v; want 0.5, 0.666...", p, r)
+	}
+	if got := score.Total.F1(); got != 4.0/7 {
+		t.Errorf("got F1 %v; want %v", got, 4.0/7)
+	}
+	checkLabelScores(t, score.ByLabel, evaluation.LabelScores{
+		"PERSON":   {Matched: 1, Gold: 2, Predicted: 2},
+		"LOCATION": {Matched: 1, Gold: 1, Predicted: 2},
+	})
+}
+
+func TestNER_BIOTags(t *testing.T) {
+	const text = "Ann Lee met Bob in New York."
+	gold := testdoc.Tokenize(text)
+	testdoc.SetNER(gold, 0, "B-PER", "I-PER", "O", "B-PER", "O", "B-LOC", "I-LOC", "O")
+	testCases := []struct {
+		name  string
+		tags  []string
+		total evaluation.PRF
+		want  evaluation.LabelScores
+	}{
+		{
+			name:  "same",
+			tags:  []string{"B-PER", "I-PER", "O", "B-PER", "O", "B-LOC", "I-LOC", "O"},
+			total: evaluation.PRF{Matched: 3, Gold: 3, Predicted: 3},
+			want: evaluation.LabelScores{
+				"PER": {Matched: 2, Gold: 2, Predicted: 2},
+				"LOC": {Matched: 1, Gold: 1, Predicted: 1},
+			},
+		},
+		{
+			name:  "without prefixes",
+			tags:  []string{"PER", "PER", "O", "PER", "", "LOC", "O", "O"},
+			total: evaluation.PRF{Matched: 2, Gold: 3, Predicted: 3},
+			want: evaluation.LabelScores{
+				"PER": {Matched: 2, Gold: 2, Predicted: 2},
+				"LOC": {Matched: 0, Gold: 1, Predicted: 1},
+			},
+		},
+		{
+			name:  "split and relabeled",
+			tags:  []string{"I-PER", "B-PER", "O", "B-PER", "O", "B-LOC", "I-ORG", "O"},
+			total: evaluation.PRF{Matched: 1, Gold: 3, Predicted: 5},
+			want: evaluation.LabelScores{
+				"PER": {Matched: 1, Gold: 2, Predicted: 3},
+				"LOC": {Matched: 0, Gold: 1, Predicted: 1},
+				"ORG": {Matched: 0, Gold: 0, Predicted: 1},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			predicted := testdoc.Tokenize(text)
+			testdoc.SetNER(predicted, 0, tc.tags...)
+			score, err := evaluation.NER(gold, predicted, evaluation.NERBIOTags)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if score.Total != tc.total {
+				t.Errorf("got total %+v; want %+v", score.Total, tc.total)
+			}
+			checkLabelScores(t, score.ByLabel, tc.want)
+		})
+	}
+}
+
+func TestNER_Error(t *testing.T) {
+	doc := newMentionDocument(mentionSpec{0, 0, "PERSON"})
+	if _, err := evaluation.NER(doc, doc, evaluation.NERSource(-1)); err == nil {
+		t.Error("unknown source - got nil error")
+	}
+	invalid := newMentionDocument(mentionSpec{2, 0, "PERSON"})
+	if _, err := evaluation.NER(doc, invalid, evaluation.NERMentions); err == nil {
+		t.Error("invalid mention - got nil error")
+	}
+}
+
+// mentionSpec specifies an entity mention of a single token.
+type mentionSpec struct {
+	sentence, token uint32
+	ner             string
+}
+
+// newMentionDocument creates a document of Text
+// with the specified entity mentions.
+func newMentionDocument(mentions ...mentionSpec) *pb.Document {
+	doc := testdoc.Tokenize(Text)
+	for i, m := range mentions {
+		doc.Mentions = append(doc.Mentions, &pb.NERMention{
+			SentenceIndex:                 proto.Uint32(m.sentence),
+			TokenStartInSentenceInclusive: proto.Uint32(m.token),
+			TokenEndInSentenceExclusive:   proto.Uint32(m.token + 1),
+			Ner:                           proto.String(m.ner),
+			EntityMentionIndex:            proto.Uint32(uint32(i)),
+		})
+	}
+	return doc
+}
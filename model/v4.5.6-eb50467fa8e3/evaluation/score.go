@@ -0,0 +1,112 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package evaluation
+
+// Accuracy is the result of a token-level evaluation.
+type Accuracy struct {
+	Correct int `json:"correct"` // Correct is the number of gold tokens predicted correctly.
+	Total   int `json:"total"`   // Total is the number of evaluated gold tokens.
+}
+
+// Add adds the counts of other to a.
+func (a *Accuracy) Add(other Accuracy) {
+	a.Correct += other.Correct
+	a.Total += other.Total
+}
+
+// Value returns the accuracy, in [0, 1].
+//
+// It returns 0 if there are no evaluated tokens.
+func (a Accuracy) Value() float64 {
+	if a.Total == 0 {
+		return 0
+	}
+	return float64(a.Correct) / float64(a.Total)
+}
+
+// PRF is the result of a precision-recall evaluation
+// by counting matched items.
+type PRF struct {
+	Matched   int `json:"matched"`   // Matched is the number of predicted items that match gold items.
+	Gold      int `json:"gold"`      // Gold is the number of gold items.
+	Predicted int `json:"predicted"` // Predicted is the number of predicted items.
+}
+
+// Add adds the counts of other to s.
+func (s *PRF) Add(other PRF) {
+	s.Matched += other.Matched
+	s.Gold += other.Gold
+	s.Predicted += other.Predicted
+}
+
+// Precision returns the precision, in [0, 1].
+//
+// It returns 0 if there are no predicted items.
+func (s PRF) Precision() float64 {
+	if s.Predicted == 0 {
+		return 0
+	}
+	return float64(s.Matched) / float64(s.Predicted)
+}
+
+// Recall returns the recall, in [0, 1].
+//
+// It returns 0 if there are no gold items.
+func (s PRF) Recall() float64 {
+	if s.Gold == 0 {
+		return 0
+	}
+	return float64(s.Matched) / float64(s.Gold)
+}
+
+// F1 returns the F1 score, in [0, 1].
+//
+// It returns 0 if there are neither gold nor predicted items.
+func (s PRF) F1() float64 {
+	if s.Gold+s.Predicted == 0 {
+		return 0
+	}
+	return 2 * float64(s.Matched) / float64(s.Gold+s.Predicted)
+}
+
+// LabelScores are the precision-recall counts of each label.
+type LabelScores map[string]PRF
+
+// addLabelScores adds the counts of each label in other to *s.
+//
+// It allocates *s if *s is nil and other is not empty.
+func addLabelScores(s *LabelScores, other LabelScores) {
+	if *s == nil && len(other) > 0 {
+		*s = make(LabelScores, len(other))
+	}
+	for label, score := range other {
+		prf := (*s)[label]
+		prf.Add(score)
+		(*s)[label] = prf
+	}
+}
+
+// count adds the specified counts to the label.
+func (s LabelScores) count(label string, matched, gold, predicted int) {
+	prf := s[label]
+	prf.Matched += matched
+	prf.Gold += gold
+	prf.Predicted += predicted
+	s[label] = prf
+}
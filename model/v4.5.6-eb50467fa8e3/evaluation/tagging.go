@@ -0,0 +1,114 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package evaluation
+
+import (
+	gogoerrors "github.com/donyori/gogo/errors"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+// TaggingScore is the result of a token-level tagging evaluation,
+// such as part-of-speech tagging.
+type TaggingScore struct {
+	// Accuracy is the accuracy over the evaluated gold tokens.
+	Accuracy Accuracy `json:"accuracy"`
+
+	// ByLabel are the counts of each tag:
+	// Gold is the number of gold tokens with the tag,
+	// Predicted is the number of predicted tokens with the tag,
+	// and Matched is the number of gold tokens with the tag
+	// predicted correctly.
+	ByLabel LabelScores `json:"byLabel"`
+}
+
+// Add adds the counts of other to s.
+func (s *TaggingScore) Add(other TaggingScore) {
+	s.Accuracy.Add(other.Accuracy)
+	addLabelScores(&s.ByLabel, other.ByLabel)
+}
+
+// POS evaluates the part-of-speech tags (the field pos of Token)
+// of the predicted document against the gold document.
+//
+// Gold tokens without a tag are not evaluated.
+//
+// It reports an error if either document is nil or invalid
+// (see textoffset.NewAligner), or the two documents have different texts.
+func POS(gold, predicted *pb.Document) (TaggingScore, error) {
+	s, err := tagging(gold, predicted, (*pb.Token).GetPos)
+	return s, gogoerrors.AutoWrap(err)
+}
+
+// UPOS evaluates the universal part-of-speech tags
+// (the field coarseTag of Token)
+// of the predicted document against the gold document.
+//
+// Gold tokens without a tag are not evaluated.
+//
+// It reports an error if either document is nil or invalid
+// (see textoffset.NewAligner), or the two documents have different texts.
+func UPOS(gold, predicted *pb.Document) (TaggingScore, error) {
+	s, err := tagging(gold, predicted, (*pb.Token).GetCoarseTag)
+	return s, gogoerrors.AutoWrap(err)
+}
+
+// tagging evaluates the tags obtained by the function tag.
+//
+// Predicted tokens without a gold counterpart
+// count as predictions of their tags.
+func tagging(
+	gold, predicted *pb.Document,
+	tag func(token *pb.Token) string,
+) (TaggingScore, error) {
+	a, err := align(gold, predicted)
+	if err != nil {
+		return TaggingScore{}, gogoerrors.AutoWrap(err)
+	}
+	s := TaggingScore{ByLabel: make(LabelScores)}
+	for i, j := range a.predictedOf {
+		goldTag := tag(a.gold.Token(i))
+		if goldTag == "" {
+			continue
+		}
+		s.Accuracy.Total++
+		s.ByLabel.count(goldTag, 0, 1, 0)
+		if j < 0 {
+			continue
+		}
+		predTag := tag(a.predicted.Token(j))
+		if predTag == "" {
+			continue
+		}
+		if predTag == goldTag {
+			s.Accuracy.Correct++
+			s.ByLabel.count(goldTag, 1, 0, 1)
+		} else {
+			s.ByLabel.count(predTag, 0, 0, 1)
+		}
+	}
+	for j, ok := range a.matched {
+		if !ok {
+			if predTag := tag(a.predicted.Token(j)); predTag != "" {
+				s.ByLabel.count(predTag, 0, 0, 1)
+			}
+		}
+	}
+	return s, nil
+}
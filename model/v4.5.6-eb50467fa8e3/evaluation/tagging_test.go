@@ -0,0 +1,201 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package evaluation_test
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/evaluation"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/internal/testdoc"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+// Text is the text of the documents in the tests.
+//
+// Its tokens are: Zoe met Tom in Paris . / She likes him .
+const Text = "Zoe met Tom in Paris. She likes him."
+
+func TestPOS(t *testing.T) {
+	gold, predicted := newTaggedDocument(), newTaggedDocument()
+	predicted.Sentence[0].Token[2].Pos = proto.String("NN")  // Tom
+	predicted.Sentence[1].Token[1].Pos = proto.String("NNS") // likes
+	score, err := evaluation.POS(gold, predicted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (evaluation.Accuracy{Correct: 8, Total: 10}); score.Accuracy != want {
+		t.Errorf("got accuracy %+v; want %+v", score.Accuracy, want)
+	}
+	if got := score.Accuracy.Value(); got != 0.8 {
+		t.Errorf("got accuracy value %v; want 0.8", got)
+	}
+	checkLabelScores(t, score.ByLabel, evaluation.LabelScores{
+		"NNP": {Matched: 2, Gold: 3, Predicted: 2},
+		"VBD": {Matched: 1, Gold: 1, Predicted: 1},
+		"IN":  {Matched: 1, Gold: 1, Predicted: 1},
+		".":   {Matched: 2, Gold: 2, Predicted: 2},
+		"PRP": {Matched: 2, Gold: 2, Predicted: 2},
+		"VBZ": {Matched: 0, Gold: 1, Predicted: 0},
+		"NN":  {Matched: 0, Gold: 0, Predicted: 1},
+		"NNS": {Matched: 0, Gold: 0, Predicted: 1},
+	})
+}
+
+func TestPOS_Tokenization(t *testing.T) {
+	gold, predicted := newTaggedDocument(), newTaggedDocument()
+	testdoc.JoinTokens(predicted.Sentence[0], 4) // Paris .
+	predicted.Sentence[0].Token[4].Pos = proto.String("NNP")
+	gold.Sentence[1].Token[3].Pos = nil // not evaluated
+	score, err := evaluation.POS(gold, predicted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (evaluation.Accuracy{Correct: 7, Total: 9}); score.Accuracy != want {
+		t.Errorf("got accuracy %+v; want %+v", score.Accuracy, want)
+	}
+	if got, want := score.ByLabel["NNP"],
+		(evaluation.PRF{Matched: 2, Gold: 3, Predicted: 3}); got != want {
+		t.Errorf("NNP - got %+v; want %+v", got, want)
+	}
+	if got, want := score.ByLabel["."],
+		(evaluation.PRF{Matched: 0, Gold: 1, Predicted: 0}); got != want {
+		t.Errorf(". - got %+v; want %+v", got, want)
+	}
+}
+
+func TestUPOS(t *testing.T) {
+	gold, predicted := newTaggedDocument(), newTaggedDocument()
+	for _, doc := range []*pb.Document{gold, predicted} {
+		for i, tag := range []string{"PROPN", "VERB", "PROPN", "ADP", "PROPN", "PUNCT"} {
+			doc.Sentence[0].Token[i].CoarseTag = proto.String(tag)
+		}
+	}
+	predicted.Sentence[0].Token[1].CoarseTag = proto.String("AUX")
+	score, err := evaluation.UPOS(gold, predicted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (evaluation.Accuracy{Correct: 5, Total: 6}); score.Accuracy != want {
+		t.Errorf("got accuracy %+v; want %+v", score.Accuracy, want)
+	}
+	if got, want := score.ByLabel["VERB"],
+		(evaluation.PRF{Matched: 0, Gold: 1, Predicted: 0}); got != want {
+		t.Errorf("VERB - got %+v; want %+v", got, want)
+	}
+}
+
+func TestUPOS_MultiWordToken(t *testing.T) {
+	gold, predicted := newMWTDocument(), newMWTDocument()
+	for _, doc := range []*pb.Document{gold, predicted} {
+		for i, tag := range []string{"VERB", "ADP", "DET", "NOUN", "PUNCT"} {
+			doc.Sentence[0].Token[i].CoarseTag = proto.String(tag)
+		}
+	}
+	score, err := evaluation.UPOS(gold, predicted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (evaluation.Accuracy{Correct: 5, Total: 5}); score.Accuracy != want {
+		t.Errorf("got accuracy %+v; want %+v", score.Accuracy, want)
+	}
+
+	// Swap the tags of the pieces de and el.
+	tokens := predicted.Sentence[0].Token
+	tokens[1].CoarseTag, tokens[2].CoarseTag = tokens[2].CoarseTag, tokens[1].CoarseTag
+	score, err = evaluation.UPOS(gold, predicted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (evaluation.Accuracy{Correct: 3, Total: 5}); score.Accuracy != want {
+		t.Errorf("swapped - got accuracy %+v; want %+v", score.Accuracy, want)
+	}
+}
+
+func TestTaggingScore_Add(t *testing.T) {
+	gold, predicted := newTaggedDocument(), newTaggedDocument()
+	predicted.Sentence[0].Token[2].Pos = proto.String("NN")
+	var total evaluation.TaggingScore
+	for i := 0; i < 2; i++ {
+		score, err := evaluation.POS(gold, predicted)
+		if err != nil {
+			t.Fatal(err)
+		}
+		total.Add(score)
+	}
+	if want := (evaluation.Accuracy{Correct: 18, Total: 20}); total.Accuracy != want {
+		t.Errorf("got accuracy %+v; want %+v", total.Accuracy, want)
+	}
+	if got, want := total.ByLabel["NNP"],
+		(evaluation.PRF{Matched: 4, Gold: 6, Predicted: 4}); got != want {
+		t.Errorf("NNP - got %+v; want %+v", got, want)
+	}
+}
+
+func TestPOS_Error(t *testing.T) {
+	doc := newTaggedDocument()
+	testCases := []struct {
+		name            string
+		gold, predicted *pb.Document
+	}{
+		{"gold nil", nil, doc},
+		{"predicted nil", doc, nil},
+		{"different texts", doc, testdoc.Tokenize("Zoe met Tom in Rome.")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := evaluation.POS(tc.gold, tc.predicted); err == nil {
+				t.Error("got nil error")
+			}
+		})
+	}
+}
+
+// newTaggedDocument creates a document of Text
+// with part-of-speech tags.
+func newTaggedDocument() *pb.Document {
+	doc := testdoc.Tokenize(Text)
+	testdoc.SetPOS(doc, 0, "NNP", "VBD", "NNP", "IN", "NNP", ".")
+	testdoc.SetPOS(doc, 1, "PRP", "VBZ", "PRP", ".")
+	return doc
+}
+
+// newMWTDocument creates a document of the Spanish text "Voy del mercado."
+// whose multi-word token "del" is split into the pieces "de" and "el".
+func newMWTDocument() *pb.Document {
+	doc := testdoc.New([]string{"Voy", "del", "mercado", "."})
+	testdoc.SplitMWT(doc, 0, 1, "de", "el")
+	return doc
+}
+
+// checkLabelScores checks whether got equals want.
+func checkLabelScores(t *testing.T, got, want evaluation.LabelScores) {
+	t.Helper()
+	for label, w := range want {
+		if g := got[label]; g != w {
+			t.Errorf("label %q - got %+v; want %+v", label, g, w)
+		}
+	}
+	for label := range got {
+		if _, ok := want[label]; !ok {
+			t.Errorf("got unexpected label %q", label)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package testdoc
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+// New creates a document with the specified sentences,
+// each of which is a list of words.
+//
+// The words are separated by a space,
+// except that no space is inserted before
+// the punctuation marks ".", ",", "!", "?", ";", ":", and "'s".
+// The sentences are separated by a space.
+//
+// New sets the document text, the sentence indexes, and
+// the token and character offsets of the sentences and tokens,
+// in the same way as CoreNLP.
+func New(sentences ...[]string) *pb.Document {
+	doc := new(pb.Document)
+	var text strings.Builder
+	var tokenIdx, charIdx, cpIdx uint32
+	for sentIdx, words := range sentences {
+		sentence := &pb.Sentence{
+			TokenOffsetBegin: U32(tokenIdx),
+			SentenceIndex:    U32(uint32(sentIdx)),
+		}
+		for i, word := range words {
+			before := " "
+			if i == 0 && sentIdx == 0 || i > 0 && noSpaceBefore(word) {
+				before = ""
+			}
+			text.WriteString(before)
+			charIdx += uint32(len(before))
+			cpIdx += uint32(len(before))
+			if i == 0 {
+				sentence.CharacterOffsetBegin = U32(charIdx)
+			}
+			token := &pb.Token{
+				Word:                 S(word),
+				OriginalText:         S(word),
+				Value:                S(word),
+				Before:               S(before),
+				BeginChar:            U32(charIdx),
+				CodepointOffsetBegin: U32(cpIdx),
+				TokenBeginIndex:      U32(tokenIdx),
+				TokenEndIndex:        U32(tokenIdx + 1),
+			}
+			text.WriteString(word)
+			charIdx += uint32(len(utf16.Encode([]rune(word))))
+			cpIdx += uint32(utf8.RuneCountInString(word))
+			token.EndChar = U32(charIdx)
+			token.CodepointOffsetEnd = U32(cpIdx)
+			sentence.Token = append(sentence.Token, token)
+			tokenIdx++
+		}
+		sentence.TokenOffsetEnd = U32(tokenIdx)
+		sentence.CharacterOffsetEnd = U32(charIdx)
+		doc.Sentence = append(doc.Sentence, sentence)
+	}
+	// Set the field after of each token to the field before of the next.
+	var prev *pb.Token
+	for _, sentence := range doc.Sentence {
+		for _, token := range sentence.Token {
+			if prev != nil {
+				prev.After = S(token.GetBefore())
+			}
+			prev = token
+		}
+	}
+	if prev != nil {
+		prev.After = S("")
+	}
+	doc.Text = S(text.String())
+	return doc
+}
+
+// Tokenize creates a document with the specified text,
+// tokenized by a simple rule, like a CoreNLP server would return.
+//
+// The tokens are separated by whitespace,
+// and the punctuation marks ".", ",", "!", "?", ";", and ":"
+// at the end of a word are split into separate tokens.
+// A sentence ends after a token ".", "!", or "?", or at the end of the text.
+//
+// Tokenize sets the sentence indexes, the token and character offsets,
+// and the fields before and after of the tokens,
+// with the whitespace taken from the text.
+func Tokenize(text string) *pb.Document {
+	type span struct{ begin, end int }
+	var spans []span
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if unicode.IsSpace(r) {
+			i += size
+			continue
+		}
+		begin := i
+		for i < len(text) {
+			r, size = utf8.DecodeRuneInString(text[i:])
+			if unicode.IsSpace(r) {
+				break
+			}
+			i += size
+		}
+		end := i
+		for end-1 > begin && strings.IndexByte(".,!?;:", text[end-1]) >= 0 {
+			end--
+		}
+		spans = append(spans, span{begin, end})
+		for j := end; j < i; j++ {
+			spans = append(spans, span{j, j + 1})
+		}
+	}
+
+	doc := &pb.Document{Text: S(text)}
+	var sentence *pb.Sentence
+	for i, sp := range spans {
+		if sentence == nil {
+			sentence = &pb.Sentence{
+				TokenOffsetBegin: U32(uint32(i)),
+				SentenceIndex:    U32(uint32(len(doc.Sentence))),
+				CharacterOffsetBegin: U32(
+					uint32(len(utf16.Encode([]rune(text[:sp.begin]))))),
+			}
+		}
+		before, after := text[:sp.begin], text[sp.end:]
+		if i > 0 {
+			before = text[spans[i-1].end:sp.begin]
+		}
+		if i < len(spans)-1 {
+			after = text[sp.end:spans[i+1].begin]
+		}
+		word := text[sp.begin:sp.end]
+		beginChar := uint32(len(utf16.Encode([]rune(text[:sp.begin]))))
+		endChar := uint32(len(utf16.Encode([]rune(text[:sp.end]))))
+		sentence.Token = append(sentence.Token, &pb.Token{
+			Word:                 S(word),
+			OriginalText:         S(word),
+			Value:                S(word),
+			Before:               S(before),
+			After:                S(after),
+			BeginChar:            U32(beginChar),
+			EndChar:              U32(endChar),
+			CodepointOffsetBegin: U32(uint32(utf8.RuneCountInString(text[:sp.begin]))),
+			CodepointOffsetEnd:   U32(uint32(utf8.RuneCountInString(text[:sp.end]))),
+			TokenBeginIndex:      U32(uint32(i)),
+			TokenEndIndex:        U32(uint32(i + 1)),
+		})
+		if word == "." || word == "!" || word == "?" || i == len(spans)-1 {
+			sentence.TokenOffsetEnd = U32(uint32(i + 1))
+			sentence.CharacterOffsetEnd = U32(endChar)
+			doc.Sentence = append(doc.Sentence, sentence)
+			sentence = nil
+		}
+	}
+	return doc
+}
+
+// SplitMWT splits the token at the specified position
+// in the sentence with the specified index into the specified pieces,
+// as CoreNLP does for a multi-word token
+// (such as the Spanish word "del" split into "de" and "el").
+//
+// The pieces share the character and code point offsets of the token,
+// and the token's text is recorded in their field mwtText.
+// The field before of the first piece and the field after of the last piece
+// are taken from the token; those between the pieces are empty.
+// SplitMWT then renumbers the token indexes of the document.
+//
+// It panics if fewer than two pieces are specified.
+func SplitMWT(doc *pb.Document, sentenceIndex, tokenIndex int, pieces ...string) {
+	if len(pieces) < 2 {
+		panic("fewer than two pieces")
+	}
+	sentence := doc.Sentence[sentenceIndex]
+	token := sentence.Token[tokenIndex]
+	split := make([]*pb.Token, len(pieces))
+	for i, piece := range pieces {
+		split[i] = &pb.Token{
+			Word:                 S(piece),
+			OriginalText:         S(piece),
+			Value:                S(piece),
+			Before:               S(""),
+			After:                S(""),
+			BeginChar:            U32(token.GetBeginChar()),
+			EndChar:              U32(token.GetEndChar()),
+			CodepointOffsetBegin: U32(token.GetCodepointOffsetBegin()),
+			CodepointOffsetEnd:   U32(token.GetCodepointOffsetEnd()),
+			IsMWT:                B(true),
+			IsFirstMWT:           B(i == 0),
+			MwtText:              S(token.GetOriginalText()),
+		}
+	}
+	split[0].Before = S(token.GetBefore())
+	split[len(split)-1].After = S(token.GetAfter())
+	sentence.Token = append(sentence.Token[:tokenIndex],
+		append(split, sentence.Token[tokenIndex+1:]...)...)
+
+	var tokenIdx uint32
+	for _, sentence := range doc.Sentence {
+		sentence.TokenOffsetBegin = U32(tokenIdx)
+		for _, token := range sentence.Token {
+			token.TokenBeginIndex = U32(tokenIdx)
+			token.TokenEndIndex = U32(tokenIdx + 1)
+			tokenIdx++
+		}
+		sentence.TokenOffsetEnd = U32(tokenIdx)
+	}
+}
+
+// JoinTokens joins the i-th and (i+1)-th tokens of the sentence
+// into one token, as if a tokenizer did not split them.
+func JoinTokens(sentence *pb.Sentence, i int) {
+	first, second := sentence.Token[i], sentence.Token[i+1]
+	word := first.GetWord() + first.GetAfter() + second.GetWord()
+	first.Word = S(word)
+	first.OriginalText = S(word)
+	first.Value = S(word)
+	first.After = second.After
+	first.EndChar = second.EndChar
+	first.CodepointOffsetEnd = second.CodepointOffsetEnd
+	sentence.Token = append(sentence.Token[:i+1], sentence.Token[i+2:]...)
+}
+
+// NewCorefMention creates a coreference chain mention
+// of the single token at the specified position
+// in the sentence with the specified index,
+// with the specified mention ID.
+func NewCorefMention(
+	mentionID int32,
+	sentenceIndex, tokenIndex uint32,
+) *pb.CorefChain_CorefMention {
+	return &pb.CorefChain_CorefMention{
+		MentionID:     I32(mentionID),
+		BeginIndex:    U32(tokenIndex),
+		EndIndex:      U32(tokenIndex + 1),
+		HeadIndex:     U32(tokenIndex),
+		SentenceIndex: U32(sentenceIndex),
+	}
+}
+
+// SetPOS sets the part-of-speech tags of the tokens in the sentence
+// with the specified index.
+//
+// It panics if the number of tags differs from that of tokens.
+func SetPOS(doc *pb.Document, sentenceIndex int, tags ...string) {
+	tokens := doc.Sentence[sentenceIndex].Token
+	if len(tags) != len(tokens) {
+		panic("number of tags mismatch")
+	}
+	for i := range tokens {
+		tokens[i].Pos = S(tags[i])
+	}
+}
+
+// SetNER sets the named entity tags of the tokens in the sentence
+// with the specified index.
+//
+// It panics if the number of tags differs from that of tokens.
+func SetNER(doc *pb.Document, sentenceIndex int, tags ...string) {
+	tokens := doc.Sentence[sentenceIndex].Token
+	if len(tags) != len(tokens) {
+		panic("number of tags mismatch")
+	}
+	for i := range tokens {
+		tokens[i].Ner = S(tags[i])
+	}
+}
+
+// S returns a pointer to a copy of s.
+func S(s string) *string {
+	return &s
+}
+
+// U32 returns a pointer to a copy of x.
+func U32(x uint32) *uint32 {
+	return &x
+}
+
+// I32 returns a pointer to a copy of x.
+func I32(x int32) *int32 {
+	return &x
+}
+
+// B returns a pointer to a copy of b.
+func B(b bool) *bool {
+	return &b
+}
+
+// noSpaceBefore reports whether no space is inserted before word.
+func noSpaceBefore(word string) bool {
+	switch word {
+	case ".", ",", "!", "?", ";", ":", "'s":
+		return true
+	}
+	return false
+}
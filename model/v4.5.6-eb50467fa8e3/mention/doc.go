@@ -0,0 +1,28 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package mention extracts the entity mentions of
+// Stanford CoreNLP 4.5.6 documents as flat typed spans.
+//
+// CoreNLP records entity mentions (NERMention) by token ranges
+// relative to their sentences,
+// and does not always set the mention text.
+// The function Spans resolves each entity mention to its text,
+// document-level token and character offsets,
+// and coreference chain (through entityMentionToCorefMentionMappings).
+package mention
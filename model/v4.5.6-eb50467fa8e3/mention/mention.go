@@ -0,0 +1,189 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package mention
+
+import (
+	"fmt"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/textoffset"
+)
+
+// Span is an entity mention resolved against its document.
+type Span struct {
+	// Index is the entity mention index of the mention,
+	// i.e., its position in the document-level mention list.
+	Index int
+
+	// CanonicalIndex is the entity mention index of
+	// the canonical mention of this mention, or -1 if unset.
+	CanonicalIndex int
+
+	// SentenceIndex is the 0-based index of the sentence
+	// containing the mention.
+	SentenceIndex uint32
+
+	// TokenBegin and TokenEnd are the 0-based token positions of
+	// the mention in its sentence,
+	// including TokenBegin and excluding TokenEnd.
+	TokenBegin, TokenEnd uint32
+
+	// DocTokenBegin and DocTokenEnd are the 0-based token positions of
+	// the mention in the document,
+	// including DocTokenBegin and excluding DocTokenEnd.
+	DocTokenBegin, DocTokenEnd uint32
+
+	// BeginChar and EndChar are the character offsets of the mention
+	// in the document text, including BeginChar and excluding EndChar.
+	//
+	// Note that CoreNLP counts characters in UTF-16 code units.
+	BeginChar, EndChar uint32
+
+	// CodepointBegin and CodepointEnd are the Unicode code point offsets of
+	// the mention in the document text,
+	// including CodepointBegin and excluding CodepointEnd.
+	CodepointBegin, CodepointEnd uint32
+
+	// Text is the text of the mention:
+	// the field entityMentionText if set,
+	// or the surface text of its tokens otherwise
+	// (see function textoffset.SpanText).
+	Text string
+
+	NER             string    // NER is the named entity tag, such as "PERSON".
+	NormalizedNER   string    // NormalizedNER is the normalized value, such as "2024-01-01" for a date.
+	EntityType      string    // EntityType is the entity type.
+	Timex           *pb.Timex // Timex is the temporal expression of the mention, if any.
+	WikipediaEntity string    // WikipediaEntity is the linked Wikipedia entity, if any.
+	Gender          string    // Gender is the gender of the mention, if any.
+
+	// CorefMentionID is the ID of the coreference mention
+	// corresponding to this entity mention, or -1 if there is none.
+	CorefMentionID int32
+
+	// CorefChainID is the ID of the coreference chain containing
+	// CorefMentionID, or -1 if there is none.
+	CorefChainID int32
+
+	// Tokens are the tokens of the mention.
+	Tokens []*pb.Token
+}
+
+// Spans returns the entity mentions of the document as flat spans,
+// in the same order as the mention list.
+//
+// It uses the document-level mention list (the field mentions of Document)
+// if it is not empty;
+// otherwise, it concatenates the mention lists of the sentences.
+//
+// It reports an error if doc is nil or a mention refers to
+// a nonexistent sentence or token.
+func Spans(doc *pb.Document) ([]Span, error) {
+	if doc == nil {
+		return nil, gogoerrors.AutoNew("document is nil")
+	}
+	sentences := doc.GetSentence()
+	pbMentions := doc.GetMentions()
+	if len(pbMentions) == 0 {
+		for _, sentence := range sentences {
+			pbMentions = append(pbMentions, sentence.GetMentions()...)
+		}
+	}
+	chainOf := make(map[int32]int32)
+	for _, chain := range doc.GetCorefChain() {
+		for _, m := range chain.GetMention() {
+			chainOf[m.GetMentionID()] = chain.GetChainID()
+		}
+	}
+	corefMappings := doc.GetEntityMentionToCorefMentionMappings()
+
+	spans := make([]Span, len(pbMentions))
+	for i, m := range pbMentions {
+		s := Span{
+			Index:           i,
+			CanonicalIndex:  -1,
+			SentenceIndex:   m.GetSentenceIndex(),
+			TokenBegin:      m.GetTokenStartInSentenceInclusive(),
+			TokenEnd:        m.GetTokenEndInSentenceExclusive(),
+			NER:             m.GetNer(),
+			NormalizedNER:   m.GetNormalizedNER(),
+			EntityType:      m.GetEntityType(),
+			Timex:           m.GetTimex(),
+			WikipediaEntity: m.GetWikipediaEntity(),
+			Gender:          m.GetGender(),
+			CorefMentionID:  -1,
+			CorefChainID:    -1,
+		}
+		if m.EntityMentionIndex != nil {
+			s.Index = int(m.GetEntityMentionIndex())
+		}
+		if m.CanonicalEntityMentionIndex != nil {
+			s.CanonicalIndex = int(m.GetCanonicalEntityMentionIndex())
+		}
+		if uint64(s.SentenceIndex) >= uint64(len(sentences)) {
+			return nil, gogoerrors.AutoNew(fmt.Sprintf(
+				"entity mention %d refers to nonexistent sentence %d",
+				s.Index, s.SentenceIndex))
+		}
+		sentence := sentences[s.SentenceIndex]
+		tokens := sentence.GetToken()
+		if s.TokenBegin >= s.TokenEnd ||
+			uint64(s.TokenEnd) > uint64(len(tokens)) {
+			return nil, gogoerrors.AutoNew(fmt.Sprintf(
+				"entity mention %d has invalid token range [%d, %d) in sentence %d with %d token(s)",
+				s.Index, s.TokenBegin, s.TokenEnd, s.SentenceIndex, len(tokens)))
+		}
+		s.Tokens = tokens[s.TokenBegin:s.TokenEnd:s.TokenEnd]
+		first, last := s.Tokens[0], s.Tokens[len(s.Tokens)-1]
+		s.DocTokenBegin = sentence.GetTokenOffsetBegin() + s.TokenBegin
+		s.DocTokenEnd = sentence.GetTokenOffsetBegin() + s.TokenEnd
+		s.BeginChar, s.EndChar = first.GetBeginChar(), last.GetEndChar()
+		s.CodepointBegin = first.GetCodepointOffsetBegin()
+		s.CodepointEnd = last.GetCodepointOffsetEnd()
+		s.Text = m.GetEntityMentionText()
+		if len(s.Text) == 0 {
+			s.Text = textoffset.SpanText(s.Tokens)
+		}
+		if s.Index >= 0 && s.Index < len(corefMappings) &&
+			corefMappings[s.Index] >= 0 {
+			s.CorefMentionID = corefMappings[s.Index]
+			if chainID, ok := chainOf[s.CorefMentionID]; ok {
+				s.CorefChainID = chainID
+			}
+		}
+		spans[i] = s
+	}
+	return spans, nil
+}
+
+// ByCorefChain groups the specified spans by their coreference chain IDs.
+//
+// Spans not linked to any coreference chain are omitted.
+// Within each group, the spans are in the same order as in spans.
+func ByCorefChain(spans []Span) map[int32][]Span {
+	groups := make(map[int32][]Span)
+	for _, s := range spans {
+		if s.CorefChainID >= 0 {
+			groups[s.CorefChainID] = append(groups[s.CorefChainID], s)
+		}
+	}
+	return groups
+}
@@ -0,0 +1,193 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package mention_test
+
+import (
+	"testing"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/internal/testdoc"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/mention"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+func TestSpans(t *testing.T) {
+	for _, docLevel := range []bool{true, false} {
+		name := "sentence-level"
+		if docLevel {
+			name = "document-level"
+		}
+		t.Run(name, func(t *testing.T) {
+			doc := NewZoeDocument(docLevel)
+			spans, err := mention.Spans(doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []struct {
+				text                  string
+				ner                   string
+				docBegin, docEnd      uint32
+				beginChar, endChar    uint32
+				cpBegin, cpEnd        uint32
+				corefMention, corefCh int32
+			}{
+				{"Zoë Smith", "PERSON", 0, 2, 0, 9, 0, 9, 0, 7},
+				{"Paris", "CITY", 3, 4, 18, 23, 18, 23, -1, -1},
+				{"last Monday", "DATE", 5, 7, 27, 38, 27, 38, -1, -1},
+				{"🙂 Smith", "PERSON", 10, 12, 48, 56, 48, 55, 2, 7},
+			}
+			if len(spans) != len(want) {
+				t.Fatalf("got %d span(s); want %d", len(spans), len(want))
+			}
+			for i, w := range want {
+				s := spans[i]
+				if s.Text != w.text || s.NER != w.ner ||
+					s.DocTokenBegin != w.docBegin || s.DocTokenEnd != w.docEnd ||
+					s.BeginChar != w.beginChar || s.EndChar != w.endChar ||
+					s.CodepointBegin != w.cpBegin || s.CodepointEnd != w.cpEnd ||
+					s.CorefMentionID != w.corefMention ||
+					s.CorefChainID != w.corefCh {
+					t.Errorf("span#%d: got %+v; want %+v", i, s, w)
+				}
+			}
+			if wiki := spans[1].WikipediaEntity; wiki != "Paris" {
+				t.Errorf("got Wikipedia entity %q; want \"Paris\"", wiki)
+			}
+			if tv := spans[2].Timex.GetValue(); tv != "2024-01-01" ||
+				spans[2].NormalizedNER != "2024-01-01" {
+				t.Errorf("got timex %q, normalized %q; want 2024-01-01",
+					tv, spans[2].NormalizedNER)
+			}
+			if c := spans[3].CanonicalIndex; c != 0 {
+				t.Errorf("got canonical index %d; want 0", c)
+			}
+			groups := mention.ByCorefChain(spans)
+			if len(groups) != 1 || len(groups[7]) != 2 {
+				t.Errorf("got groups %v; want chain 7 with 2 spans", groups)
+			}
+		})
+	}
+}
+
+func TestSpans_MultiWordToken(t *testing.T) {
+	doc := testdoc.New([]string{"Fui", "al", "Museo", "del", "Prado", "."})
+	testdoc.SplitMWT(doc, 0, 3, "de", "el")
+	testdoc.SplitMWT(doc, 0, 1, "a", "el")
+	// Tokens: Fui a el Museo de el Prado .
+	doc.Mentions = []*pb.NERMention{NewNERMention(0, 0, 3, 7, "LOCATION")}
+	spans, err := mention.Spans(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spans) != 1 {
+		t.Fatalf("got %d span(s); want 1", len(spans))
+	}
+	const want = "Museo del Prado"
+	if s := spans[0]; s.Text != want ||
+		doc.GetText()[s.BeginChar:s.EndChar] != want {
+		t.Errorf("got %+v; want %q", s, want)
+	}
+}
+
+func TestSpans_Invalid(t *testing.T) {
+	doc := NewZoeDocument(true)
+	doc.Mentions[0].SentenceIndex = testdoc.U32(2)
+	if _, err := mention.Spans(doc); err == nil {
+		t.Error("got nil error for nonexistent sentence")
+	}
+	doc = NewZoeDocument(true)
+	doc.Mentions[0].TokenEndInSentenceExclusive = testdoc.U32(20)
+	if _, err := mention.Spans(doc); err == nil {
+		t.Error("got nil error for invalid token range")
+	}
+	if _, err := mention.Spans(nil); err == nil {
+		t.Error("got nil error for nil document")
+	}
+}
+
+// NewZoeDocument returns a document of the text
+// "Zoë Smith visited Paris on last Monday. She met 🙂 Smith."
+// with four entity mentions.
+//
+// If docLevel is true, the mentions are stored in the document;
+// otherwise, they are stored in the sentences.
+func NewZoeDocument(docLevel bool) *pb.Document {
+	doc := testdoc.New(
+		[]string{"Zoë", "Smith", "visited", "Paris", "on", "last", "Monday", "."},
+		[]string{"She", "met", "🙂", "Smith", "."},
+	)
+	mentions := []*pb.NERMention{
+		NewNERMention(0, 0, 0, 2, "PERSON"),
+		NewNERMention(1, 0, 3, 4, "CITY"),
+		NewNERMention(2, 0, 5, 7, "DATE"),
+		NewNERMention(3, 1, 2, 4, "PERSON"),
+	}
+	mentions[1].WikipediaEntity = testdoc.S("Paris")
+	mentions[2].NormalizedNER = testdoc.S("2024-01-01")
+	mentions[2].Timex = &pb.Timex{
+		Value: testdoc.S("2024-01-01"),
+		Type:  testdoc.S("DATE"),
+	}
+	mentions[3].CanonicalEntityMentionIndex = testdoc.U32(0)
+	if docLevel {
+		doc.Mentions = mentions
+	} else {
+		doc.Sentence[0].Mentions = mentions[:3]
+		doc.Sentence[1].Mentions = mentions[3:]
+	}
+	doc.EntityMentionToCorefMentionMappings = []int32{0, -1, -1, 2}
+	doc.CorefChain = []*pb.CorefChain{{
+		ChainID:        testdoc.I32(7),
+		Representative: testdoc.U32(0),
+		Mention: []*pb.CorefChain_CorefMention{
+			{
+				MentionID:     testdoc.I32(0),
+				SentenceIndex: testdoc.U32(0),
+				BeginIndex:    testdoc.U32(0),
+				EndIndex:      testdoc.U32(2),
+			},
+			{
+				MentionID:     testdoc.I32(1),
+				SentenceIndex: testdoc.U32(1),
+				BeginIndex:    testdoc.U32(0),
+				EndIndex:      testdoc.U32(1),
+			},
+			{
+				MentionID:     testdoc.I32(2),
+				SentenceIndex: testdoc.U32(1),
+				BeginIndex:    testdoc.U32(2),
+				EndIndex:      testdoc.U32(4),
+			},
+		},
+	}}
+	return doc
+}
+
+// NewNERMention returns an entity mention.
+func NewNERMention(
+	index, sentenceIndex, begin, end uint32,
+	ner string,
+) *pb.NERMention {
+	return &pb.NERMention{
+		SentenceIndex:                 testdoc.U32(sentenceIndex),
+		TokenStartInSentenceInclusive: testdoc.U32(begin),
+		TokenEndInSentenceExclusive:   testdoc.U32(end),
+		Ner:                           testdoc.S(ner),
+		EntityMentionIndex:            testdoc.U32(index),
+	}
+}
@@ -0,0 +1,44 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package parsetree converts the constituency parse trees of
+// Stanford CoreNLP 4.5.6 between their representations.
+//
+// A tree can be represented as
+//   - a string in the Penn Treebank (PTB) bracketed format,
+//     such as "(ROOT (S (NP (NNS Roses)) (VP (VBP are) (ADJP (JJ red)))))";
+//   - a github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb.ParseTree,
+//     a recursive structure as produced by the parse annotator;
+//   - a github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb.FlattenedParseTree,
+//     a flat list of nodes used by the requests of the dependency converter
+//     and the parser evaluator, so that deep trees do not exceed
+//     the ProtoBuf stack depth.
+//
+// In a FlattenedParseTree, each internal node is written as
+// an open node, a value node holding its label, its children,
+// and a close node, and each leaf is written as a single value node.
+// The score of a tree is held by the open node of its root.
+//
+// This package also builds the requests of the dependency converter
+// and the parser evaluator of Stanford CoreNLP
+// (see the package github.com/donyori/gocorenlp/processor),
+// and provides an evalb-style labeled bracket F1 scorer in pure Go
+// (function Evalb and EvaluateLocally),
+// which can be cross-checked against the parser evaluator
+// and used without Java.
+package parsetree
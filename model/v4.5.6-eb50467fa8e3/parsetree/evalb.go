@@ -0,0 +1,322 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parsetree
+
+import (
+	"fmt"
+	"strings"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+// Score is the result of an evalb-style labeled bracket evaluation.
+//
+// Scores of several sentences can be accumulated with the method Add,
+// which gives the micro-averaged (corpus-level) precision, recall,
+// and F1 score, as reported by evalb and Stanford CoreNLP.
+type Score struct {
+	Sentences  int // Sentences is the number of evaluated sentences.
+	ExactMatch int // ExactMatch is the number of sentences whose predicted brackets are the same as the gold ones.
+	Matched    int // Matched is the number of matched brackets.
+	Gold       int // Gold is the number of brackets in the gold trees.
+	Predicted  int // Predicted is the number of brackets in the predicted trees.
+}
+
+// Add adds the counts of other to s.
+func (s *Score) Add(other Score) {
+	s.Sentences += other.Sentences
+	s.ExactMatch += other.ExactMatch
+	s.Matched += other.Matched
+	s.Gold += other.Gold
+	s.Predicted += other.Predicted
+}
+
+// Precision returns the labeled bracket precision, in [0, 1].
+//
+// It returns 0 if there are no predicted brackets.
+func (s Score) Precision() float64 {
+	if s.Predicted == 0 {
+		return 0
+	}
+	return float64(s.Matched) / float64(s.Predicted)
+}
+
+// Recall returns the labeled bracket recall, in [0, 1].
+//
+// It returns 0 if there are no gold brackets.
+func (s Score) Recall() float64 {
+	if s.Gold == 0 {
+		return 0
+	}
+	return float64(s.Matched) / float64(s.Gold)
+}
+
+// F1 returns the labeled bracket F1 score, in [0, 1].
+//
+// It returns 0 if there are neither gold nor predicted brackets.
+func (s Score) F1() float64 {
+	if s.Gold+s.Predicted == 0 {
+		return 0
+	}
+	return 2 * float64(s.Matched) / float64(s.Gold+s.Predicted)
+}
+
+// Evalb evaluates a predicted tree against its gold tree
+// with labeled brackets, in the way of evalb with
+// the parameter file COLLINS.prm:
+//   - the root node labeled "ROOT", "TOP", or "" is not counted;
+//   - preterminals (part-of-speech tags) are not counted;
+//   - punctuation (tagged as commas, colons, periods, or quotation marks) and
+//     empty elements (tagged as "-NONE-") are deleted first,
+//     together with the nodes left without words;
+//   - function tags and indexes are removed from the labels
+//     (for example, "NP-SBJ-1" is counted as "NP");
+//   - the labels "ADVP" and "PRT" are treated as the same.
+//
+// It reports an error if the two trees have different numbers of words
+// after the deletion.
+func Evalb(gold, predicted *pb.ParseTree) (Score, error) {
+	goldBrackets, goldN := collectBrackets(gold)
+	predBrackets, predN := collectBrackets(predicted)
+	if goldN != predN {
+		return Score{}, gogoerrors.AutoNew(fmt.Sprintf(
+			"gold tree has %d words but predicted tree has %d",
+			goldN, predN))
+	}
+	counts := make(map[bracket]int, len(goldBrackets))
+	for _, b := range goldBrackets {
+		counts[b]++
+	}
+	s := Score{
+		Sentences: 1,
+		Gold:      len(goldBrackets),
+		Predicted: len(predBrackets),
+	}
+	for _, b := range predBrackets {
+		if counts[b] > 0 {
+			counts[b]--
+			s.Matched++
+		}
+	}
+	if s.Matched == s.Gold && s.Matched == s.Predicted {
+		s.ExactMatch = 1
+	}
+	return s, nil
+}
+
+// EvalbCorpus evaluates the predicted trees against the gold trees
+// and returns the accumulated score.
+//
+// gold and predicted must have the same length,
+// and predicted[i] is evaluated against gold[i].
+//
+// See the function Evalb for details.
+func EvalbCorpus(gold, predicted []*pb.ParseTree) (Score, error) {
+	if len(gold) != len(predicted) {
+		return Score{}, gogoerrors.AutoNew(fmt.Sprintf(
+			"got %d gold trees but %d predicted trees",
+			len(gold), len(predicted)))
+	}
+	var total Score
+	for i := range gold {
+		s, err := Evalb(gold[i], predicted[i])
+		if err != nil {
+			return Score{}, gogoerrors.AutoWrap(fmt.Errorf("tree#%d: %w", i, err))
+		}
+		total.Add(s)
+	}
+	return total, nil
+}
+
+// NewEvaluateParserRequest creates an EvaluateParserRequest
+// for the specified gold trees and predicted trees.
+//
+// predicted[i] holds the predicted trees of gold[i]
+// (one tree, or the k-best trees with the best first).
+// The score of each predicted tree is sent along with it.
+//
+// The request can be sent by the method
+// github.com/donyori/gocorenlp/processor.ParserEvaluator.Evaluate,
+// or evaluated locally by the function EvaluateLocally.
+func NewEvaluateParserRequest(
+	gold []*pb.ParseTree,
+	predicted [][]*pb.ParseTree,
+) (*pb.EvaluateParserRequest, error) {
+	if len(gold) != len(predicted) {
+		return nil, gogoerrors.AutoNew(fmt.Sprintf(
+			"got %d gold trees but %d predicted tree lists",
+			len(gold), len(predicted)))
+	}
+	req := &pb.EvaluateParserRequest{
+		Treebank: make([]*pb.EvaluateParserRequest_ParseResult, len(gold)),
+	}
+	for i := range gold {
+		if gold[i] == nil {
+			return nil, gogoerrors.AutoNew(fmt.Sprintf(
+				"gold tree#%d is nil", i))
+		} else if len(predicted[i]) == 0 {
+			return nil, gogoerrors.AutoNew(fmt.Sprintf(
+				"no predicted tree for gold tree#%d", i))
+		}
+		result := &pb.EvaluateParserRequest_ParseResult{
+			Gold:      Flatten(gold[i]),
+			Predicted: make([]*pb.FlattenedParseTree, len(predicted[i])),
+		}
+		for j, t := range predicted[i] {
+			if t == nil {
+				return nil, gogoerrors.AutoNew(fmt.Sprintf(
+					"predicted tree#%d of gold tree#%d is nil", j, i))
+			}
+			result.Predicted[j] = Flatten(t)
+		}
+		req.Treebank[i] = result
+	}
+	return req, nil
+}
+
+// EvaluateLocally evaluates the request in pure Go,
+// without Stanford CoreNLP,
+// and returns the result in the same form as the CoreNLP parser evaluator.
+//
+// The field f1 of the response is the F1 score of the first
+// predicted trees (see the function Evalb).
+// If any gold tree has more than one predicted tree,
+// the field kbestF1 is set to the F1 score of the oracle choice,
+// that is, the predicted tree with the highest F1 score for each gold tree.
+//
+// Both scores are in [0, 1].
+func EvaluateLocally(
+	req *pb.EvaluateParserRequest,
+) (*pb.EvaluateParserResponse, error) {
+	var best, kbest Score
+	var hasKBest bool
+	for i, result := range req.GetTreebank() {
+		gold, err := Unflatten(result.GetGold())
+		if err != nil {
+			return nil, gogoerrors.AutoWrap(fmt.Errorf(
+				"gold tree#%d: %w", i, err))
+		}
+		predicted := result.GetPredicted()
+		if len(predicted) == 0 {
+			return nil, gogoerrors.AutoNew(fmt.Sprintf(
+				"no predicted tree for gold tree#%d", i))
+		}
+		hasKBest = hasKBest || len(predicted) > 1
+		var oracle Score
+		for j, f := range predicted {
+			t, err := Unflatten(f)
+			if err != nil {
+				return nil, gogoerrors.AutoWrap(fmt.Errorf(
+					"predicted tree#%d of gold tree#%d: %w", j, i, err))
+			}
+			s, err := Evalb(gold, t)
+			if err != nil {
+				return nil, gogoerrors.AutoWrap(fmt.Errorf(
+					"predicted tree#%d of gold tree#%d: %w", j, i, err))
+			}
+			if j == 0 {
+				best.Add(s)
+				oracle = s
+			} else if s.F1() > oracle.F1() {
+				oracle = s
+			}
+		}
+		kbest.Add(oracle)
+	}
+	resp := &pb.EvaluateParserResponse{F1: proto.Float64(best.F1())}
+	if hasKBest {
+		resp.KbestF1 = proto.Float64(kbest.F1())
+	}
+	return resp, nil
+}
+
+// bracket is a labeled constituent covering the words
+// from begin (inclusive) to end (exclusive).
+type bracket struct {
+	label      string
+	begin, end int
+}
+
+// evalbDeletedTags are the part-of-speech tags of the words
+// deleted before evaluation.
+var evalbDeletedTags = map[string]bool{
+	",":      true,
+	":":      true,
+	".":      true,
+	"``":     true,
+	"''":     true,
+	"-NONE-": true,
+}
+
+// collectBrackets returns the brackets of t to be evaluated
+// and the number of its words, after the deletion described in Evalb.
+func collectBrackets(t *pb.ParseTree) (brackets []bracket, n int) {
+	if t == nil {
+		return
+	}
+	var walk func(t *pb.ParseTree, isRoot bool)
+	walk = func(t *pb.ParseTree, isRoot bool) {
+		children := t.GetChild()
+		if len(children) == 0 {
+			n++
+			return
+		} else if len(children) == 1 && len(children[0].GetChild()) == 0 {
+			// Preterminal.
+			if !evalbDeletedTags[t.GetValue()] {
+				n++
+			}
+			return
+		}
+		begin := n
+		for _, child := range children {
+			walk(child, false)
+		}
+		if n == begin {
+			return // all words have been deleted
+		}
+		label := evalbLabel(t.GetValue())
+		if isRoot && (label == "" || label == "ROOT" || label == "TOP") {
+			return
+		}
+		brackets = append(brackets, bracket{label: label, begin: begin, end: n})
+	}
+	walk(t, true)
+	return
+}
+
+// evalbLabel returns the label used in evaluation for
+// the specified node value.
+//
+// It removes the function tags and indexes,
+// and maps "PRT" to "ADVP".
+func evalbLabel(value string) string {
+	label := value
+	if !strings.HasPrefix(label, "-") {
+		if i := strings.IndexAny(label, "-="); i > 0 {
+			label = label[:i]
+		}
+	}
+	if label == "PRT" {
+		label = "ADVP"
+	}
+	return label
+}
@@ -0,0 +1,169 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parsetree_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/parsetree"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+const (
+	CatGold = "(ROOT (S (NP (DT The) (NN cat)) (VP (VBD sat) (PP (IN on) (NP (DT the) (NN mat)))) (. .)))"
+	CatPred = "(ROOT (S (NP (DT The) (NN cat)) (VP (VBD sat) (PP (IN on)) (NP (DT the) (NN mat))) (. .)))"
+
+	RosesGold  = "(ROOT (S (NP (NNS Roses)) (VP (VBP are) (ADJP (JJ red))) (. .)))"
+	RosesPred1 = "(ROOT (S (NP (NNS Roses) (VBP are)) (ADJP (JJ red)) (. .)))"
+	RosesPred2 = "(ROOT (S (NP (NNS Roses)) (VP (VBP are) (ADJP (JJ red)) (. .))))"
+)
+
+func TestEvalb(t *testing.T) {
+	testCases := []struct {
+		name, gold, predicted string
+		want                  parsetree.Score
+	}{
+		{
+			"wrong attachment",
+			CatGold, CatPred,
+			parsetree.Score{Sentences: 1, Matched: 4, Gold: 5, Predicted: 5},
+		},
+		{
+			"punctuation",
+			RosesGold, RosesPred2,
+			parsetree.Score{
+				Sentences: 1, ExactMatch: 1, Matched: 4, Gold: 4, Predicted: 4,
+			},
+		},
+		{
+			"function tags and PRT",
+			"(ROOT (S (NP-SBJ-1 (PRP He)) (VP (VBD gave) (PRT (RP up)))))",
+			"(ROOT (S (NP (PRP He)) (VP (VBD gave) (ADVP (RB up)))))",
+			parsetree.Score{
+				Sentences: 1, ExactMatch: 1, Matched: 4, Gold: 4, Predicted: 4,
+			},
+		},
+		{
+			"empty elements",
+			"(TOP (S (NP-SBJ (-NONE- *)) (VP (VB Go)) (. !)))",
+			"( (S (VP (VB Go)) (. !)))",
+			parsetree.Score{
+				Sentences: 1, ExactMatch: 1, Matched: 2, Gold: 2, Predicted: 2,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := parsetree.Evalb(mustParse(t, tc.gold), mustParse(t, tc.predicted))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s != tc.want {
+				t.Errorf("got %+v; want %+v", s, tc.want)
+			}
+		})
+	}
+}
+
+func TestEvalb_LengthMismatch(t *testing.T) {
+	_, err := parsetree.Evalb(
+		mustParse(t, RosesGold),
+		mustParse(t, "(ROOT (S (NP (NNS Roses)) (VP (VBP are))))"),
+	)
+	if err == nil {
+		t.Error("got nil error")
+	}
+}
+
+func TestScore(t *testing.T) {
+	s := parsetree.Score{Matched: 4, Gold: 5, Predicted: 8}
+	if p := s.Precision(); p != 0.5 {
+		t.Errorf("got precision %v; want 0.5", p)
+	}
+	if r := s.Recall(); r != 0.8 {
+		t.Errorf("got recall %v; want 0.8", r)
+	}
+	if f := s.F1(); math.Abs(f-8.0/13) > 1e-12 {
+		t.Errorf("got F1 %v; want %v", f, 8.0/13)
+	}
+	var zero parsetree.Score
+	if zero.Precision() != 0 || zero.Recall() != 0 || zero.F1() != 0 {
+		t.Error("got non-zero scores for zero counts")
+	}
+}
+
+func TestEvaluateLocally(t *testing.T) {
+	req, err := parsetree.NewEvaluateParserRequest(
+		[]*pb.ParseTree{mustParse(t, CatGold), mustParse(t, RosesGold)},
+		[][]*pb.ParseTree{
+			{mustParse(t, CatPred)},
+			{mustParse(t, RosesPred1), mustParse(t, RosesPred2)},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := parsetree.EvaluateLocally(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f1 := resp.GetF1(); math.Abs(f1-12.0/17) > 1e-12 {
+		t.Errorf("got f1 %v; want %v", f1, 12.0/17)
+	}
+	if resp.KbestF1 == nil {
+		t.Fatal("kbestF1 is unset")
+	}
+	if f1 := resp.GetKbestF1(); math.Abs(f1-8.0/9) > 1e-12 {
+		t.Errorf("got kbestF1 %v; want %v", f1, 8.0/9)
+	}
+
+	req.Treebank = req.Treebank[:1]
+	resp, err = parsetree.EvaluateLocally(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f1 := resp.GetF1(); f1 != 0.8 {
+		t.Errorf("1-best only, got f1 %v; want 0.8", f1)
+	}
+	if resp.KbestF1 != nil {
+		t.Errorf("1-best only, got kbestF1 %v; want unset", resp.GetKbestF1())
+	}
+}
+
+func TestNewEvaluateParserRequest_Error(t *testing.T) {
+	gold := []*pb.ParseTree{mustParse(t, RosesGold)}
+	if _, err := parsetree.NewEvaluateParserRequest(gold, nil); err == nil {
+		t.Error("got nil error for mismatched lengths")
+	}
+	_, err := parsetree.NewEvaluateParserRequest(gold, [][]*pb.ParseTree{{}})
+	if err == nil {
+		t.Error("got nil error for no predicted tree")
+	}
+}
+
+// mustParse parses s by parsetree.Parse and reports any error to tb.
+func mustParse(tb testing.TB, s string) *pb.ParseTree {
+	tb.Helper()
+	tree, err := parsetree.Parse(s)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return tree
+}
@@ -0,0 +1,180 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parsetree
+
+import (
+	"fmt"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+// Flatten converts t to a FlattenedParseTree.
+//
+// The scores of the nodes are kept.
+// The yield indexes and the sentiments are dropped.
+//
+// It returns nil if t is nil.
+func Flatten(t *pb.ParseTree) *pb.FlattenedParseTree {
+	if t == nil {
+		return nil
+	}
+	f := new(pb.FlattenedParseTree)
+	var add func(t *pb.ParseTree)
+	add = func(t *pb.ParseTree) {
+		if len(t.GetChild()) == 0 {
+			f.Nodes = append(f.Nodes, &pb.FlattenedParseTree_Node{
+				Contents: &pb.FlattenedParseTree_Node_Value{
+					Value: t.GetValue(),
+				},
+				Score: t.Score,
+			})
+			return
+		}
+		f.Nodes = append(f.Nodes, &pb.FlattenedParseTree_Node{
+			Contents: &pb.FlattenedParseTree_Node_OpenNode{OpenNode: true},
+			Score:    t.Score,
+		}, &pb.FlattenedParseTree_Node{
+			Contents: &pb.FlattenedParseTree_Node_Value{Value: t.GetValue()},
+		})
+		for _, child := range t.GetChild() {
+			add(child)
+		}
+		f.Nodes = append(f.Nodes, &pb.FlattenedParseTree_Node{
+			Contents: &pb.FlattenedParseTree_Node_CloseNode{CloseNode: true},
+		})
+	}
+	add(t)
+	return f
+}
+
+// Unflatten converts f back to a ParseTree.
+//
+// It reports an error if f is empty, is not well-formed,
+// or contains more than one tree.
+func Unflatten(f *pb.FlattenedParseTree) (*pb.ParseTree, error) {
+	var root *pb.ParseTree
+	var stack []*pb.ParseTree
+	expectLabel := false
+	for i, node := range f.GetNodes() {
+		if root != nil {
+			return nil, gogoerrors.AutoNew(fmt.Sprintf(
+				"node#%d follows the end of the tree", i))
+		}
+		n := len(stack)
+		switch c := node.GetContents().(type) {
+		case *pb.FlattenedParseTree_Node_OpenNode:
+			if expectLabel {
+				return nil, gogoerrors.AutoNew(fmt.Sprintf(
+					"node#%d is an open node; want the value of node#%d",
+					i, i-1))
+			}
+			t := &pb.ParseTree{Score: node.Score}
+			if n > 0 {
+				stack[n-1].Child = append(stack[n-1].Child, t)
+			}
+			stack = append(stack, t)
+			expectLabel = true
+		case *pb.FlattenedParseTree_Node_Value:
+			if expectLabel {
+				stack[n-1].Value = proto.String(c.Value)
+				expectLabel = false
+			} else if n > 0 {
+				stack[n-1].Child = append(stack[n-1].Child, &pb.ParseTree{
+					Value: proto.String(c.Value),
+					Score: node.Score,
+				})
+			} else if i == 0 && len(f.GetNodes()) == 1 {
+				// A tree consisting of a single leaf.
+				root = &pb.ParseTree{
+					Value: proto.String(c.Value),
+					Score: node.Score,
+				}
+			} else {
+				return nil, gogoerrors.AutoNew(fmt.Sprintf(
+					"node#%d is a value outside of any open node", i))
+			}
+		case *pb.FlattenedParseTree_Node_CloseNode:
+			if expectLabel {
+				return nil, gogoerrors.AutoNew(fmt.Sprintf(
+					"node#%d is a close node; want the value of node#%d",
+					i, i-1))
+			} else if n == 0 {
+				return nil, gogoerrors.AutoNew(fmt.Sprintf(
+					"node#%d is an unmatched close node", i))
+			}
+			if n == 1 {
+				root = stack[0]
+			}
+			stack = stack[:n-1]
+		default:
+			return nil, gogoerrors.AutoNew(fmt.Sprintf(
+				"node#%d has no content", i))
+		}
+	}
+	if len(stack) > 0 {
+		return nil, gogoerrors.AutoNew(fmt.Sprintf(
+			"%d unclosed open nodes", len(stack)))
+	} else if root == nil {
+		return nil, gogoerrors.AutoNew("tree is empty")
+	}
+	return root, nil
+}
+
+// FromPTB parses a tree in the Penn Treebank (PTB) bracketed format
+// from s and converts it to a FlattenedParseTree.
+func FromPTB(s string) (*pb.FlattenedParseTree, error) {
+	t, err := Parse(s)
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	return Flatten(t), nil
+}
+
+// ToPTB converts f to a string in the Penn Treebank (PTB) bracketed format.
+func ToPTB(f *pb.FlattenedParseTree) (string, error) {
+	t, err := Unflatten(f)
+	if err != nil {
+		return "", gogoerrors.AutoWrap(err)
+	}
+	return String(t), nil
+}
+
+// NewDependencyConverterRequest creates a DependencyConverterRequest
+// for the specified trees in the Penn Treebank (PTB) bracketed format.
+//
+// The request can be sent by the method
+// github.com/donyori/gocorenlp/processor.DependencyConverter.Convert.
+func NewDependencyConverterRequest(
+	ptbTrees ...string,
+) (*pb.DependencyConverterRequest, error) {
+	req := &pb.DependencyConverterRequest{
+		Trees: make([]*pb.FlattenedParseTree, len(ptbTrees)),
+	}
+	for i, s := range ptbTrees {
+		f, err := FromPTB(s)
+		if err != nil {
+			return nil, gogoerrors.AutoWrap(fmt.Errorf("tree#%d: %w", i, err))
+		}
+		req.Trees[i] = f
+	}
+	return req, nil
+}
@@ -0,0 +1,124 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parsetree_test
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/parsetree"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+func TestFlatten(t *testing.T) {
+	tree, err := parsetree.Parse("(ROOT (NP (JJ red) (NNS roses)))")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree.Score = proto.Float64(-1.5)
+	f := parsetree.Flatten(tree)
+	open := &pb.FlattenedParseTree_Node_OpenNode{OpenNode: true}
+	closeNode := &pb.FlattenedParseTree_Node_CloseNode{CloseNode: true}
+	value := func(v string) *pb.FlattenedParseTree_Node_Value {
+		return &pb.FlattenedParseTree_Node_Value{Value: v}
+	}
+	want := &pb.FlattenedParseTree{Nodes: []*pb.FlattenedParseTree_Node{
+		{Contents: open, Score: proto.Float64(-1.5)},
+		{Contents: value("ROOT")},
+		{Contents: open},
+		{Contents: value("NP")},
+		{Contents: open},
+		{Contents: value("JJ")},
+		{Contents: value("red")},
+		{Contents: closeNode},
+		{Contents: open},
+		{Contents: value("NNS")},
+		{Contents: value("roses")},
+		{Contents: closeNode},
+		{Contents: closeNode},
+		{Contents: closeNode},
+	}}
+	if !proto.Equal(f, want) {
+		t.Errorf("got %v; want %v", f, want)
+	}
+	back, err := parsetree.Unflatten(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(back, tree) {
+		t.Errorf("after unflattening, got %v; want %v", back, tree)
+	}
+}
+
+func TestUnflatten_Error(t *testing.T) {
+	open := &pb.FlattenedParseTree_Node{
+		Contents: &pb.FlattenedParseTree_Node_OpenNode{OpenNode: true},
+	}
+	closeNode := &pb.FlattenedParseTree_Node{
+		Contents: &pb.FlattenedParseTree_Node_CloseNode{CloseNode: true},
+	}
+	value := &pb.FlattenedParseTree_Node{
+		Contents: &pb.FlattenedParseTree_Node_Value{Value: "X"},
+	}
+	testCases := []struct {
+		name  string
+		nodes []*pb.FlattenedParseTree_Node
+	}{
+		{"empty", nil},
+		{"unclosed", []*pb.FlattenedParseTree_Node{open, value, value}},
+		{"unmatched close", []*pb.FlattenedParseTree_Node{closeNode}},
+		{"no label", []*pb.FlattenedParseTree_Node{open, closeNode}},
+		{"open after open", []*pb.FlattenedParseTree_Node{open, open}},
+		{"no content", []*pb.FlattenedParseTree_Node{open, {}, closeNode}},
+		{"two trees", []*pb.FlattenedParseTree_Node{
+			open, value, closeNode, open, value, closeNode}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parsetree.Unflatten(
+				&pb.FlattenedParseTree{Nodes: tc.nodes})
+			if err == nil {
+				t.Error("got nil error")
+			}
+		})
+	}
+}
+
+func TestToPTB_SingleLeaf(t *testing.T) {
+	f, err := parsetree.FromPTB("(X x)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := parsetree.ToPTB(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "(X x)" {
+		t.Errorf("got %q; want %q", s, "(X x)")
+	}
+	leaf := &pb.FlattenedParseTree{Nodes: []*pb.FlattenedParseTree_Node{{
+		Contents: &pb.FlattenedParseTree_Node_Value{Value: "x"},
+	}}}
+	if s, err = parsetree.ToPTB(leaf); err != nil {
+		t.Error(err)
+	} else if s != "x" {
+		t.Errorf("got %q; want %q", s, "x")
+	}
+}
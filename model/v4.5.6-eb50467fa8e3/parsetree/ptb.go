@@ -0,0 +1,171 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parsetree
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+// Parse parses exactly one tree in the Penn Treebank (PTB)
+// bracketed format from s.
+//
+// Each internal node is enclosed in parentheses, starting with its label.
+// The label may be omitted, as in "( (S ...))",
+// in which case the value of the node is an empty string.
+// Leaves are the words outside the labels.
+//
+// Parse sets only the fields child and value of the returned tree.
+func Parse(s string) (*pb.ParseTree, error) {
+	trees, err := ParseAll(s)
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	} else if len(trees) != 1 {
+		return nil, gogoerrors.AutoNew(fmt.Sprintf(
+			"got %d trees; want exactly 1", len(trees)))
+	}
+	return trees[0], nil
+}
+
+// ParseAll parses all trees in the Penn Treebank (PTB) bracketed format
+// from s, in order.
+//
+// The trees are separated by whitespace (optional).
+//
+// See the function Parse for details.
+func ParseAll(s string) ([]*pb.ParseTree, error) {
+	var trees, stack []*pb.ParseTree
+	expectLabel := false
+	for pos := 0; pos < len(s); {
+		switch c := s[pos]; {
+		case c == '(':
+			t := new(pb.ParseTree)
+			if n := len(stack); n > 0 {
+				if expectLabel {
+					// The parent has no label.
+					stack[n-1].Value = proto.String("")
+				}
+				stack[n-1].Child = append(stack[n-1].Child, t)
+			}
+			stack = append(stack, t)
+			expectLabel = true
+			pos++
+		case c == ')':
+			n := len(stack)
+			if n == 0 {
+				return nil, gogoerrors.AutoNew(fmt.Sprintf(
+					"unmatched ')' at byte %d", pos))
+			} else if expectLabel {
+				// Empty node "()".
+				stack[n-1].Value = proto.String("")
+				expectLabel = false
+			}
+			if n == 1 {
+				trees = append(trees, stack[0])
+			}
+			stack = stack[:n-1]
+			pos++
+		case isSpace(c):
+			pos++
+		default:
+			end := pos + 1
+			for end < len(s) && s[end] != '(' && s[end] != ')' &&
+				!isSpace(s[end]) {
+				end++
+			}
+			atom := s[pos:end]
+			n := len(stack)
+			if n == 0 {
+				return nil, gogoerrors.AutoNew(fmt.Sprintf(
+					"word %q at byte %d is outside of any tree", atom, pos))
+			} else if expectLabel {
+				stack[n-1].Value = proto.String(atom)
+				expectLabel = false
+			} else {
+				stack[n-1].Child = append(
+					stack[n-1].Child, &pb.ParseTree{Value: proto.String(atom)})
+			}
+			pos = end
+		}
+	}
+	if len(stack) > 0 {
+		return nil, gogoerrors.AutoNew(fmt.Sprintf(
+			"%d unclosed '('", len(stack)))
+	}
+	return trees, nil
+}
+
+// String formats t in the Penn Treebank (PTB) bracketed format
+// on a single line.
+//
+// A node without children is formatted as a leaf.
+// It returns an empty string if t is nil.
+func String(t *pb.ParseTree) string {
+	if t == nil {
+		return ""
+	}
+	var b strings.Builder
+	writePTB(&b, t)
+	return b.String()
+}
+
+// writePTB writes t to b in the Penn Treebank (PTB) bracketed format.
+func writePTB(b *strings.Builder, t *pb.ParseTree) {
+	if len(t.GetChild()) == 0 {
+		b.WriteString(t.GetValue())
+		return
+	}
+	b.WriteByte('(')
+	b.WriteString(t.GetValue())
+	for _, child := range t.GetChild() {
+		b.WriteByte(' ')
+		writePTB(b, child)
+	}
+	b.WriteByte(')')
+}
+
+// Leaves returns the values of the leaves of t, from left to right.
+func Leaves(t *pb.ParseTree) []string {
+	var leaves []string
+	var walk func(t *pb.ParseTree)
+	walk = func(t *pb.ParseTree) {
+		if len(t.GetChild()) == 0 {
+			leaves = append(leaves, t.GetValue())
+			return
+		}
+		for _, child := range t.GetChild() {
+			walk(child)
+		}
+	}
+	if t != nil {
+		walk(t)
+	}
+	return leaves
+}
+
+// isSpace reports whether the byte c is an ASCII whitespace character.
+func isSpace(c byte) bool {
+	return c < unicode.MaxASCII && unicode.IsSpace(rune(c))
+}
@@ -0,0 +1,100 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parsetree_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/parsetree"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		s, want string
+		leaves  []string
+	}{
+		{
+			"(ROOT (S (NP (NNS Roses)) (VP (VBP are) (ADJP (JJ red))) (. .)))",
+			"(ROOT (S (NP (NNS Roses)) (VP (VBP are) (ADJP (JJ red))) (. .)))",
+			[]string{"Roses", "are", "red", "."},
+		},
+		{
+			"\n(ROOT\n  (S\n    (NP (NN Sugar))\n    (VP (VBZ is))))\n",
+			"(ROOT (S (NP (NN Sugar)) (VP (VBZ is))))",
+			[]string{"Sugar", "is"},
+		},
+		{
+			"( (S (NP (PRP you)) (VP (VBP are))))",
+			"( (S (NP (PRP you)) (VP (VBP are))))",
+			[]string{"you", "are"},
+		},
+		{
+			"(NP (-LRB- -LRB-) (NN a) (-RRB- -RRB-))",
+			"(NP (-LRB- -LRB-) (NN a) (-RRB- -RRB-))",
+			[]string{"-LRB-", "a", "-RRB-"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run("s="+tc.s, func(t *testing.T) {
+			tree, err := parsetree.Parse(tc.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := parsetree.String(tree); got != tc.want {
+				t.Errorf("got %q; want %q", got, tc.want)
+			}
+			if got := parsetree.Leaves(tree); !slices.Equal(got, tc.leaves) {
+				t.Errorf("got leaves %q; want %q", got, tc.leaves)
+			}
+		})
+	}
+}
+
+func TestParse_Error(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"(ROOT (S (NP (NN a)))",
+		"(ROOT (NN a)))",
+		"word",
+		"(A (B b)) (A (B c))",
+	} {
+		t.Run("s="+s, func(t *testing.T) {
+			if _, err := parsetree.Parse(s); err == nil {
+				t.Error("got nil error")
+			}
+		})
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	trees, err := parsetree.ParseAll("(A (B b))\n(C (D d) (E e))")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"(A (B b))", "(C (D d) (E e))"}
+	if len(trees) != len(want) {
+		t.Fatalf("got %d trees; want %d", len(trees), len(want))
+	}
+	for i := range trees {
+		if got := parsetree.String(trees[i]); got != want[i] {
+			t.Errorf("tree#%d: got %q; want %q", i, got, want[i])
+		}
+	}
+}
@@ -0,0 +1,340 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package textoffset
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+)
+
+// Location is the location of a token in a document.
+type Location struct {
+	Sentence int // Sentence is the 0-based index of the sentence.
+	Token    int // Token is the 0-based position of the token in the sentence.
+}
+
+// ByteSpan is a span of a Go string in bytes,
+// including Begin and excluding End.
+type ByteSpan struct {
+	Begin, End int
+}
+
+// Reconstruct rebuilds the original text of the document from its tokens.
+//
+// It concatenates the field before of the first token,
+// and then the surface text (see function SurfaceText) and
+// the field after of each token.
+// The pieces of a multi-word token share the surface text,
+// which is written only once, for the first piece.
+// If the document has no sentence, it uses the sentenceless tokens.
+//
+// For a document annotated by CoreNLP,
+// the result is usually the same as the document text.
+func Reconstruct(doc *pb.Document) string {
+	var b strings.Builder
+	first := true
+	forEachToken(doc, func(token *pb.Token, _ Location) {
+		if first {
+			b.WriteString(token.GetBefore())
+			first = false
+		}
+		if !isMWTContinuation(token) {
+			b.WriteString(SurfaceText(token))
+		}
+		b.WriteString(token.GetAfter())
+	})
+	return b.String()
+}
+
+// Aligner maps the tokens of a document to byte spans of its text,
+// and maps byte spans of the text back to token ranges.
+//
+// Tokens are identified by their 0-based positions in the document.
+// The pieces of a multi-word token (such as "de" and "el"
+// split from the Spanish word "del") have the same byte span.
+//
+// An Aligner must be created by the function NewAligner.
+type Aligner struct {
+	index     *Index
+	tokens    []*pb.Token
+	locations []Location
+	spans     []ByteSpan
+}
+
+// NewAligner creates an Aligner for the specified document.
+//
+// It uses the document text, or the reconstructed text
+// (see function Reconstruct) if the document text is empty.
+// The byte spans of the tokens are computed from
+// their character offsets (the fields beginChar and endChar).
+//
+// It reports an error if doc is nil, a token has invalid character offsets,
+// or the tokens overlap or are out of order.
+// Tokens with the same character offsets, such as the pieces of
+// a multi-word token, are not regarded as overlapping.
+func NewAligner(doc *pb.Document) (*Aligner, error) {
+	if doc == nil {
+		return nil, gogoerrors.AutoNew("document is nil")
+	}
+	text := doc.GetText()
+	if len(text) == 0 {
+		text = Reconstruct(doc)
+	}
+	a := &Aligner{index: NewIndex(text)}
+	var err error
+	forEachToken(doc, func(token *pb.Token, loc Location) {
+		if err != nil {
+			return
+		}
+		begin, ok := a.index.CharToByte(int(token.GetBeginChar()))
+		end, ok2 := a.index.CharToByte(int(token.GetEndChar()))
+		switch {
+		case !ok || !ok2 || begin > end:
+			err = gogoerrors.AutoNew(fmt.Sprintf(
+				"token#%d in sentence#%d has invalid character offsets [%d, %d) in text of %d char(s)",
+				loc.Token, loc.Sentence, token.GetBeginChar(),
+				token.GetEndChar(), a.index.NumChars()))
+			return
+		case len(a.spans) > 0 && begin < a.spans[len(a.spans)-1].End &&
+			a.spans[len(a.spans)-1] != ByteSpan{Begin: begin, End: end}:
+			err = gogoerrors.AutoNew(fmt.Sprintf(
+				"token#%d in sentence#%d overlaps with or precedes its previous token",
+				loc.Token, loc.Sentence))
+			return
+		}
+		a.tokens = append(a.tokens, token)
+		a.locations = append(a.locations, loc)
+		a.spans = append(a.spans, ByteSpan{Begin: begin, End: end})
+	})
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	return a, nil
+}
+
+// Index returns the offset index of the text.
+func (a *Aligner) Index() *Index {
+	return a.index
+}
+
+// Text returns the text that the tokens are aligned to.
+func (a *Aligner) Text() string {
+	return a.index.Text()
+}
+
+// NumTokens returns the number of tokens in the document.
+func (a *Aligner) NumTokens() int {
+	return len(a.tokens)
+}
+
+// Token returns the i-th token of the document.
+//
+// It panics if i is out of range.
+func (a *Aligner) Token(i int) *pb.Token {
+	return a.tokens[i]
+}
+
+// Location returns the location of the i-th token of the document.
+//
+// It panics if i is out of range.
+func (a *Aligner) Location(i int) Location {
+	return a.locations[i]
+}
+
+// ByteSpan returns the byte span of the i-th token of the document
+// in the text.
+//
+// It panics if i is out of range.
+func (a *Aligner) ByteSpan(i int) ByteSpan {
+	return a.spans[i]
+}
+
+// TokenRange returns the tokens overlapping with the byte span
+// [begin, end) of the text, as a range of token positions in the document,
+// including first and excluding last.
+//
+// If begin equals end, TokenRange returns the token containing
+// the byte at begin, together with the tokens sharing its byte span
+// (i.e., all the pieces of a multi-word token).
+//
+// ok is false if the span is invalid or no token overlaps with it
+// (e.g., the span only covers whitespace).
+func (a *Aligner) TokenRange(begin, end int) (first, last int, ok bool) {
+	if begin < 0 || end > a.index.NumBytes() || begin > end {
+		return -1, -1, false
+	}
+	n := len(a.spans)
+	first = sort.Search(n, func(i int) bool {
+		return a.spans[i].End > begin
+	})
+	if begin == end {
+		if first < n && a.spans[first].Begin <= begin {
+			last = first + 1
+			for last < n && a.spans[last] == a.spans[first] {
+				last++
+			}
+			return first, last, true
+		}
+		return -1, -1, false
+	}
+	last = sort.Search(n, func(i int) bool {
+		return a.spans[i].Begin >= end
+	})
+	if first >= last {
+		return -1, -1, false
+	}
+	return first, last, true
+}
+
+// Verify checks whether the tokens of the document are consistent
+// with its text.
+//
+// It reports an error if the aligner cannot be created (see NewAligner),
+// or for any token, the text in its character offsets differs from
+// its original text, its code point offsets (if set) disagree with
+// its character offsets, or its fields before and after
+// differ from the text between it and its neighbors.
+// Between the pieces of a multi-word token,
+// the fields before and after must be empty.
+// The field before of the first token and the field after of the last token
+// are compared with the text before and after all tokens, respectively.
+func Verify(doc *pb.Document) error {
+	a, err := NewAligner(doc)
+	if err != nil {
+		return gogoerrors.AutoWrap(err)
+	}
+	text := a.Text()
+	for i, token := range a.tokens {
+		span, loc := a.spans[i], a.locations[i]
+		if s := text[span.Begin:span.End]; s != SurfaceText(token) {
+			return gogoerrors.AutoNew(fmt.Sprintf(
+				"token#%d in sentence#%d: got text %q at its offsets; want %q",
+				loc.Token, loc.Sentence, s, SurfaceText(token)))
+		}
+		if token.CodepointOffsetBegin != nil || token.CodepointOffsetEnd != nil {
+			b, _ := a.index.ByteToCodepoint(span.Begin)
+			e, _ := a.index.ByteToCodepoint(span.End)
+			if uint32(b) != token.GetCodepointOffsetBegin() ||
+				uint32(e) != token.GetCodepointOffsetEnd() {
+				return gogoerrors.AutoNew(fmt.Sprintf(
+					"token#%d in sentence#%d: got code point offsets [%d, %d); want [%d, %d)",
+					loc.Token, loc.Sentence, token.GetCodepointOffsetBegin(),
+					token.GetCodepointOffsetEnd(), b, e))
+			}
+		}
+		gapBegin, gapEnd := 0, len(text)
+		if i > 0 {
+			gapBegin = a.spans[i-1].End
+			if a.spans[i-1] == span {
+				gapBegin = span.Begin // inside a multi-word token
+			}
+		}
+		if i < len(a.tokens)-1 {
+			gapEnd = a.spans[i+1].Begin
+			if a.spans[i+1] == span {
+				gapEnd = span.End // inside a multi-word token
+			}
+		}
+		if gap := text[gapBegin:span.Begin]; gap != token.GetBefore() {
+			return gogoerrors.AutoNew(fmt.Sprintf(
+				"token#%d in sentence#%d: got text %q before it; want %q",
+				loc.Token, loc.Sentence, gap, token.GetBefore()))
+		}
+		if gap := text[span.End:gapEnd]; gap != token.GetAfter() {
+			return gogoerrors.AutoNew(fmt.Sprintf(
+				"token#%d in sentence#%d: got text %q after it; want %q",
+				loc.Token, loc.Sentence, gap, token.GetAfter()))
+		}
+	}
+	return nil
+}
+
+// forEachToken calls f on each token of the document in order.
+//
+// If the document has no sentence, it iterates over the sentenceless tokens,
+// with the sentence index of their locations being -1.
+func forEachToken(doc *pb.Document, f func(token *pb.Token, loc Location)) {
+	sentences := doc.GetSentence()
+	if len(sentences) == 0 {
+		for i, token := range doc.GetSentencelessToken() {
+			f(token, Location{Sentence: -1, Token: i})
+		}
+		return
+	}
+	for sentIdx, sentence := range sentences {
+		for i, token := range sentence.GetToken() {
+			f(token, Location{Sentence: sentIdx, Token: i})
+		}
+	}
+}
+
+// TokenText returns the original text of the token,
+// or its word if the original text is empty.
+func TokenText(token *pb.Token) string {
+	if text := token.GetOriginalText(); len(text) > 0 {
+		return text
+	}
+	return token.GetWord()
+}
+
+// SurfaceText returns the text of the token in the document text,
+// which is the field mwtText for a piece of a multi-word token
+// (if mwtText is set), and TokenText(token) otherwise.
+//
+// The pieces of a multi-word token share the surface text.
+func SurfaceText(token *pb.Token) string {
+	if token.GetIsMWT() {
+		if text := token.GetMwtText(); len(text) > 0 {
+			return text
+		}
+	}
+	return TokenText(token)
+}
+
+// SpanText returns the surface text (see function SurfaceText)
+// of the specified consecutive tokens,
+// including the whitespace between them (the field after of each token
+// except the last one).
+//
+// The surface text of a multi-word token is written only once,
+// for the first of its pieces in tokens.
+func SpanText(tokens []*pb.Token) string {
+	var b strings.Builder
+	for i, token := range tokens {
+		if i == 0 || !isMWTContinuation(token) {
+			b.WriteString(SurfaceText(token))
+		}
+		if i < len(tokens)-1 {
+			b.WriteString(token.GetAfter())
+		}
+	}
+	return b.String()
+}
+
+// isMWTContinuation reports whether the token is a piece of
+// a multi-word token other than the first one,
+// which shares the surface text with the first piece.
+func isMWTContinuation(token *pb.Token) bool {
+	return token.GetIsMWT() && !token.GetIsFirstMWT()
+}
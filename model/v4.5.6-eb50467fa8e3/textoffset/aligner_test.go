@@ -0,0 +1,204 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package textoffset_test
+
+import (
+	"testing"
+
+	"github.com/donyori/gocorenlp/internal/pbtest"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/internal/testdoc"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/textoffset"
+)
+
+func TestReconstruct_RosesAreRed(t *testing.T) {
+	doc := new(pb.Document)
+	err := pbtest.DecodeBase64ToPb(pbtest.RosesAreRedRespV456, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := textoffset.Reconstruct(doc); text != pbtest.RosesAreRed {
+		t.Errorf("got %q; want %q", text, pbtest.RosesAreRed)
+	}
+	if err = textoffset.Verify(doc); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	doc := NewEmojiDocument()
+	if err := textoffset.Verify(doc); err != nil {
+		t.Error(err)
+	}
+	if text := textoffset.Reconstruct(doc); text != doc.GetText() {
+		t.Errorf("got %q; want %q", text, doc.GetText())
+	}
+
+	testCases := []struct {
+		name   string
+		modify func(tokens []*pb.Token)
+	}{
+		{"wrong before", func(tokens []*pb.Token) {
+			tokens[2].Before = testdoc.S("  ")
+		}},
+		{"wrong after", func(tokens []*pb.Token) {
+			tokens[len(tokens)-1].After = testdoc.S("\n")
+		}},
+		{"wrong original text", func(tokens []*pb.Token) {
+			tokens[1].OriginalText = testdoc.S("smile")
+		}},
+		{"wrong code point offset", func(tokens []*pb.Token) {
+			tokens[2].CodepointOffsetBegin = testdoc.U32(5)
+		}},
+		{"char offset in surrogate pair", func(tokens []*pb.Token) {
+			tokens[1].EndChar = testdoc.U32(tokens[1].GetEndChar() - 1)
+		}},
+		{"overlapping tokens", func(tokens []*pb.Token) {
+			tokens[2].BeginChar = testdoc.U32(tokens[1].GetBeginChar())
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc := NewEmojiDocument()
+			tc.modify(doc.Sentence[0].Token)
+			if err := textoffset.Verify(doc); err == nil {
+				t.Error("got nil error")
+			}
+		})
+	}
+}
+
+func TestAligner(t *testing.T) {
+	doc := NewEmojiDocument()
+	a, err := textoffset.NewAligner(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := a.NumTokens(); n != 7 {
+		t.Fatalf("got %d token(s); want 7", n)
+	}
+	// Text: "I 🙂 Zoë. She waved."
+	wantSpans := []textoffset.ByteSpan{
+		{0, 1}, {2, 6}, {7, 11}, {11, 12}, {13, 16}, {17, 22}, {22, 23},
+	}
+	for i, want := range wantSpans {
+		if span := a.ByteSpan(i); span != want {
+			t.Errorf("token#%d: got span %v; want %v", i, span, want)
+		}
+		word := a.Token(i).GetWord()
+		if s := a.Text()[want.Begin:want.End]; s != word {
+			t.Errorf("token#%d: got text %q; want %q", i, s, word)
+		}
+	}
+	if loc := a.Location(5); loc != (textoffset.Location{Sentence: 1, Token: 1}) {
+		t.Errorf("got location %+v of token#5; want {1 1}", loc)
+	}
+
+	rangeCases := []struct {
+		begin, end  int
+		first, last int
+		ok          bool
+	}{
+		{0, 23, 0, 7, true},
+		{3, 9, 1, 3, true},    // from the middle of 🙂 to the middle of Zoë
+		{6, 7, -1, -1, false}, // whitespace only
+		{2, 2, 1, 2, true},    // empty span at the beginning of 🙂
+		{6, 6, -1, -1, false},
+		{13, 22, 4, 6, true},
+		{5, 3, -1, -1, false},
+		{0, 24, -1, -1, false},
+	}
+	for _, rc := range rangeCases {
+		first, last, ok := a.TokenRange(rc.begin, rc.end)
+		if first != rc.first || last != rc.last || ok != rc.ok {
+			t.Errorf("TokenRange(%d, %d): got %d, %d, %t; want %d, %d, %t",
+				rc.begin, rc.end, first, last, ok, rc.first, rc.last, rc.ok)
+		}
+	}
+}
+
+func TestAligner_MultiWordToken(t *testing.T) {
+	// Text: "Voy al cine."
+	doc := testdoc.New([]string{"Voy", "al", "cine", "."})
+	testdoc.SplitMWT(doc, 0, 1, "a", "el")
+	if text := textoffset.Reconstruct(doc); text != doc.GetText() {
+		t.Errorf("got %q; want %q", text, doc.GetText())
+	}
+	if err := textoffset.Verify(doc); err != nil {
+		t.Error(err)
+	}
+	a, err := textoffset.NewAligner(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := a.NumTokens(); n != 5 {
+		t.Fatalf("got %d token(s); want 5", n)
+	}
+	want := textoffset.ByteSpan{Begin: 4, End: 6}
+	for i := 1; i <= 2; i++ {
+		if span := a.ByteSpan(i); span != want {
+			t.Errorf("token#%d: got span %v; want %v", i, span, want)
+		}
+	}
+	rangeCases := []struct {
+		begin, end  int
+		first, last int
+	}{
+		{4, 4, 1, 3},
+		{5, 5, 1, 3},
+		{4, 6, 1, 3},
+		{0, 5, 0, 3},
+		{5, 8, 1, 4},
+	}
+	for _, rc := range rangeCases {
+		first, last, ok := a.TokenRange(rc.begin, rc.end)
+		if first != rc.first || last != rc.last || !ok {
+			t.Errorf("TokenRange(%d, %d): got %d, %d, %t; want %d, %d, true",
+				rc.begin, rc.end, first, last, ok, rc.first, rc.last)
+		}
+	}
+
+	tokens := doc.Sentence[0].Token
+	for _, sc := range []struct {
+		begin, end int
+		want       string
+	}{
+		{1, 3, "al"},
+		{0, 3, "Voy al"},
+		{2, 4, "al cine"},
+	} {
+		if got := textoffset.SpanText(tokens[sc.begin:sc.end]); got != sc.want {
+			t.Errorf("SpanText(tokens[%d:%d]): got %q; want %q",
+				sc.begin, sc.end, got, sc.want)
+		}
+	}
+
+	doc.Sentence[0].Token[1].After = testdoc.S(" ")
+	if err = textoffset.Verify(doc); err == nil {
+		t.Error("got nil error for a space between the pieces")
+	}
+}
+
+// NewEmojiDocument returns a document of the text "I 🙂 Zoë. She waved."
+func NewEmojiDocument() *pb.Document {
+	return testdoc.New(
+		[]string{"I", "🙂", "Zoë", "."},
+		[]string{"She", "waved", "."},
+	)
+}
@@ -0,0 +1,35 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package textoffset reconstructs the original text of
+// Stanford CoreNLP 4.5.6 documents and aligns offsets in the text.
+//
+// CoreNLP records the position of a token in three ways:
+// the whitespace around it (the fields before and after),
+// character offsets (beginChar and endChar), and
+// Unicode code point offsets (codepointOffsetBegin and codepointOffsetEnd).
+// As CoreNLP is written in Java, its character offsets count
+// UTF-16 code units rather than bytes,
+// so they cannot be used to slice a Go string directly
+// unless the text is pure ASCII.
+//
+// The type Index converts offsets among UTF-16 code units (chars),
+// code points, and Go byte offsets for a text.
+// The type Aligner maps the tokens of a document to byte spans of its text
+// and maps byte spans back to token ranges.
+package textoffset
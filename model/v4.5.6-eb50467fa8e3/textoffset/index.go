@@ -0,0 +1,177 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package textoffset
+
+import (
+	"slices"
+	"unicode/utf8"
+)
+
+// Index converts offsets in a text among UTF-16 code units
+// (the characters counted by CoreNLP),
+// Unicode code points, and Go byte offsets.
+//
+// All offsets are 0-based.
+// The length of the text (in the corresponding unit) is a valid offset,
+// representing the end of the text.
+//
+// Invalid UTF-8 bytes in the text are treated as
+// U+FFFD (one code point and one UTF-16 code unit) each,
+// consistent with how Go decodes strings.
+type Index struct {
+	text string
+
+	// byteOf[i] and charOf[i] are the byte offset and
+	// the UTF-16 offset of the i-th code point, respectively.
+	// The last elements correspond to the end of the text.
+	byteOf []int
+	charOf []int
+}
+
+// NewIndex creates an Index for the specified text.
+func NewIndex(text string) *Index {
+	n := utf8.RuneCountInString(text)
+	x := &Index{
+		text:   text,
+		byteOf: make([]int, 0, n+1),
+		charOf: make([]int, 0, n+1),
+	}
+	var char int
+	for i, r := range text {
+		x.byteOf = append(x.byteOf, i)
+		x.charOf = append(x.charOf, char)
+		if r >= 0x10000 && r <= utf8.MaxRune {
+			char += 2 // surrogate pair
+		} else {
+			char++
+		}
+	}
+	x.byteOf = append(x.byteOf, len(text))
+	x.charOf = append(x.charOf, char)
+	return x
+}
+
+// Text returns the text of the index.
+func (x *Index) Text() string {
+	return x.text
+}
+
+// NumBytes returns the length of the text in bytes.
+func (x *Index) NumBytes() int {
+	return len(x.text)
+}
+
+// NumCodepoints returns the length of the text in Unicode code points.
+func (x *Index) NumCodepoints() int {
+	return len(x.byteOf) - 1
+}
+
+// NumChars returns the length of the text in UTF-16 code units.
+func (x *Index) NumChars() int {
+	return x.charOf[len(x.charOf)-1]
+}
+
+// CharToByte converts the UTF-16 offset char to the byte offset.
+//
+// ok is false if char is out of range or
+// in the middle of a surrogate pair.
+func (x *Index) CharToByte(char int) (b int, ok bool) {
+	cp, ok := x.CharToCodepoint(char)
+	if !ok {
+		return -1, false
+	}
+	return x.byteOf[cp], true
+}
+
+// ByteToChar converts the byte offset b to the UTF-16 offset.
+//
+// ok is false if b is out of range or
+// in the middle of a UTF-8 encoded code point.
+func (x *Index) ByteToChar(b int) (char int, ok bool) {
+	cp, ok := x.ByteToCodepoint(b)
+	if !ok {
+		return -1, false
+	}
+	return x.charOf[cp], true
+}
+
+// CodepointToByte converts the code point offset cp to the byte offset.
+//
+// ok is false if cp is out of range.
+func (x *Index) CodepointToByte(cp int) (b int, ok bool) {
+	if cp < 0 || cp >= len(x.byteOf) {
+		return -1, false
+	}
+	return x.byteOf[cp], true
+}
+
+// ByteToCodepoint converts the byte offset b to the code point offset.
+//
+// ok is false if b is out of range or
+// in the middle of a UTF-8 encoded code point.
+func (x *Index) ByteToCodepoint(b int) (cp int, ok bool) {
+	return search(x.byteOf, b)
+}
+
+// CharToCodepoint converts the UTF-16 offset char to the code point offset.
+//
+// ok is false if char is out of range or
+// in the middle of a surrogate pair.
+func (x *Index) CharToCodepoint(char int) (cp int, ok bool) {
+	return search(x.charOf, char)
+}
+
+// CodepointToChar converts the code point offset cp to the UTF-16 offset.
+//
+// ok is false if cp is out of range.
+func (x *Index) CodepointToChar(cp int) (char int, ok bool) {
+	if cp < 0 || cp >= len(x.charOf) {
+		return -1, false
+	}
+	return x.charOf[cp], true
+}
+
+// SliceChars returns the text between the UTF-16 offsets
+// begin (inclusive) and end (exclusive).
+//
+// ok is false if either offset is invalid (see CharToByte)
+// or begin is greater than end.
+func (x *Index) SliceChars(begin, end int) (s string, ok bool) {
+	b, ok := x.CharToByte(begin)
+	if !ok {
+		return "", false
+	}
+	e, ok := x.CharToByte(end)
+	if !ok || b > e {
+		return "", false
+	}
+	return x.text[b:e], true
+}
+
+// search finds offset in the ascending slice offsets
+// and returns its index.
+//
+// ok is false if offset is not in offsets.
+func search(offsets []int, offset int) (i int, ok bool) {
+	i, ok = slices.BinarySearch(offsets, offset)
+	if !ok {
+		return -1, false
+	}
+	return i, true
+}
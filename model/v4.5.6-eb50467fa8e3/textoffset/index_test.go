@@ -0,0 +1,116 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package textoffset_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/textoffset"
+)
+
+// IndexText consists of code points of
+// 1 byte (a), 2 bytes (é), 3 bytes (€), and 4 bytes (🙂, a surrogate pair).
+const IndexText = "aé€🙂b"
+
+func TestIndex_Len(t *testing.T) {
+	x := textoffset.NewIndex(IndexText)
+	if x.Text() != IndexText {
+		t.Errorf("got text %q; want %q", x.Text(), IndexText)
+	}
+	if n := x.NumBytes(); n != 11 {
+		t.Errorf("got %d byte(s); want 11", n)
+	}
+	if n := x.NumCodepoints(); n != 5 {
+		t.Errorf("got %d code point(s); want 5", n)
+	}
+	if n := x.NumChars(); n != 6 {
+		t.Errorf("got %d char(s); want 6", n)
+	}
+}
+
+func TestIndex_Convert(t *testing.T) {
+	// Offsets of the boundaries of each code point and the end.
+	bytes := []int{0, 1, 3, 6, 10, 11}
+	chars := []int{0, 1, 2, 3, 5, 6}
+	x := textoffset.NewIndex(IndexText)
+	for cp := range bytes {
+		t.Run(fmt.Sprintf("cp=%d", cp), func(t *testing.T) {
+			if b, ok := x.CodepointToByte(cp); !ok || b != bytes[cp] {
+				t.Errorf("CodepointToByte: got %d, %t; want %d, true",
+					b, ok, bytes[cp])
+			}
+			if c, ok := x.CodepointToChar(cp); !ok || c != chars[cp] {
+				t.Errorf("CodepointToChar: got %d, %t; want %d, true",
+					c, ok, chars[cp])
+			}
+			if got, ok := x.ByteToCodepoint(bytes[cp]); !ok || got != cp {
+				t.Errorf("ByteToCodepoint: got %d, %t; want %d, true",
+					got, ok, cp)
+			}
+			if got, ok := x.CharToCodepoint(chars[cp]); !ok || got != cp {
+				t.Errorf("CharToCodepoint: got %d, %t; want %d, true",
+					got, ok, cp)
+			}
+			if b, ok := x.CharToByte(chars[cp]); !ok || b != bytes[cp] {
+				t.Errorf("CharToByte: got %d, %t; want %d, true",
+					b, ok, bytes[cp])
+			}
+			if c, ok := x.ByteToChar(bytes[cp]); !ok || c != chars[cp] {
+				t.Errorf("ByteToChar: got %d, %t; want %d, true",
+					c, ok, chars[cp])
+			}
+		})
+	}
+}
+
+func TestIndex_Invalid(t *testing.T) {
+	x := textoffset.NewIndex(IndexText)
+	for _, b := range []int{-1, 2, 4, 7, 12} {
+		if _, ok := x.ByteToChar(b); ok {
+			t.Errorf("ByteToChar(%d): got ok true", b)
+		}
+	}
+	for _, c := range []int{-1, 4, 7} {
+		if _, ok := x.CharToByte(c); ok {
+			t.Errorf("CharToByte(%d): got ok true", c)
+		}
+	}
+	for _, cp := range []int{-1, 6} {
+		if _, ok := x.CodepointToByte(cp); ok {
+			t.Errorf("CodepointToByte(%d): got ok true", cp)
+		}
+		if _, ok := x.CodepointToChar(cp); ok {
+			t.Errorf("CodepointToChar(%d): got ok true", cp)
+		}
+	}
+}
+
+func TestIndex_SliceChars(t *testing.T) {
+	x := textoffset.NewIndex(IndexText)
+	if s, ok := x.SliceChars(2, 5); !ok || s != "€🙂" {
+		t.Errorf("got %q, %t; want \"€🙂\", true", s, ok)
+	}
+	if _, ok := x.SliceChars(2, 4); ok {
+		t.Error("got ok true for slicing in a surrogate pair")
+	}
+	if _, ok := x.SliceChars(3, 2); ok {
+		t.Error("got ok true for begin greater than end")
+	}
+}
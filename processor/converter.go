@@ -0,0 +1,109 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package processor
+
+import (
+	gogoerrors "github.com/donyori/gogo/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// DependencyConverterClass is the Java class of Stanford CoreNLP
+// that converts constituency trees to dependency graphs.
+const DependencyConverterClass = "edu.stanford.nlp.trees.ProcessDependencyConverterRequest"
+
+// DependencyConverter converts constituency parse trees
+// to (basic Universal Dependencies) dependency graphs,
+// backed by a Java process running DependencyConverterClass.
+//
+// It is safe for concurrent use; the requests are processed one by one.
+type DependencyConverter struct {
+	c         *conn
+	batchSize int
+}
+
+// NewDependencyConverter starts a Java process running
+// DependencyConverterClass with the specified options and
+// returns a DependencyConverter communicating with it.
+//
+// If opt is nil, it uses default options.
+//
+// The client should call the method Close to stop the process
+// after use.
+func NewDependencyConverter(opt *Options) (*DependencyConverter, error) {
+	c, err := start(DependencyConverterClass, opt)
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	return &DependencyConverter{c: c, batchSize: opt.batchSize()}, nil
+}
+
+// Convert converts the trees (FlattenedParseTree) in req
+// to dependency graphs and stores the results in resp.
+//
+// req must be a non-nil pointer to an auto-generated
+// DependencyConverterRequest, and resp must be a non-nil pointer to
+// an auto-generated DependencyConverterResponse of the same version.
+// To build a request from trees in the Penn Treebank (PTB) format,
+// use the function NewDependencyConverterRequest in the subpackage parsetree
+// of the model version, for example:
+//
+//	import (
+//		"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/parsetree"
+//		"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+//	)
+//	...
+//	req, err := parsetree.NewDependencyConverterRequest(
+//		"(ROOT (S (NP (NNS Roses)) (VP (VBP are) (ADJP (JJ red)))))")
+//	...
+//	resp := new(pb.DependencyConverterResponse)
+//	err = c.Convert(req, resp)
+//	...
+//
+// The trees are sent in batches of at most Options.BatchSize trees.
+// The i-th conversion in resp corresponds to the i-th tree in req,
+// and its field tree is set to that tree if the processor leaves it unset,
+// so that each graph is paired with its original tree.
+func (dc *DependencyConverter) Convert(req, resp proto.Message) error {
+	err := checkMessage(req, "DependencyConverterRequest", "req")
+	if err == nil {
+		err = checkMessage(resp, "DependencyConverterResponse", "resp")
+	}
+	if err == nil {
+		err = dc.c.processBatches(req, resp, "trees", "conversions",
+			dc.batchSize, pairConversion)
+	}
+	return gogoerrors.AutoWrap(err)
+}
+
+// Close tells the Java process to exit and waits for it.
+//
+// After Close, Convert reports ErrClosed.
+func (dc *DependencyConverter) Close() error {
+	return gogoerrors.AutoWrap(dc.c.close())
+}
+
+// pairConversion sets the field tree of the conversion to
+// the requested tree if it is unset.
+func pairConversion(tree, conversion protoreflect.Message) {
+	fd := conversion.Descriptor().Fields().ByName("tree")
+	if fd != nil && !conversion.Has(fd) {
+		conversion.Set(fd, protoreflect.ValueOfMessage(tree))
+	}
+}
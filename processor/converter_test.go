@@ -0,0 +1,127 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package processor_test
+
+import (
+	"fmt"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/parsetree"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+	"github.com/donyori/gocorenlp/processor"
+)
+
+// FakeConvert converts each tree to a chain of its leaves,
+// rooted at the first leaf.
+// It echoes the tree only in the even-numbered conversions.
+func FakeConvert(req []byte) ([]byte, error) {
+	dcr := new(pb.DependencyConverterRequest)
+	if err := proto.Unmarshal(req, dcr); err != nil {
+		return nil, err
+	}
+	resp := new(pb.DependencyConverterResponse)
+	for i, f := range dcr.GetTrees() {
+		t, err := parsetree.Unflatten(f)
+		if err != nil {
+			return nil, err
+		}
+		g := &pb.DependencyGraph{Root: []uint32{1}}
+		for j := range parsetree.Leaves(t) {
+			g.Node = append(g.Node, &pb.DependencyGraph_Node{
+				SentenceIndex: proto.Uint32(0),
+				Index:         proto.Uint32(uint32(j + 1)),
+			})
+			if j > 0 {
+				g.Edge = append(g.Edge, &pb.DependencyGraph_Edge{
+					Source: proto.Uint32(uint32(j)),
+					Target: proto.Uint32(uint32(j + 1)),
+					Dep:    proto.String("dep"),
+				})
+			}
+		}
+		conversion := &pb.DependencyConverterResponse_DependencyConversion{
+			Graph: g,
+		}
+		if i%2 == 0 {
+			conversion.Tree = f
+		}
+		resp.Conversions = append(resp.Conversions, conversion)
+	}
+	return proto.Marshal(resp)
+}
+
+func TestDependencyConverter_Convert(t *testing.T) {
+	ptbTrees := []string{
+		"(ROOT (S (NP (NNS Roses)) (VP (VBP are) (ADJP (JJ red))) (. .)))",
+		"(ROOT (S (NP (NNS Violets)) (VP (VBP are) (ADJP (JJ blue))) (. .)))",
+		"(ROOT (S (NP (NN Sugar)) (VP (VBZ is) (ADJP (JJ sweet))) (. .)))",
+	}
+	for _, batchSize := range []int{1, 2, 3} {
+		t.Run(fmt.Sprintf("batchSize=%d", batchSize), func(t *testing.T) {
+			fp := StartFakeProcessor(Must(t, FakeConvert))
+			dc := processor.NewDependencyConverterWithConn(
+				fp.R, fp.W, fp.Wait, batchSize)
+			defer func() {
+				if err := dc.Close(); err != nil {
+					t.Error("close -", err)
+				}
+			}()
+			req, err := parsetree.NewDependencyConverterRequest(ptbTrees...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp := new(pb.DependencyConverterResponse)
+			if err = dc.Convert(req, resp); err != nil {
+				t.Fatal(err)
+			}
+			if n := len(resp.GetConversions()); n != len(ptbTrees) {
+				t.Fatalf("got %d conversions; want %d", n, len(ptbTrees))
+			}
+			for i, c := range resp.GetConversions() {
+				s, err := parsetree.ToPTB(c.GetTree())
+				if err != nil {
+					t.Errorf("conversion#%d - %v", i, err)
+				} else if s != ptbTrees[i] {
+					t.Errorf("conversion#%d: got tree %q; want %q",
+						i, s, ptbTrees[i])
+				}
+				if n := len(c.GetGraph().GetNode()); n != 4 {
+					t.Errorf("conversion#%d: got %d graph nodes; want 4", i, n)
+				}
+			}
+		})
+	}
+}
+
+func TestDependencyConverter_Convert_WrongType(t *testing.T) {
+	fp := StartFakeProcessor(Must(t, FakeConvert))
+	dc := processor.NewDependencyConverterWithConn(fp.R, fp.W, fp.Wait, 0)
+	defer func() {
+		if err := dc.Close(); err != nil {
+			t.Error("close -", err)
+		}
+	}()
+	err := dc.Convert(
+		new(pb.MorphologyRequest), new(pb.DependencyConverterResponse))
+	if err == nil {
+		t.Error("got nil error for a MorphologyRequest")
+	}
+}
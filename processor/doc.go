@@ -0,0 +1,36 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package processor provides access to the request processors of
+// Stanford CoreNLP that are not exposed by the CoreNLP server.
+//
+// Since v4.2.1, Stanford CoreNLP ships Java classes that accept
+// ProtoBuf requests (such as MorphologyRequest) and reply with
+// ProtoBuf responses, but the CoreNLP server has no HTTP endpoints for them.
+// This package starts such a class in a Java process
+// (with the option -multiple, so that one process serves many requests)
+// and communicates with it through the standard input and output.
+//
+// Like the package client, this package works with any version
+// of the auto-generated ProtoBuf structures
+// (for example, github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb).
+// The requests and responses are passed as proto.Message.
+//
+// Java and Stanford CoreNLP must be available on the local machine.
+// See Options for how they are located.
+package processor
@@ -0,0 +1,106 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package processor
+
+import (
+	gogoerrors "github.com/donyori/gogo/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// DependencyEnhancerClass is the Java class of Stanford CoreNLP
+// that computes enhanced dependencies.
+const DependencyEnhancerClass = "edu.stanford.nlp.trees.ud.ProcessUniversalEnhancerRequest"
+
+// DependencyEnhancer computes the enhanced (Universal Dependencies)
+// dependency graphs of documents parsed elsewhere,
+// backed by a Java process running DependencyEnhancerClass.
+//
+// It is safe for concurrent use; the requests are processed one by one.
+type DependencyEnhancer struct {
+	c *conn
+}
+
+// NewDependencyEnhancer starts a Java process running
+// DependencyEnhancerClass with the specified options and
+// returns a DependencyEnhancer communicating with it.
+//
+// If opt is nil, it uses default options.
+//
+// The client should call the method Close to stop the process
+// after use.
+func NewDependencyEnhancer(opt *Options) (*DependencyEnhancer, error) {
+	c, err := start(DependencyEnhancerClass, opt)
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	return &DependencyEnhancer{c: c}, nil
+}
+
+// Enhance sends the document in req to the enhancer and
+// stores the enhanced document in outDoc.
+//
+// req must be a non-nil pointer to an auto-generated
+// DependencyEnhancerRequest, and outDoc must be a non-nil pointer to
+// an auto-generated Document of the same version.
+// The sentences of the document in req must have
+// basic dependencies (field basicDependencies).
+// The enhanced dependencies are stored in the fields
+// enhancedDependencies and enhancedPlusPlusDependencies of
+// the sentences of outDoc.
+//
+// Exactly one of the fields language and relativePronouns
+// of req must be set.
+// The language selects the built-in relative pronouns of that language,
+// while relativePronouns is a custom regular expression
+// matching relative pronouns, for example:
+//
+//	import "github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+//	...
+//	req := &pb.DependencyEnhancerRequest{
+//		Document: doc,
+//		Ref: &pb.DependencyEnhancerRequest_Language{
+//			Language: pb.Language_UniversalEnglish,
+//		},
+//	}
+//	outDoc := new(pb.Document)
+//	err := e.Enhance(req, outDoc)
+//	...
+func (e *DependencyEnhancer) Enhance(req, outDoc proto.Message) error {
+	err := checkMessage(req, "DependencyEnhancerRequest", "req")
+	if err == nil {
+		err = checkMessage(outDoc, "Document", "outDoc")
+	}
+	if err != nil {
+		return gogoerrors.AutoWrap(err)
+	}
+	reqMsg := req.ProtoReflect()
+	od := reqMsg.Descriptor().Oneofs().ByName("ref")
+	if od != nil && reqMsg.WhichOneof(od) == nil {
+		return gogoerrors.AutoNew(
+			"neither language nor relativePronouns is set in req")
+	}
+	return gogoerrors.AutoWrap(e.c.process(req, outDoc))
+}
+
+// Close tells the Java process to exit and waits for it.
+//
+// After Close, Enhance reports ErrClosed.
+func (e *DependencyEnhancer) Close() error {
+	return gogoerrors.AutoWrap(e.c.close())
+}
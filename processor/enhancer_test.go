@@ -0,0 +1,158 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package processor_test
+
+import (
+	"fmt"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+	"github.com/donyori/gocorenlp/processor"
+)
+
+// FakeEnhance copies the basic dependencies of each sentence to
+// its enhanced dependencies, with the relation names suffixed by
+// the language or the relative pronoun regular expression in the request.
+func FakeEnhance(req []byte) ([]byte, error) {
+	der := new(pb.DependencyEnhancerRequest)
+	if err := proto.Unmarshal(req, der); err != nil {
+		return nil, err
+	}
+	var suffix string
+	switch ref := der.GetRef().(type) {
+	case *pb.DependencyEnhancerRequest_Language:
+		suffix = ref.Language.String()
+	case *pb.DependencyEnhancerRequest_RelativePronouns:
+		suffix = ref.RelativePronouns
+	default:
+		return nil, fmt.Errorf("unknown ref %T", ref)
+	}
+	doc := der.GetDocument()
+	for _, s := range doc.GetSentence() {
+		g := proto.Clone(s.GetBasicDependencies()).(*pb.DependencyGraph)
+		for _, e := range g.GetEdge() {
+			e.Dep = proto.String(e.GetDep() + ":" + suffix)
+		}
+		s.EnhancedDependencies = g
+	}
+	return proto.Marshal(doc)
+}
+
+func TestDependencyEnhancer_Enhance(t *testing.T) {
+	doc := &pb.Document{
+		Text: proto.String("Roses are red."),
+		Sentence: []*pb.Sentence{{
+			TokenOffsetBegin: proto.Uint32(0),
+			TokenOffsetEnd:   proto.Uint32(2),
+			Token: []*pb.Token{
+				{Word: proto.String("Roses")},
+				{Word: proto.String("red")},
+			},
+			BasicDependencies: &pb.DependencyGraph{
+				Node: []*pb.DependencyGraph_Node{
+					{SentenceIndex: proto.Uint32(0), Index: proto.Uint32(1)},
+					{SentenceIndex: proto.Uint32(0), Index: proto.Uint32(2)},
+				},
+				Edge: []*pb.DependencyGraph_Edge{{
+					Source: proto.Uint32(2),
+					Target: proto.Uint32(1),
+					Dep:    proto.String("nsubj"),
+				}},
+				Root: []uint32{2},
+			},
+		}},
+	}
+	testCases := []struct {
+		ref     any
+		wantDep string
+	}{
+		{
+			&pb.DependencyEnhancerRequest_Language{
+				Language: pb.Language_UniversalEnglish,
+			},
+			"nsubj:UniversalEnglish",
+		},
+		{
+			&pb.DependencyEnhancerRequest_RelativePronouns{
+				RelativePronouns: "(?i:that|which|who)",
+			},
+			"nsubj:(?i:that|which|who)",
+		},
+	}
+	fp := StartFakeProcessor(Must(t, FakeEnhance))
+	e := processor.NewDependencyEnhancerWithConn(fp.R, fp.W, fp.Wait)
+	defer func() {
+		if err := e.Close(); err != nil {
+			t.Error("close -", err)
+		}
+	}()
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("ref=%T", tc.ref), func(t *testing.T) {
+			req := &pb.DependencyEnhancerRequest{Document: doc}
+			switch ref := tc.ref.(type) {
+			case *pb.DependencyEnhancerRequest_Language:
+				req.Ref = ref
+			case *pb.DependencyEnhancerRequest_RelativePronouns:
+				req.Ref = ref
+			}
+			outDoc := new(pb.Document)
+			if err := e.Enhance(req, outDoc); err != nil {
+				t.Fatal(err)
+			}
+			edges := outDoc.GetSentence()[0].GetEnhancedDependencies().GetEdge()
+			if len(edges) != 1 {
+				t.Fatalf("got %d enhanced edges; want 1", len(edges))
+			}
+			if dep := edges[0].GetDep(); dep != tc.wantDep {
+				t.Errorf("got dep %q; want %q", dep, tc.wantDep)
+			}
+			if doc.GetSentence()[0].GetEnhancedDependencies() != nil {
+				t.Error("the input document was modified")
+			}
+		})
+	}
+}
+
+func TestDependencyEnhancer_Enhance_Error(t *testing.T) {
+	fp := StartFakeProcessor(Must(t, FakeEnhance))
+	e := processor.NewDependencyEnhancerWithConn(fp.R, fp.W, fp.Wait)
+	defer func() {
+		if err := e.Close(); err != nil {
+			t.Error("close -", err)
+		}
+	}()
+	doc := &pb.Document{Text: proto.String("")}
+	err := e.Enhance(&pb.DependencyEnhancerRequest{Document: doc}, new(pb.Document))
+	if err == nil {
+		t.Error("got nil error for a request without ref")
+	}
+	err = e.Enhance(&pb.DependencyEnhancerRequest{
+		Ref: &pb.DependencyEnhancerRequest_Language{
+			Language: pb.Language_English,
+		},
+	}, new(pb.Document))
+	if err == nil {
+		t.Error("got nil error for a request without document")
+	}
+	if n := fp.NumRequest.Load(); n != 0 {
+		t.Errorf("got %d requests; want 0", n)
+	}
+}
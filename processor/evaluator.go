@@ -0,0 +1,99 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package processor
+
+import (
+	gogoerrors "github.com/donyori/gogo/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// ParserEvaluatorClass is the Java class of Stanford CoreNLP
+// that evaluates the output of external constituency parsers.
+const ParserEvaluatorClass = "edu.stanford.nlp.parser.metrics.EvaluateExternalParser"
+
+// ParserEvaluator computes the bracketing F1 scores
+// of constituency parse trees against gold trees,
+// backed by a Java process running ParserEvaluatorClass.
+//
+// It is safe for concurrent use; the requests are processed one by one.
+type ParserEvaluator struct {
+	c *conn
+}
+
+// NewParserEvaluator starts a Java process running ParserEvaluatorClass
+// with the specified options and returns a ParserEvaluator
+// communicating with it.
+//
+// If opt is nil, it uses default options.
+//
+// The client should call the method Close to stop the process
+// after use.
+func NewParserEvaluator(opt *Options) (*ParserEvaluator, error) {
+	c, err := start(ParserEvaluatorClass, opt)
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	return &ParserEvaluator{c: c}, nil
+}
+
+// Evaluate evaluates the predicted trees in req against the gold trees
+// and stores the scores in resp.
+//
+// req must be a non-nil pointer to an auto-generated
+// EvaluateParserRequest, and resp must be a non-nil pointer to
+// an auto-generated EvaluateParserResponse of the same version.
+// To build a request, use the function NewEvaluateParserRequest
+// in the subpackage parsetree of the model version, for example:
+//
+//	import (
+//		"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/parsetree"
+//		"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+//	)
+//	...
+//	req, err := parsetree.NewEvaluateParserRequest(goldTrees, predictedTrees)
+//	...
+//	resp := new(pb.EvaluateParserResponse)
+//	err = pe.Evaluate(req, resp)
+//	...
+//
+// The field f1 of resp is the F1 score of the first predicted tree
+// of each gold tree, and the field kbestF1 is set if k-best trees
+// are provided.
+// The same subpackage parsetree provides the function EvaluateLocally
+// to compute these scores without Java.
+//
+// The whole treebank is sent in one request,
+// as the scores are computed over the whole treebank.
+func (pe *ParserEvaluator) Evaluate(req, resp proto.Message) error {
+	err := checkMessage(req, "EvaluateParserRequest", "req")
+	if err == nil {
+		err = checkMessage(resp, "EvaluateParserResponse", "resp")
+	}
+	if err == nil {
+		err = pe.c.process(req, resp)
+	}
+	return gogoerrors.AutoWrap(err)
+}
+
+// Close tells the Java process to exit and waits for it.
+//
+// After Close, Evaluate reports ErrClosed.
+func (pe *ParserEvaluator) Close() error {
+	return gogoerrors.AutoWrap(pe.c.close())
+}
@@ -0,0 +1,94 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package processor_test
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/parsetree"
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+	"github.com/donyori/gocorenlp/processor"
+)
+
+// FakeEvaluate evaluates the request by parsetree.EvaluateLocally.
+func FakeEvaluate(req []byte) ([]byte, error) {
+	epr := new(pb.EvaluateParserRequest)
+	if err := proto.Unmarshal(req, epr); err != nil {
+		return nil, err
+	}
+	resp, err := parsetree.EvaluateLocally(epr)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(resp)
+}
+
+func TestParserEvaluator_Evaluate(t *testing.T) {
+	fp := StartFakeProcessor(Must(t, FakeEvaluate))
+	pe := processor.NewParserEvaluatorWithConn(fp.R, fp.W, fp.Wait)
+	defer func() {
+		if err := pe.Close(); err != nil {
+			t.Error("close -", err)
+		}
+	}()
+	var gold []*pb.ParseTree
+	var predicted [][]*pb.ParseTree
+	for _, pair := range [][2]string{
+		{
+			"(ROOT (S (NP (NNS Roses)) (VP (VBP are) (ADJP (JJ red)))))",
+			"(ROOT (S (NP (NNS Roses)) (VP (VBP are) (ADJP (JJ red)))))",
+		},
+		{
+			"(ROOT (S (NP (NNS Violets)) (VP (VBP are) (ADJP (JJ blue)))))",
+			"(ROOT (S (NP (NNS Violets) (VBP are)) (ADJP (JJ blue))))",
+		},
+	} {
+		g, err := parsetree.Parse(pair[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := parsetree.Parse(pair[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		gold = append(gold, g)
+		predicted = append(predicted, []*pb.ParseTree{p})
+	}
+	req, err := parsetree.NewEvaluateParserRequest(gold, predicted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := new(pb.EvaluateParserResponse)
+	if err = pe.Evaluate(req, resp); err != nil {
+		t.Fatal(err)
+	}
+	// Matched 4 + 2 brackets; 4 + 4 gold and 4 + 3 predicted.
+	if f1, want := resp.GetF1(), 12.0/15; f1 != want {
+		t.Errorf("got f1 %v; want %v", f1, want)
+	}
+	if resp.KbestF1 != nil {
+		t.Errorf("got kbestF1 %v; want unset", resp.GetKbestF1())
+	}
+	err = pe.Evaluate(req, new(pb.DependencyConverterResponse))
+	if err == nil {
+		t.Error("got nil error for a DependencyConverterResponse")
+	}
+}
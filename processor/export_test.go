@@ -0,0 +1,84 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package processor
+
+import "io"
+
+// Export for testing only.
+
+// NewMorphologyWithConn creates a Morphology that reads responses from r
+// and writes requests to w, instead of starting a Java process.
+//
+// wait is called on closing, after closing w. It can be nil.
+// A non-positive batchSize means DefaultBatchSize.
+func NewMorphologyWithConn(
+	r io.Reader,
+	w io.WriteCloser,
+	wait func() error,
+	batchSize int,
+) *Morphology {
+	return &Morphology{
+		c:         newConn(r, w, wait),
+		batchSize: (&Options{BatchSize: batchSize}).batchSize(),
+	}
+}
+
+// NewDependencyConverterWithConn creates a DependencyConverter that
+// reads responses from r and writes requests to w,
+// instead of starting a Java process.
+//
+// wait is called on closing, after closing w. It can be nil.
+// A non-positive batchSize means DefaultBatchSize.
+func NewDependencyConverterWithConn(
+	r io.Reader,
+	w io.WriteCloser,
+	wait func() error,
+	batchSize int,
+) *DependencyConverter {
+	return &DependencyConverter{
+		c:         newConn(r, w, wait),
+		batchSize: (&Options{BatchSize: batchSize}).batchSize(),
+	}
+}
+
+// NewDependencyEnhancerWithConn creates a DependencyEnhancer that
+// reads responses from r and writes requests to w,
+// instead of starting a Java process.
+//
+// wait is called on closing, after closing w. It can be nil.
+func NewDependencyEnhancerWithConn(
+	r io.Reader,
+	w io.WriteCloser,
+	wait func() error,
+) *DependencyEnhancer {
+	return &DependencyEnhancer{c: newConn(r, w, wait)}
+}
+
+// NewParserEvaluatorWithConn creates a ParserEvaluator that
+// reads responses from r and writes requests to w,
+// instead of starting a Java process.
+//
+// wait is called on closing, after closing w. It can be nil.
+func NewParserEvaluatorWithConn(
+	r io.Reader,
+	w io.WriteCloser,
+	wait func() error,
+) *ParserEvaluator {
+	return &ParserEvaluator{c: newConn(r, w, wait)}
+}
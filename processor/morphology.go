@@ -0,0 +1,92 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package processor
+
+import (
+	gogoerrors "github.com/donyori/gogo/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// MorphologyClass is the Java class of Stanford CoreNLP
+// that processes morphology requests.
+const MorphologyClass = "edu.stanford.nlp.process.ProcessMorphologyRequest"
+
+// Morphology is a lemmatizer backed by a Java process
+// running MorphologyClass.
+//
+// It lemmatizes words that have been tagged elsewhere,
+// without running the full annotation pipeline.
+//
+// It is safe for concurrent use; the requests are processed one by one.
+type Morphology struct {
+	c         *conn
+	batchSize int
+}
+
+// NewMorphology starts a Java process running MorphologyClass
+// with the specified options and returns a Morphology communicating with it.
+//
+// If opt is nil, it uses default options.
+//
+// The client should call the method Close to stop the process
+// after use.
+func NewMorphology(opt *Options) (*Morphology, error) {
+	c, err := start(MorphologyClass, opt)
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	return &Morphology{c: c, batchSize: opt.batchSize()}, nil
+}
+
+// Lemmatize lemmatizes the tagged words in req
+// and stores the results in resp.
+//
+// req must be a non-nil pointer to an auto-generated MorphologyRequest,
+// and resp must be a non-nil pointer to an auto-generated MorphologyResponse
+// of the same version, for example:
+//
+//	import "github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+//	...
+//	req := &pb.MorphologyRequest{Words: []*pb.MorphologyRequest_TaggedWord{
+//		{Word: proto.String("running"), Xpos: proto.String("VBG")},
+//	}}
+//	resp := new(pb.MorphologyResponse)
+//	err := m.Lemmatize(req, resp)
+//	...
+//
+// The words are sent in batches of at most Options.BatchSize words.
+// The i-th word (WordTagLemma) in resp corresponds to
+// the i-th word (TaggedWord) in req.
+func (m *Morphology) Lemmatize(req, resp proto.Message) error {
+	err := checkMessage(req, "MorphologyRequest", "req")
+	if err == nil {
+		err = checkMessage(resp, "MorphologyResponse", "resp")
+	}
+	if err == nil {
+		err = m.c.processBatches(req, resp, "words", "words", m.batchSize, nil)
+	}
+	return gogoerrors.AutoWrap(err)
+}
+
+// Close tells the Java process to exit and waits for it.
+//
+// After Close, Lemmatize reports ErrClosed.
+func (m *Morphology) Close() error {
+	return gogoerrors.AutoWrap(m.c.close())
+}
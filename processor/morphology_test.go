@@ -0,0 +1,170 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package processor_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+	"github.com/donyori/gocorenlp/processor"
+)
+
+// FakeLemmatize lemmatizes words by converting them to lower case
+// and trimming a trailing "s" of plural nouns.
+func FakeLemmatize(req []byte) ([]byte, error) {
+	mr := new(pb.MorphologyRequest)
+	if err := proto.Unmarshal(req, mr); err != nil {
+		return nil, err
+	}
+	resp := new(pb.MorphologyResponse)
+	for _, w := range mr.GetWords() {
+		lemma := strings.ToLower(w.GetWord())
+		if w.GetXpos() == "NNS" {
+			lemma = strings.TrimSuffix(lemma, "s")
+		}
+		resp.Words = append(resp.Words, &pb.MorphologyResponse_WordTagLemma{
+			Word:  w.Word,
+			Xpos:  w.Xpos,
+			Lemma: proto.String(lemma),
+		})
+	}
+	return proto.Marshal(resp)
+}
+
+func TestMorphology_Lemmatize(t *testing.T) {
+	words := [][2]string{
+		{"Roses", "NNS"},
+		{"are", "VBP"},
+		{"red", "JJ"},
+		{"Violets", "NNS"},
+		{"Blue", "JJ"},
+	}
+	wantLemmas := []string{"rose", "are", "red", "violet", "blue"}
+	for _, batchSize := range []int{1, 2, 5, 100} {
+		t.Run(fmt.Sprintf("batchSize=%d", batchSize), func(t *testing.T) {
+			fp := StartFakeProcessor(Must(t, FakeLemmatize))
+			m := processor.NewMorphologyWithConn(fp.R, fp.W, fp.Wait, batchSize)
+			req := new(pb.MorphologyRequest)
+			for _, w := range words {
+				req.Words = append(req.Words, &pb.MorphologyRequest_TaggedWord{
+					Word: proto.String(w[0]),
+					Xpos: proto.String(w[1]),
+				})
+			}
+			resp := new(pb.MorphologyResponse)
+			if err := m.Lemmatize(req, resp); err != nil {
+				t.Fatal(err)
+			}
+			if n := len(resp.GetWords()); n != len(words) {
+				t.Fatalf("got %d words; want %d", n, len(words))
+			}
+			for i, w := range resp.GetWords() {
+				if w.GetWord() != words[i][0] || w.GetXpos() != words[i][1] ||
+					w.GetLemma() != wantLemmas[i] {
+					t.Errorf("word#%d: got (%q, %q, %q); want (%q, %q, %q)",
+						i, w.GetWord(), w.GetXpos(), w.GetLemma(),
+						words[i][0], words[i][1], wantLemmas[i])
+				}
+			}
+			wantNumReq := int32((len(words) + batchSize - 1) / batchSize)
+			if n := fp.NumRequest.Load(); n != wantNumReq {
+				t.Errorf("got %d requests; want %d", n, wantNumReq)
+			}
+			if err := m.Close(); err != nil {
+				t.Error("close -", err)
+			}
+			err := m.Lemmatize(req, resp)
+			if !errors.Is(err, processor.ErrClosed) {
+				t.Errorf("after close, got error %v; want ErrClosed", err)
+			}
+		})
+	}
+}
+
+func TestMorphology_Lemmatize_Empty(t *testing.T) {
+	fp := StartFakeProcessor(Must(t, FakeLemmatize))
+	m := processor.NewMorphologyWithConn(fp.R, fp.W, fp.Wait, 0)
+	defer func() {
+		if err := m.Close(); err != nil {
+			t.Error("close -", err)
+		}
+	}()
+	resp := &pb.MorphologyResponse{
+		Words: []*pb.MorphologyResponse_WordTagLemma{{}},
+	}
+	if err := m.Lemmatize(new(pb.MorphologyRequest), resp); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(resp.GetWords()); n != 0 {
+		t.Errorf("got %d words; want 0", n)
+	}
+	if n := fp.NumRequest.Load(); n != 0 {
+		t.Errorf("got %d requests; want 0", n)
+	}
+}
+
+func TestMorphology_Lemmatize_Mismatch(t *testing.T) {
+	fp := StartFakeProcessor(func([]byte) []byte {
+		b, _ := proto.Marshal(&pb.MorphologyResponse{
+			Words: []*pb.MorphologyResponse_WordTagLemma{{
+				Word:  proto.String("x"),
+				Lemma: proto.String("x"),
+			}},
+		})
+		return b
+	})
+	m := processor.NewMorphologyWithConn(fp.R, fp.W, fp.Wait, 0)
+	defer func() {
+		if err := m.Close(); err != nil {
+			t.Error("close -", err)
+		}
+	}()
+	req := &pb.MorphologyRequest{
+		Words: []*pb.MorphologyRequest_TaggedWord{
+			{Word: proto.String("a")},
+			{Word: proto.String("b")},
+		},
+	}
+	if err := m.Lemmatize(req, new(pb.MorphologyResponse)); err == nil {
+		t.Error("got nil error for a mismatched response")
+	}
+}
+
+func TestMorphology_Lemmatize_WrongType(t *testing.T) {
+	fp := StartFakeProcessor(Must(t, FakeLemmatize))
+	m := processor.NewMorphologyWithConn(fp.R, fp.W, fp.Wait, 0)
+	defer func() {
+		if err := m.Close(); err != nil {
+			t.Error("close -", err)
+		}
+	}()
+	err := m.Lemmatize(new(pb.Document), new(pb.MorphologyResponse))
+	if err == nil {
+		t.Error("got nil error for a Document request")
+	}
+	err = m.Lemmatize(new(pb.MorphologyRequest), nil)
+	if err == nil {
+		t.Error("got nil error for a nil response")
+	}
+}
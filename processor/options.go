@@ -0,0 +1,102 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package processor
+
+import (
+	"io"
+
+	"github.com/donyori/gocorenlp/internal/javacmd"
+)
+
+// DefaultBatchSize is the default maximum number of items
+// sent to a processor in one request.
+const DefaultBatchSize = 1000
+
+// Options are the configuration for starting a new processor.
+type Options struct {
+	// JavaPath is the path to the Java executable.
+	//
+	// Default: "bin/java" under the environment variable JAVA_HOME
+	// if JAVA_HOME is set, or "java" (looked up in PATH) otherwise.
+	JavaPath string `json:"javaPath,omitempty"`
+
+	// Classpath is the Java class path containing Stanford CoreNLP
+	// and its models.
+	//
+	// Default: "*" under the environment variable CORENLP_HOME
+	// if CORENLP_HOME is set, or the value of
+	// the environment variable CLASSPATH otherwise.
+	Classpath string `json:"classpath,omitempty"`
+
+	// Memory is the maximum heap size of the Java virtual machine,
+	// such as "4g".
+	//
+	// Default: "" (empty, use the default of the Java virtual machine)
+	Memory string `json:"memory,omitempty"`
+
+	// JavaOptions are additional options for the Java virtual machine.
+	//
+	// Default: nil
+	JavaOptions []string `json:"javaOptions,omitempty"`
+
+	// BatchSize is the maximum number of items (such as words or trees)
+	// sent to the processor in one request.
+	// Larger inputs are split into several requests transparently.
+	//
+	// A non-positive value means DefaultBatchSize.
+	//
+	// Default: 1000
+	BatchSize int `json:"batchSize,omitempty"`
+
+	// Stderr is the writer to which the standard error of
+	// the Java process is written.
+	//
+	// If nil, the standard error is discarded.
+	//
+	// Default: nil
+	Stderr io.Writer `json:"-"`
+
+	// onlyKeyedLiterals forces others to construct Options
+	// only with the keyed literals, so future additions to it
+	// will not violate compatibility.
+	onlyKeyedLiterals struct{}
+}
+
+var _ = Options{}.onlyKeyedLiterals // to suppress "field `onlyKeyedLiterals` is unused (unused)"
+
+// javaConfig returns the configuration for starting the Java process.
+func (opt *Options) javaConfig() *javacmd.Config {
+	if opt == nil {
+		return nil
+	}
+	return &javacmd.Config{
+		Java:      opt.JavaPath,
+		Classpath: opt.Classpath,
+		Memory:    opt.Memory,
+		Options:   opt.JavaOptions,
+	}
+}
+
+// batchSize returns the effective batch size.
+func (opt *Options) batchSize() int {
+	if opt == nil || opt.BatchSize <= 0 {
+		return DefaultBatchSize
+	}
+	return opt.BatchSize
+}
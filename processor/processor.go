@@ -0,0 +1,261 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package processor
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/donyori/gocorenlp/errors"
+	"github.com/donyori/gocorenlp/model"
+)
+
+// ErrClosed is an error indicating that the processor has been closed,
+// either by its method Close or after an I/O error
+// with the Java process in the middle of a request.
+//
+// The client should use errors.Is to test whether an error is ErrClosed.
+var ErrClosed = gogoerrors.New("processor is closed")
+
+// conn is the connection to a Java request processor
+// started with the option -multiple.
+//
+// Each message is framed by its length as a 4-byte big-endian integer.
+// A length of zero tells the processor to exit.
+//
+// An I/O error in the middle of a frame leaves the stream out of sync,
+// so the conn is closed on such an error (see method abandon).
+type conn struct {
+	mu     sync.Mutex
+	w      io.WriteCloser
+	r      io.Reader
+	wait   func() error // wait for the process to exit; nil if no process
+	kill   func() error // kill the process; nil if no process
+	closed bool
+}
+
+// start starts a Java process running the specified class
+// and returns the connection to it.
+func start(class string, opt *Options) (*conn, error) {
+	cmd := opt.javaConfig().Command(class, "-multiple")
+	if opt != nil {
+		cmd.Stderr = opt.Stderr
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	err = cmd.Start()
+	if err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	c := newConn(stdout, stdin, cmd.Wait)
+	c.kill = cmd.Process.Kill
+	return c, nil
+}
+
+// newConn creates a new conn that reads responses from r
+// and writes requests to w.
+//
+// wait is called after closing w to wait for the processor to exit.
+// It can be nil.
+func newConn(r io.Reader, w io.WriteCloser, wait func() error) *conn {
+	return &conn{w: w, r: bufio.NewReader(r), wait: wait}
+}
+
+// process sends req to the processor and stores its reply in resp.
+func (c *conn) process(req, resp proto.Message) error {
+	b, err := proto.Marshal(req)
+	if err != nil {
+		return gogoerrors.AutoWrap(errors.NewProtoBufError(
+			"google.golang.org/protobuf/proto.Marshal",
+			req,
+			err,
+		))
+	} else if len(b) == 0 {
+		// A zero length would tell the processor to exit.
+		return gogoerrors.AutoNew("request is empty")
+	} else if len(b) > math.MaxInt32 {
+		return gogoerrors.AutoNew(fmt.Sprintf(
+			"request is too large (%d bytes)", len(b)))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return gogoerrors.AutoWrap(ErrClosed)
+	}
+	frame := make([]byte, 4, 4+len(b))
+	binary.BigEndian.PutUint32(frame, uint32(len(b)))
+	_, err = c.w.Write(append(frame, b...))
+	if err != nil {
+		return gogoerrors.AutoWrap(c.abandon(fmt.Errorf(
+			"failed to write the request: %w", err)))
+	}
+	var header [4]byte
+	_, err = io.ReadFull(c.r, header[:])
+	if err != nil {
+		return gogoerrors.AutoWrap(c.abandon(fmt.Errorf(
+			"failed to read the response length: %w", err)))
+	}
+	b = make([]byte, binary.BigEndian.Uint32(header[:]))
+	_, err = io.ReadFull(c.r, b)
+	if err != nil {
+		return gogoerrors.AutoWrap(c.abandon(fmt.Errorf(
+			"failed to read the response: %w", err)))
+	}
+	return gogoerrors.AutoWrap(model.DecodeMessage(b, resp))
+}
+
+// abandon marks c as closed after the I/O error err,
+// kills the process (if any), closes the writer,
+// and returns an error wrapping both ErrClosed and err.
+//
+// The process is waited for in a new goroutine,
+// as it may be blocked on the abandoned stream.
+//
+// The caller must hold c.mu.
+func (c *conn) abandon(err error) error {
+	c.closed = true
+	if c.kill != nil {
+		_ = c.kill() // ignore error
+	}
+	_ = c.w.Close() // ignore error
+	if c.wait != nil {
+		go func() {
+			_ = c.wait() // ignore error
+		}()
+	}
+	return fmt.Errorf("%w after an I/O error: %w", ErrClosed, err)
+}
+
+// processBatches splits the repeated field reqField of req into batches
+// of at most batchSize items, sends each batch to the processor
+// (together with the other fields of req), and appends the items
+// of the repeated field respField of the replies to resp.
+//
+// It reports an error if a reply does not have
+// as many items as its batch.
+//
+// If pair is not nil, it is called with each request item and
+// its corresponding response item before appending the latter to resp.
+func (c *conn) processBatches(
+	req, resp proto.Message,
+	reqField, respField protoreflect.Name,
+	batchSize int,
+	pair func(reqItem, respItem protoreflect.Message),
+) error {
+	reqMsg, respMsg := req.ProtoReflect(), resp.ProtoReflect()
+	reqFd := reqMsg.Descriptor().Fields().ByName(reqField)
+	respFd := respMsg.Descriptor().Fields().ByName(respField)
+	if reqFd == nil || !reqFd.IsList() {
+		return gogoerrors.AutoNew(fmt.Sprintf(
+			"%s has no repeated field %s", reqMsg.Descriptor().FullName(),
+			reqField))
+	} else if respFd == nil || !respFd.IsList() {
+		return gogoerrors.AutoNew(fmt.Sprintf(
+			"%s has no repeated field %s", respMsg.Descriptor().FullName(),
+			respField))
+	}
+	proto.Reset(resp)
+	items := reqMsg.Get(reqFd).List()
+	n := items.Len()
+	if n == 0 {
+		return nil
+	}
+	out := respMsg.Mutable(respFd).List()
+	for begin := 0; begin < n; begin += batchSize {
+		end := min(begin+batchSize, n)
+		batch := reqMsg.New()
+		reqMsg.Range(func(fd protoreflect.FieldDescriptor,
+			v protoreflect.Value) bool {
+			if fd != reqFd {
+				batch.Set(fd, v)
+			}
+			return true
+		})
+		list := batch.Mutable(reqFd).List()
+		for i := begin; i < end; i++ {
+			list.Append(items.Get(i))
+		}
+		batchResp := respMsg.New()
+		err := c.process(batch.Interface(), batchResp.Interface())
+		if err != nil {
+			return gogoerrors.AutoWrap(err)
+		}
+		got := batchResp.Get(respFd).List()
+		if got.Len() != end-begin {
+			return gogoerrors.AutoNew(fmt.Sprintf(
+				"got %d %s for %d %s in a batch; want the same number",
+				got.Len(), respField, end-begin, reqField))
+		}
+		for i := 0; i < got.Len(); i++ {
+			if pair != nil {
+				pair(items.Get(begin+i).Message(), got.Get(i).Message())
+			}
+			out.Append(got.Get(i))
+		}
+	}
+	return nil
+}
+
+// close tells the processor to exit and waits for it.
+//
+// It does nothing and returns nil if c has already been closed.
+func (c *conn) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	var zero [4]byte
+	_, err := c.w.Write(zero[:])
+	err = gogoerrors.Combine(err, c.w.Close())
+	if c.wait != nil {
+		err = gogoerrors.Combine(err, c.wait())
+	}
+	return gogoerrors.AutoWrap(err)
+}
+
+// checkMessage reports an error if msg is nil or
+// its message name is not the specified name.
+//
+// arg is the name of the argument used in the error message.
+func checkMessage(msg proto.Message, name protoreflect.Name, arg string) error {
+	if msg == nil {
+		return gogoerrors.AutoNew(arg + " is nil")
+	}
+	if got := msg.ProtoReflect().Descriptor().Name(); got != name {
+		return gogoerrors.AutoNew(fmt.Sprintf(
+			"%s is a %s; want a %s", arg, got, name))
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package processor_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/donyori/gocorenlp/model/v4.5.6-eb50467fa8e3/pb"
+	"github.com/donyori/gocorenlp/processor"
+)
+
+// FakeProcessor plays the Java side of a processor started with
+// the option -multiple, replying to each request with handle.
+type FakeProcessor struct {
+	// R and W are the reader and writer for the Go side.
+	R io.Reader
+	W io.WriteCloser
+
+	// NumRequest is the number of requests received.
+	NumRequest atomic.Int32
+
+	done chan error
+}
+
+// StartFakeProcessor starts a FakeProcessor in a new goroutine.
+//
+// handle takes the wire encoding of a request and returns
+// the wire encoding of the response.
+func StartFakeProcessor(handle func(req []byte) []byte) *FakeProcessor {
+	reqR, reqW := io.Pipe()
+	respR, respW := io.Pipe()
+	fp := &FakeProcessor{R: respR, W: reqW, done: make(chan error, 1)}
+	go func() {
+		defer close(fp.done)
+		defer func() {
+			_ = respW.Close() // ignore error
+		}()
+		var header [4]byte
+		for {
+			if _, err := io.ReadFull(reqR, header[:]); err != nil {
+				fp.done <- err
+				return
+			}
+			n := binary.BigEndian.Uint32(header[:])
+			if n == 0 {
+				return
+			}
+			req := make([]byte, n)
+			if _, err := io.ReadFull(reqR, req); err != nil {
+				fp.done <- err
+				return
+			}
+			fp.NumRequest.Add(1)
+			resp := handle(req)
+			binary.BigEndian.PutUint32(header[:], uint32(len(resp)))
+			if _, err := respW.Write(append(header[:], resp...)); err != nil {
+				fp.done <- err
+				return
+			}
+		}
+	}()
+	return fp
+}
+
+// Wait waits for the FakeProcessor to exit and returns its error.
+//
+// It returns nil if the FakeProcessor exits on a zero length.
+func (fp *FakeProcessor) Wait() error {
+	return <-fp.done
+}
+
+// Must returns a handle function that calls f and
+// reports any error to tb.
+func Must(tb testing.TB, f func(req []byte) ([]byte, error)) func([]byte) []byte {
+	return func(req []byte) []byte {
+		resp, err := f(req)
+		if err != nil {
+			tb.Error(err)
+		}
+		return resp
+	}
+}
+
+func TestConn_BrokenStream(t *testing.T) {
+	testCases := []struct {
+		name string
+		// readRequest indicates whether to read the request before reply.
+		readRequest bool
+		// reply writes a broken reply to w, or closes r to fail the write.
+		reply func(r *io.PipeReader, w *io.PipeWriter)
+		cause error
+	}{
+		{
+			name:        "truncated header",
+			readRequest: true,
+			reply: func(_ *io.PipeReader, w *io.PipeWriter) {
+				_, _ = w.Write([]byte{0, 0}) // ignore error
+			},
+			cause: io.ErrUnexpectedEOF,
+		},
+		{
+			name:        "truncated body",
+			readRequest: true,
+			reply: func(_ *io.PipeReader, w *io.PipeWriter) {
+				// Claim 100 bytes but send only 10.
+				_, _ = w.Write(append([]byte{0, 0, 0, 100}, make([]byte, 10)...)) // ignore error
+			},
+			cause: io.ErrUnexpectedEOF,
+		},
+		{
+			name: "write error",
+			reply: func(r *io.PipeReader, _ *io.PipeWriter) {
+				_ = r.Close() // ignore error
+			},
+			cause: io.ErrClosedPipe,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reqR, reqW := io.Pipe()
+			respR, respW := io.Pipe()
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				if tc.readRequest {
+					var header [4]byte
+					if _, err := io.ReadFull(reqR, header[:]); err != nil {
+						return
+					}
+					req := make([]byte, binary.BigEndian.Uint32(header[:]))
+					if _, err := io.ReadFull(reqR, req); err != nil {
+						return
+					}
+				}
+				tc.reply(reqR, respW)
+				_ = respW.Close() // ignore error
+				// Drain the requests until the Go side closes its writer.
+				_, _ = io.Copy(io.Discard, reqR) // ignore error
+			}()
+			wait := func() error {
+				<-done
+				return nil
+			}
+			m := processor.NewMorphologyWithConn(respR, reqW, wait, 0)
+			req := &pb.MorphologyRequest{
+				Words: []*pb.MorphologyRequest_TaggedWord{
+					{Word: proto.String("Roses"), Xpos: proto.String("NNS")},
+				},
+			}
+			err := m.Lemmatize(req, new(pb.MorphologyResponse))
+			if !errors.Is(err, processor.ErrClosed) || !errors.Is(err, tc.cause) {
+				t.Errorf("got error %v; want one wrapping ErrClosed and %v",
+					err, tc.cause)
+			}
+			err = m.Lemmatize(req, new(pb.MorphologyResponse))
+			if !errors.Is(err, processor.ErrClosed) {
+				t.Errorf("second request - got error %v; want ErrClosed", err)
+			}
+			if err = m.Close(); err != nil {
+				t.Error("close -", err)
+			}
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Error("the fake processor is not released")
+			}
+		})
+	}
+}
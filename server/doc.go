@@ -0,0 +1,31 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package server launches and manages a local Stanford CoreNLP server
+// (edu.stanford.nlp.pipeline.StanfordCoreNLPServer) as a child process.
+//
+// The function Start starts the server with typed options,
+// waits until it is ready to accept connections,
+// and captures its logs.
+// The method Stop stops it with the shutdown key written by the server
+// (as the method ShutdownLocal of the package client does),
+// falling back to process signals.
+//
+// Java and Stanford CoreNLP must be available on the local machine.
+// See Options for how they are located.
+package server
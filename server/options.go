@@ -0,0 +1,211 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/donyori/gocorenlp/internal/javacmd"
+)
+
+// Options are the configuration for starting a new server.
+type Options struct {
+	// JavaPath is the path to the Java executable.
+	//
+	// Default: "bin/java" under the environment variable JAVA_HOME
+	// if JAVA_HOME is set, or "java" (looked up in PATH) otherwise.
+	JavaPath string `json:"javaPath,omitempty"`
+
+	// Classpath is the Java class path containing Stanford CoreNLP
+	// and its models.
+	// It can also be the directory containing the jar files
+	// (such as the unzipped Stanford CoreNLP distribution),
+	// in which case all jar files in it are used.
+	//
+	// Default: "*" under the environment variable CORENLP_HOME
+	// if CORENLP_HOME is set, or the value of
+	// the environment variable CLASSPATH otherwise.
+	Classpath string `json:"classpath,omitempty"`
+
+	// Memory is the maximum heap size of the Java virtual machine,
+	// such as "4g".
+	//
+	// Default: "" (empty, use the default of the Java virtual machine)
+	Memory string `json:"memory,omitempty"`
+
+	// JavaOptions are additional options for the Java virtual machine.
+	//
+	// Default: nil
+	JavaOptions []string `json:"javaOptions,omitempty"`
+
+	// Port is the port of the server (option -port).
+	//
+	// Default: 9000
+	Port uint16 `json:"port,omitempty"`
+
+	// StatusPort is the port of the liveness and readiness server
+	// (option -status_port).
+	// If zero, it is the same as Port.
+	//
+	// Default: 0
+	StatusPort uint16 `json:"statusPort,omitempty"`
+
+	// Timeout is the time limit for the server to wait for an annotation
+	// to finish before canceling it (option -timeout).
+	//
+	// A non-positive value means the server default (15 seconds).
+	//
+	// Default: 0
+	Timeout time.Duration `json:"timeout,omitempty"`
+
+	// Threads is the number of threads of the server (option -threads).
+	//
+	// A non-positive value means the server default
+	// (the number of processors).
+	//
+	// Default: 0
+	Threads int `json:"threads,omitempty"`
+
+	// ServerID is the server ID (option -server_id).
+	// It determines the name of the shutdown key file.
+	//
+	// Default: "" (empty)
+	ServerID string `json:"serverID,omitempty"`
+
+	// Preload are the annotators to load when the server starts
+	// (option -preload), separated by commas (,), such as
+	// "tokenize,ssplit,pos".
+	//
+	// Default: "" (empty, no annotator is preloaded)
+	Preload string `json:"preload,omitempty"`
+
+	// PropertiesFile is the path to the properties file of the server
+	// (option -serverProperties).
+	//
+	// Default: "" (empty)
+	PropertiesFile string `json:"propertiesFile,omitempty"`
+
+	// ExtraArgs are additional arguments for the server,
+	// appended after the options above.
+	//
+	// Default: nil
+	ExtraArgs []string `json:"extraArgs,omitempty"`
+
+	// StartTimeout is the time limit for the server to become ready
+	// after starting.
+	//
+	// A non-positive value means 2 minutes.
+	//
+	// Default: 0
+	StartTimeout time.Duration `json:"startTimeout,omitempty"`
+
+	// StopTimeout is the time limit for the server to exit
+	// at each step of stopping (shutdown request, then interrupt signal)
+	// before the next step is taken.
+	//
+	// A non-positive value means 10 seconds.
+	//
+	// Default: 0
+	StopTimeout time.Duration `json:"stopTimeout,omitempty"`
+
+	// LogWriter is the writer to which the standard output and
+	// standard error of the server are copied, in addition to
+	// the captured logs (see Server.Logs).
+	//
+	// Default: nil
+	LogWriter io.Writer `json:"-"`
+
+	// onlyKeyedLiterals forces others to construct Options
+	// only with the keyed literals, so future additions to it
+	// will not violate compatibility.
+	onlyKeyedLiterals struct{}
+}
+
+var _ = Options{}.onlyKeyedLiterals // to suppress "field `onlyKeyedLiterals` is unused (unused)"
+
+// javaConfig returns the configuration for starting the Java process.
+func (opt *Options) javaConfig() *javacmd.Config {
+	cfg := &javacmd.Config{
+		Java:      opt.JavaPath,
+		Classpath: strings.TrimSpace(opt.Classpath),
+		Memory:    opt.Memory,
+		Options:   opt.JavaOptions,
+	}
+	if len(cfg.Classpath) > 0 {
+		if info, err := os.Stat(cfg.Classpath); err == nil && info.IsDir() {
+			cfg.Classpath = filepath.Join(cfg.Classpath, "*")
+		}
+	}
+	return cfg
+}
+
+// args returns the arguments for the server class.
+func (opt *Options) args() []string {
+	args := []string{"-port", strconv.FormatUint(uint64(opt.port()), 10)}
+	if opt.StatusPort != 0 {
+		args = append(args, "-status_port",
+			strconv.FormatUint(uint64(opt.StatusPort), 10))
+	}
+	if opt.Timeout > 0 {
+		args = append(args, "-timeout",
+			strconv.FormatInt(opt.Timeout.Milliseconds(), 10))
+	}
+	if opt.Threads > 0 {
+		args = append(args, "-threads", strconv.Itoa(opt.Threads))
+	}
+	if id := strings.TrimSpace(opt.ServerID); len(id) > 0 {
+		args = append(args, "-server_id", id)
+	}
+	if preload := strings.Join(strings.Fields(opt.Preload), ""); len(preload) > 0 {
+		args = append(args, "-preload", preload)
+	}
+	if len(opt.PropertiesFile) > 0 {
+		args = append(args, "-serverProperties", opt.PropertiesFile)
+	}
+	return append(args, opt.ExtraArgs...)
+}
+
+// port returns the effective port.
+func (opt *Options) port() uint16 {
+	if opt.Port == 0 {
+		return 9000
+	}
+	return opt.Port
+}
+
+// startTimeout returns the effective start timeout.
+func (opt *Options) startTimeout() time.Duration {
+	if opt.StartTimeout <= 0 {
+		return 2 * time.Minute
+	}
+	return opt.StartTimeout
+}
+
+// stopTimeout returns the effective stop timeout.
+func (opt *Options) stopTimeout() time.Duration {
+	if opt.StopTimeout <= 0 {
+		return 10 * time.Second
+	}
+	return opt.StopTimeout
+}
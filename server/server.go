@@ -0,0 +1,270 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	gogoerrors "github.com/donyori/gogo/errors"
+
+	"github.com/donyori/gocorenlp/client"
+)
+
+// MainClass is the Java class of the Stanford CoreNLP server.
+const MainClass = "edu.stanford.nlp.pipeline.StanfordCoreNLPServer"
+
+// MaxLogSize is the maximum number of bytes of the logs captured
+// by a Server. Older logs are discarded.
+const MaxLogSize = 1 << 20
+
+// readyPollInterval is the interval between two readiness checks
+// when starting a server.
+const readyPollInterval = 200 * time.Millisecond
+
+// Server is a Stanford CoreNLP server running as a child process.
+type Server struct {
+	cmd      *exec.Cmd
+	opt      Options
+	logs     *logBuffer
+	c        client.Client
+	done     chan struct{}
+	waitErr  error // valid after done is closed
+	stopOnce sync.Once
+	stopErr  error
+}
+
+// Start starts a Stanford CoreNLP server with the specified options
+// and waits until it is ready to accept connections.
+//
+// If opt is nil, it uses default options.
+//
+// If the server exits or does not become ready within
+// Options.StartTimeout, Start stops it and reports an error
+// including the tail of its logs.
+//
+// The client should call the method Stop to stop the server after use.
+func Start(opt *Options) (*Server, error) {
+	s := &Server{logs: new(logBuffer), done: make(chan struct{})}
+	if opt != nil {
+		s.opt = *opt
+	}
+	// Check the port first, to avoid taking another server
+	// listening on the same port as the started one.
+	for _, port := range []uint16{s.opt.port(), s.opt.StatusPort} {
+		if port == 0 {
+			continue
+		}
+		ln, err := net.Listen("tcp", ":"+strconv.FormatUint(uint64(port), 10))
+		if err != nil {
+			return nil, gogoerrors.AutoWrap(fmt.Errorf(
+				"port %d is unavailable: %w", port, err))
+		}
+		_ = ln.Close() // ignore error
+	}
+	s.cmd = s.opt.javaConfig().Command(MainClass, s.opt.args()...)
+	var w io.Writer = s.logs
+	if s.opt.LogWriter != nil {
+		w = &lockedWriter{w: io.MultiWriter(s.logs, s.opt.LogWriter)}
+	}
+	s.cmd.Stdout, s.cmd.Stderr = w, w
+	if err := s.cmd.Start(); err != nil {
+		return nil, gogoerrors.AutoWrap(err)
+	}
+	go func() {
+		s.waitErr = s.cmd.Wait()
+		close(s.done)
+	}()
+	if err := s.waitReady(); err != nil {
+		_ = s.Stop() // ignore error
+		return nil, gogoerrors.AutoWrap(fmt.Errorf(
+			"%w; server logs:\n%s", err, s.logs.tail(2048)))
+	}
+	return s, nil
+}
+
+// waitReady polls the server until it is ready,
+// it exits, or the start timeout expires.
+func (s *Server) waitReady() error {
+	deadline := time.Now().Add(s.opt.startTimeout())
+	ticker := time.NewTicker(readyPollInterval)
+	defer ticker.Stop()
+	for {
+		if s.c == nil {
+			if c, err := client.New(s.ClientOptions()); err == nil {
+				s.c = c
+			}
+		}
+		if s.c != nil && s.c.Ready() == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return gogoerrors.AutoNew(fmt.Sprintf(
+				"server is not ready after %v", s.opt.startTimeout()))
+		}
+		select {
+		case <-s.done:
+			return gogoerrors.AutoWrap(fmt.Errorf(
+				"server exited before it became ready: %v", s.waitErr))
+		case <-ticker.C:
+		}
+	}
+}
+
+// ClientOptions returns the client options for connecting to the server,
+// with the host, ports, and server ID set.
+func (s *Server) ClientOptions() *client.Options {
+	return &client.Options{
+		Hostname:   "127.0.0.1",
+		Port:       s.opt.port(),
+		StatusPort: s.opt.StatusPort,
+		ServerID:   s.opt.ServerID,
+	}
+}
+
+// Client returns a client connected to the server,
+// created with the options returned by the method ClientOptions.
+func (s *Server) Client() client.Client {
+	return s.c
+}
+
+// Pid returns the process ID of the server.
+func (s *Server) Pid() int {
+	return s.cmd.Process.Pid
+}
+
+// Logs returns the captured standard output and standard error
+// of the server, up to the last MaxLogSize bytes.
+func (s *Server) Logs() string {
+	return s.logs.String()
+}
+
+// Done returns a channel that is closed when the server exits.
+func (s *Server) Done() <-chan struct{} {
+	return s.done
+}
+
+// Wait waits for the server to exit and returns
+// the error reported by os/exec.Cmd.Wait.
+func (s *Server) Wait() error {
+	<-s.done
+	return gogoerrors.AutoWrap(s.waitErr)
+}
+
+// Stop stops the server and waits for it to exit.
+//
+// It first sends a shutdown request with the key written by the server
+// (see the method ShutdownLocal of github.com/donyori/gocorenlp/client.Client).
+// If that fails or the server does not exit within Options.StopTimeout,
+// it sends an interrupt signal, and finally kills the process.
+//
+// It returns nil if the server has exited, whatever its exit status.
+// It is safe to call Stop multiple times.
+func (s *Server) Stop() error {
+	s.stopOnce.Do(func() {
+		s.stopErr = s.stop()
+	})
+	return gogoerrors.AutoWrap(s.stopErr)
+}
+
+// stop is the implementation of the method Stop.
+func (s *Server) stop() error {
+	timeout := s.opt.stopTimeout()
+	exited := func() bool {
+		select {
+		case <-s.done:
+			return true
+		case <-time.After(timeout):
+			return false
+		}
+	}
+	select {
+	case <-s.done:
+		return nil
+	default:
+	}
+	if s.c != nil && s.c.ShutdownLocal() == nil && exited() {
+		return nil
+	}
+	if s.cmd.Process.Signal(os.Interrupt) == nil && exited() {
+		return nil
+	}
+	if err := s.cmd.Process.Kill(); err != nil {
+		select {
+		case <-s.done:
+			return nil
+		default:
+			return gogoerrors.AutoWrap(err)
+		}
+	}
+	<-s.done
+	return nil
+}
+
+// logBuffer is a concurrency-safe buffer that keeps
+// the last MaxLogSize bytes written to it.
+type logBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (lb *logBuffer) Write(p []byte) (n int, err error) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	lb.buf = append(lb.buf, p...)
+	if excess := len(lb.buf) - MaxLogSize; excess > 0 {
+		lb.buf = append(lb.buf[:0], lb.buf[excess:]...)
+	}
+	return len(p), nil
+}
+
+// String returns the content of the buffer.
+func (lb *logBuffer) String() string {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	return string(lb.buf)
+}
+
+// tail returns the last n bytes of the buffer.
+func (lb *logBuffer) tail(n int) string {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	if len(lb.buf) <= n {
+		return string(lb.buf)
+	}
+	return string(lb.buf[len(lb.buf)-n:])
+}
+
+// lockedWriter serializes the writes to w.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (lw *lockedWriter) Write(p []byte) (n int, err error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	return lw.w.Write(p)
+}
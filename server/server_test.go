@@ -0,0 +1,263 @@
+// gocorenlp.  A Go (Golang) client for Stanford CoreNLP server.
+// Copyright (C) 2022-2024  Yuan Gao
+//
+// This file is part of gocorenlp.
+//
+// gocorenlp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package server_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/donyori/gocorenlp/server"
+)
+
+// EnvFakeJava is the environment variable that makes the test binary
+// act as a fake Java executable running a fake Stanford CoreNLP server.
+//
+// Its value is the behavior of the fake server:
+//   - "ok": serve normally and exit on shutdown requests;
+//   - "crash": exit with status 1 immediately;
+//   - "deaf": ignore shutdown requests (to be stopped by a signal).
+const EnvFakeJava = "GOCORENLP_SERVER_TEST_FAKE_JAVA"
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(EnvFakeJava); mode != "" {
+		os.Exit(runFakeJava(mode, os.Args[1:]))
+	}
+	os.Exit(m.Run())
+}
+
+func TestStart(t *testing.T) {
+	t.Setenv(EnvFakeJava, "ok")
+	port, statusPort := FreePort(t), FreePort(t)
+	var log bytes.Buffer
+	opt := &server.Options{
+		JavaPath:     os.Args[0],
+		Classpath:    t.TempDir(),
+		Memory:       "2g",
+		Port:         port,
+		StatusPort:   statusPort,
+		Timeout:      30 * time.Second,
+		Threads:      2,
+		ServerID:     fmt.Sprintf("servertest%d", port),
+		Preload:      "tokenize, ssplit",
+		StartTimeout: 10 * time.Second,
+		LogWriter:    &log,
+	}
+	s, err := server.Start(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := s.Client()
+	if c == nil {
+		t.Fatal("got nil client")
+	}
+	if err = c.Ready(); err != nil {
+		t.Error("ready -", err)
+	}
+	logs := s.Logs()
+	for _, want := range []string{
+		"-Xmx2g",
+		"-cp " + filepath.Join(opt.Classpath, "*"),
+		server.MainClass,
+		fmt.Sprintf("-port %d", port),
+		fmt.Sprintf("-status_port %d", statusPort),
+		"-timeout 30000",
+		"-threads 2",
+		"-server_id " + opt.ServerID,
+		"-preload tokenize,ssplit",
+	} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("logs do not contain %q; logs:\n%s", want, logs)
+		}
+	}
+	if err = s.Stop(); err != nil {
+		t.Fatal("stop -", err)
+	}
+	select {
+	case <-s.Done():
+	default:
+		t.Error("server has not exited after Stop")
+	}
+	if err = s.Wait(); err != nil {
+		t.Error("wait -", err) // exited by the shutdown request
+	}
+	if !strings.Contains(log.String(), "Shutdown successful") {
+		t.Errorf("log writer did not receive the shutdown log; got:\n%s",
+			log.String())
+	}
+	if err = s.Stop(); err != nil {
+		t.Error("stop again -", err)
+	}
+}
+
+func TestStart_Crash(t *testing.T) {
+	t.Setenv(EnvFakeJava, "crash")
+	_, err := server.Start(&server.Options{
+		JavaPath:     os.Args[0],
+		Port:         FreePort(t),
+		StartTimeout: 10 * time.Second,
+	})
+	if err == nil {
+		t.Fatal("got nil error")
+	}
+	if !strings.Contains(err.Error(), "fake crash") {
+		t.Errorf("error does not contain the server logs: %v", err)
+	}
+}
+
+func TestServer_Stop_Signal(t *testing.T) {
+	t.Setenv(EnvFakeJava, "deaf")
+	port := FreePort(t)
+	serverID := fmt.Sprintf("servertest%d", port)
+	defer func() {
+		// The key file is left as the fake server is interrupted.
+		_ = os.Remove(filepath.Join(
+			os.TempDir(), "corenlp.shutdown."+serverID)) // ignore error
+	}()
+	s, err := server.Start(&server.Options{
+		JavaPath:     os.Args[0],
+		Port:         port,
+		ServerID:     serverID,
+		StartTimeout: 10 * time.Second,
+		StopTimeout:  time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Stop(); err != nil {
+		t.Fatal("stop -", err)
+	}
+	select {
+	case <-s.Done():
+	default:
+		t.Error("server has not exited after Stop")
+	}
+}
+
+func TestStart_PortInUse(t *testing.T) {
+	ln, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = ln.Close() // ignore error
+	}()
+	t.Setenv(EnvFakeJava, "ok")
+	_, err = server.Start(&server.Options{
+		JavaPath: os.Args[0],
+		Port:     uint16(ln.Addr().(*net.TCPAddr).Port),
+	})
+	if err == nil {
+		t.Error("got nil error")
+	}
+}
+
+// FreePort returns a TCP port that is free at the time of calling.
+func FreePort(tb testing.TB) uint16 {
+	tb.Helper()
+	ln, err := net.Listen("tcp", ":0")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer func() {
+		_ = ln.Close() // ignore error
+	}()
+	return uint16(ln.Addr().(*net.TCPAddr).Port)
+}
+
+// runFakeJava runs a fake Stanford CoreNLP server with
+// the specified behavior and Java arguments,
+// and returns the exit status.
+func runFakeJava(mode string, args []string) int {
+	fmt.Println("[main] INFO fake java -", strings.Join(args, " "))
+	if mode == "crash" {
+		fmt.Fprintln(os.Stderr, "fake crash: could not load models")
+		return 1
+	}
+	var port, statusPort, serverID string
+	for i := 0; i+1 < len(args); i++ {
+		switch args[i] {
+		case "-port":
+			port = args[i+1]
+		case "-status_port":
+			statusPort = args[i+1]
+		case "-server_id":
+			serverID = args[i+1]
+		}
+	}
+	key := fmt.Sprintf("key%d", time.Now().UnixNano())
+	keyFile := filepath.Join(os.TempDir(), "corenlp.shutdown")
+	if serverID != "" {
+		keyFile += "." + serverID
+	}
+	if err := os.WriteFile(keyFile, []byte(key), 0o600); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer func() {
+		_ = os.Remove(keyFile) // ignore error
+	}()
+	exit := make(chan int, 1)
+	status := func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, strings.Trim(r.URL.Path, "/"))
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/shutdown", func(w http.ResponseWriter, r *http.Request) {
+		if mode == "deaf" || r.URL.Query().Get("key") != key {
+			http.Error(w, "Invalid shutdown key", http.StatusUnauthorized)
+			return
+		}
+		_, _ = io.WriteString(w, "Shutdown successful!")
+		fmt.Println("[main] INFO fake java - Shutdown successful!")
+		exit <- 0
+	})
+	statusMux := mux
+	if statusPort != "" {
+		statusMux = http.NewServeMux()
+	}
+	statusMux.HandleFunc("/live", status)
+	statusMux.HandleFunc("/ready", status)
+	for _, p := range []string{port, statusPort} {
+		if p == "" {
+			continue
+		}
+		h := http.Handler(mux)
+		if p == statusPort {
+			h = statusMux
+		}
+		ln, err := net.Listen("tcp", ":"+p)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		go func() {
+			_ = http.Serve(ln, h) // ignore error
+		}()
+	}
+	code := <-exit
+	time.Sleep(50 * time.Millisecond) // let the response be sent
+	return code
+}